@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// runAggregate implements "asarscan aggregate results/*.json": merge the JSON
+// reports of many hosts and summarize each application across the fleet
+func runAggregate(args []string) {
+	fs := flag.NewFlagSet("aggregate", flag.ExitOnError)
+	outputFormat := fs.String("format", "text", "Output format: text, json or csv")
+	outputPath := fs.String("o", "", "Write the summary to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asarscan aggregate [flags] report.json...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *outputFormat != "text" && *outputFormat != "json" && *outputFormat != "csv" {
+		fmt.Fprintf(os.Stderr, "Error: Unknown output format: %s\n", *outputFormat)
+		os.Exit(1)
+	}
+
+	// cmd.exe does not expand wildcards, so do it here
+	var paths []string
+	for _, arg := range fs.Args() {
+		matches, err := filepath.Glob(arg)
+		if err != nil || len(matches) == 0 {
+			matches = []string{arg}
+		}
+		paths = append(paths, matches...)
+	}
+
+	var reports []electronscan.Report
+	for _, path := range paths {
+		report, err := electronscan.ReadReport(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report)
+	}
+	fleet := electronscan.Aggregate(reports)
+
+	var out io.Writer = os.Stdout
+	if *outputPath != "" {
+		f, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch *outputFormat {
+	case "json":
+		jsonData, err := json.MarshalIndent(fleet, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(out, string(jsonData))
+	case "csv":
+		outputFleetCsv(out, fleet)
+	default:
+		outputFleetText(out, fleet)
+	}
+}
+
+// outputFleetText outputs one block per application, most widely installed first
+func outputFleetText(w io.Writer, fleet electronscan.Fleet) {
+	fmt.Fprintf(w, "%d reports from %d hosts, %d applications\n", fleet.Reports, fleet.Hosts, len(fleet.Apps))
+	if len(fleet.IncompleteHosts) > 0 {
+		fmt.Fprintf(w, "Incomplete scans (unreadable directories): %s\n", strings.Join(fleet.IncompleteHosts, ", "))
+	}
+
+	for _, app := range fleet.Apps {
+		fmt.Fprintf(w, "\n%s (%s, %s)\n", app.Name, app.ID, app.Framework)
+		fmt.Fprintf(w, "  Hosts: %d/%d\n", app.Hosts, fleet.Hosts)
+
+		fmt.Fprintln(w, "  Versions:")
+		for _, version := range app.Versions {
+			fmt.Fprintf(w, "    %s (Electron %s): %d hosts\n", valueOrNone(version.AppVersion), valueOrNone(version.ElectronVersion), version.Hosts)
+		}
+
+		if len(app.FuseConfigs) > 0 {
+			fmt.Fprintln(w, "  Fuse configurations:")
+			for _, config := range app.FuseConfigs {
+				fmt.Fprintf(w, "    %s: %d hosts\n", valueOrNone(config.AppVersion), config.Hosts)
+				fmt.Fprintf(w, "      %s\n", strings.Join(fuseList(config.Fuses), ", "))
+			}
+		}
+
+		if len(app.TamperedHosts) > 0 {
+			fmt.Fprintln(w, "  ! Tampered:")
+			for _, install := range app.TamperedHosts {
+				fmt.Fprintf(w, "    %s: %s\n", install.Host, install.Path)
+			}
+		}
+		if len(app.WritableHosts) > 0 {
+			fmt.Fprintln(w, "  ! Writable:")
+			for _, install := range app.WritableHosts {
+				fmt.Fprintf(w, "    %s: %s (%s)\n", install.Host, install.Path, strings.Join(install.Rules, ", "))
+			}
+		}
+	}
+}
+
+// outputFleetCsv outputs one row per application. Lists are joined with "; "
+// and hosts are written as host:path.
+func outputFleetCsv(w io.Writer, fleet electronscan.Fleet) {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "name", "framework", "hosts", "versions", "fuse_configurations", "tampered_hosts", "writable_hosts"})
+	for _, app := range fleet.Apps {
+		var versions []string
+		for _, version := range app.Versions {
+			versions = append(versions, fmt.Sprintf("%s/electron %s=%d", valueOrNone(version.AppVersion), valueOrNone(version.ElectronVersion), version.Hosts))
+		}
+		var configs []string
+		for _, config := range app.FuseConfigs {
+			configs = append(configs, fmt.Sprintf("%s [%s]=%d", valueOrNone(config.AppVersion), strings.Join(fuseList(config.Fuses), " "), config.Hosts))
+		}
+		var tampered, writable []string
+		for _, install := range app.TamperedHosts {
+			tampered = append(tampered, install.Host+":"+install.Path)
+		}
+		for _, install := range app.WritableHosts {
+			writable = append(writable, install.Host+":"+install.Path)
+		}
+
+		writer.Write([]string{
+			app.ID,
+			app.Name,
+			app.Framework,
+			strconv.Itoa(app.Hosts),
+			strings.Join(versions, "; "),
+			strings.Join(configs, "; "),
+			strings.Join(tampered, "; "),
+			strings.Join(writable, "; "),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+	}
+}
+
+// fuseList formats fuses as Name=state in the order of FuseNames
+func fuseList(fuses map[string]string) []string {
+	var list []string
+	for _, name := range electronscan.FuseNames {
+		if state, ok := fuses[name]; ok {
+			list = append(list, name+"="+state)
+		}
+	}
+	return list
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// runSnapshot implements "asarscan snapshot": record a baseline of every application
+func runSnapshot(args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "Enable debug logging on stderr")
+	configPath := fs.String("config", electronscan.DefaultConfigPath(), "Read search roots and exclusions from this JSON or YAML file")
+	outputPath := fs.String("o", "", "Write the baseline to this file instead of stdout")
+	fs.Parse(args)
+
+	config := loadConfig(*configPath)
+	logger := newLogger(*verbose)
+	checkSupportedOS("", false)
+
+	scanner := newBaselineScanner(config, logger)
+	results := scanApplications(scanner, logger, scanOptions{})
+	snapshot := scanner.NewSnapshot(results, version)
+
+	jsonData, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *outputPath == "" {
+		fmt.Println(string(jsonData))
+		return
+	}
+	if err := os.WriteFile(*outputPath, append(jsonData, '\n'), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing baseline: %v\n", err)
+		os.Exit(1)
+	}
+	logger.Info("Baseline written", "path", *outputPath, "apps", len(snapshot.Apps))
+}
+
+// runDiff implements "asarscan diff baseline.json": rescan and report what changed.
+// It exits with status 2 when anything changed so scripts can tell changes from errors.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "Enable debug logging on stderr")
+	configPath := fs.String("config", electronscan.DefaultConfigPath(), "Read search roots and exclusions from this JSON or YAML file")
+	outputFormat := fs.String("format", "text", "Output format: text or json")
+	outputPath := fs.String("o", "", "Write the report to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asarscan diff [flags] baseline.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// Allow flags after the baseline path as well
+	baselinePath := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Unknown output format: %s\n", *outputFormat)
+		os.Exit(1)
+	}
+
+	baseline, err := electronscan.ReadSnapshot(baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	config := loadConfig(*configPath)
+	logger := newLogger(*verbose)
+	checkSupportedOS("", false)
+
+	scanner := newBaselineScanner(config, logger)
+	results := scanApplications(scanner, logger, scanOptions{})
+	current := scanner.NewSnapshot(results, version)
+	changes := electronscan.DiffSnapshots(baseline, current)
+
+	var out io.Writer = os.Stdout
+	if *outputPath != "" {
+		f, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		out = f
+	}
+
+	if *outputFormat == "json" {
+		if changes == nil {
+			changes = []electronscan.SnapshotChange{}
+		}
+		jsonData, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(out, string(jsonData))
+	} else {
+		outputDiffText(out, baseline, changes)
+	}
+
+	if f, ok := out.(*os.File); ok && f != os.Stdout {
+		f.Close()
+	}
+	if len(changes) > 0 {
+		os.Exit(2)
+	}
+}
+
+// outputDiffText outputs the changes grouped by application
+func outputDiffText(w io.Writer, baseline *electronscan.Snapshot, changes []electronscan.SnapshotChange) {
+	fmt.Fprintf(w, "Baseline from %s (asarscan v%s)\n", baseline.CreatedAt.Local().Format("2006-01-02 15:04:05"), baseline.ScannerVersion)
+
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes detected")
+		return
+	}
+
+	lastApp := ""
+	for _, change := range changes {
+		if change.App != lastApp {
+			fmt.Fprintf(w, "\n%s\n", change.App)
+			lastApp = change.App
+		}
+
+		switch change.Kind {
+		case electronscan.ChangeAppAdded, electronscan.ChangeAppRemoved:
+			fmt.Fprintf(w, "  %s\n", change.Kind)
+		case electronscan.ChangeVersion:
+			fmt.Fprintf(w, "  %s: %s -> %s\n", change.Kind, valueOrNone(change.Old), valueOrNone(change.New))
+		case electronscan.ChangeFuseFlipped:
+			fmt.Fprintf(w, "  ! %s: %s %s -> %s\n", change.Kind, change.Path, valueOrNone(change.Old), valueOrNone(change.New))
+		case electronscan.ChangeAppFolderAdded, electronscan.ChangeAppFolderRemoved:
+			fmt.Fprintf(w, "  ! %s: resources/app\n", change.Kind)
+		default:
+			fmt.Fprintf(w, "  ! %s: %s\n", change.Kind, change.Path)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d changes detected\n", len(changes))
+}
+
+// valueOrNone prints missing values in diff output
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// newBaselineScanner creates the scanner for snapshot and diff. Every .node file
+// has to be hashed, so the inventory is never limited.
+func newBaselineScanner(config *electronscan.Config, logger *slog.Logger) *electronscan.Scanner {
+	return electronscan.New(electronscan.Options{
+		Roots:   config.SearchRoots,
+		Logger:  logger,
+		Workers: config.Workers,
+		Exclude: config.Exclude,
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// loadConfig reads the config file, or returns an empty config if path is "".
+// It exits on an invalid file so a team never scans with half its settings.
+func loadConfig(path string) *electronscan.Config {
+	if path == "" {
+		return &electronscan.Config{}
+	}
+	config, err := electronscan.LoadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return config
+}
+
+// flagsSet returns the names of the flags given on the command line,
+// which take precedence over the config file
+func flagsSet(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// warnExpiredSuppressions logs suppressions past their expiry date, whose findings are reported again
+func warnExpiredSuppressions(config *electronscan.Config, logger *slog.Logger) {
+	for _, suppression := range config.ExpiredSuppressions(time.Now()) {
+		logger.Warn("Suppression has expired", "rule", suppression.Rule, "app", suppression.App,
+			"path", suppression.Path, "expires", suppression.Expires, "justification", suppression.Justification)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// runFixture implements "asarscan fixture dir": write a fake Electron application
+// for testing scanners and detection rules
+func runFixture(args []string) {
+	fs := flag.NewFlagSet("fixture", flag.ExitOnError)
+	goos := fs.String("os", "darwin", "Application layout: darwin, windows or linux")
+	name := fs.String("name", fixture.DefaultName, "Application name")
+	bundleID := fs.String("bundle-id", "", "CFBundleIdentifier (default com.example.<name>)")
+	appVersion := fs.String("version", fixture.DefaultVersion, "Application version")
+	electronVersion := fs.String("electron", fixture.DefaultElectronVersion, "Electron version compiled into the executable")
+	binaryPlist := fs.Bool("binary-plist", false, "Write Info.plist files in binary format")
+	fuses := fs.String("fuses", "", "Comma-separated fuse overrides, e.g. RunAsNode=disabled,OnlyLoadAppFromAsar=enabled")
+	integrity := fs.Bool("integrity", false, "Embed the app.asar integrity hash")
+	tamper := fs.Bool("tamper", false, "Modify app.asar after the integrity hash was taken")
+	nativeModules := fs.String("native-modules", "", "Comma-separated npm packages to ship with a .node addon")
+	writableModules := fs.Bool("writable-modules", false, "Make the native modules world-writable")
+	moduleImports := fs.String("module-imports", "", "Comma-separated libraries the native modules import but that are not shipped, e.g. @rpath/libfoo.dylib or foo.dll")
+	signed := fs.Bool("signed", false, "Add a placeholder code signature to the executables")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asarscan fixture [flags] dir")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	app := fixture.App{
+		Name:            *name,
+		GOOS:            *goos,
+		BundleID:        *bundleID,
+		Version:         *appVersion,
+		ElectronVersion: *electronVersion,
+		BinaryPlist:     *binaryPlist,
+		Integrity:       *integrity,
+		TamperedAsar:    *tamper,
+		WritableModules: *writableModules,
+		Signed:          *signed,
+	}
+	if *fuses != "" {
+		app.Fuses = make(map[string]string)
+		for _, fuse := range strings.Split(*fuses, ",") {
+			name, state, ok := strings.Cut(strings.TrimSpace(fuse), "=")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: invalid fuse %q, expected Name=state\n", fuse)
+				os.Exit(1)
+			}
+			app.Fuses[name] = state
+		}
+	}
+	if *nativeModules != "" {
+		for _, module := range strings.Split(*nativeModules, ",") {
+			app.NativeModules = append(app.NativeModules, strings.TrimSpace(module))
+		}
+	}
+	if *moduleImports != "" {
+		for _, library := range strings.Split(*moduleImports, ",") {
+			app.ModuleImports = append(app.ModuleImports, strings.TrimSpace(library))
+		}
+	}
+
+	fsys := fstest.MapFS{}
+	appName, err := app.Build(fsys, ".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	dir := fs.Arg(0)
+	if err := fixture.WriteDir(fsys, dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing fixture: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(filepath.Join(dir, filepath.FromSlash(appName)))
+}
@@ -4,136 +4,531 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/adversis/electron-integrity/cmd/asarscan/internal"
+	"github.com/adversis/electron-integrity/electronscan"
 )
 
 // Version is set during build via ldflags
 var version = "dev"
 
-// AppResult contains the result of checking an application
-type AppResult struct {
-	Path             string   `json:"path"`
-	IsElectron       bool     `json:"is_electron"`
-	Version          string   `json:"electron_version,omitempty"`
-	HasAsarFile      bool     `json:"has_asar_file"`
-	AsarIntegrity    bool     `json:"asar_integrity_enabled"`
-	OnlyLoadFromAsar bool     `json:"only_load_from_asar"`
-	NodeFiles        []string `json:"node_files,omitempty"`
-	IntegrityError   string   `json:"integrity_error,omitempty"`
-}
-
 func main() {
+	// Subcommands share the scan but produce baselines or alerts instead of reports
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "snapshot":
+			runSnapshot(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		case "fixture":
+			runFixture(os.Args[2:])
+			return
+		case "schema":
+			runSchema(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		case "aggregate":
+			runAggregate(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		}
+	}
+
 	// Parse command-line flags
-	verbose := flag.Bool("verbose", false, "Enable verbose output")
-	outputJson := flag.Bool("json", false, "Output results in JSON format")
+	verbose := flag.Bool("verbose", false, "Enable debug logging on stderr")
+	configPath := flag.String("config", electronscan.DefaultConfigPath(), "Read search roots, exclusions, suppressions and defaults from this JSON or YAML file; flags take precedence")
+	outputJson := flag.Bool("json", false, "Output results in JSON format (same as -format json)")
+	outputFormat := flag.String("format", "text", "Output format: text, json, ndjson, sarif, csv, markdown, html or prometheus")
+	outputPath := flag.String("o", "", "Write results to this file instead of stdout")
 	listNodeFiles := flag.Bool("node-files", true, "List .node files in Electron applications")
-	maxNodeFiles := flag.Int("max-node-files", 5, "Maximum number of .node files to list per application (0 for unlimited)")
+	maxNodeFiles := flag.Int("max-node-files", 0, "Maximum number of .node files to list per application (0 for unlimited)")
+	policyPath := flag.String("policy", "", "Check every app against this policy file (JSON or YAML) and exit with status 2 on violations")
+	minSeverity := flag.String("min-severity", "", "Only report apps whose risk score is at least this severe: low, medium, high or critical")
+	syslogAddr := flag.String("syslog", "", "Also send one event per finding to syslog: \"local\", \"udp://host:514\", \"tcp://host:514\" or \"unix:///path\"")
+	syslogFormat := flag.String("syslog-format", "cef", "Syslog message format: cef, leef or json")
+	workers := flag.Int("workers", 0, "Number of applications to check in parallel (0 for one per CPU)")
+	targetOS := flag.String("target-os", "", "Application layout to expect, darwin, windows or linux (default: this OS); needed to check named paths or an image from another OS")
+	imagePath := flag.String("image", "", "Scan the mounted disk image or copied filesystem rooted at this folder instead of this computer")
 	showVersion := flag.Bool("version", false, "Show version information")
 	flag.Parse()
 
+	// Settings from the config file apply unless the flag was given
+	config := loadConfig(*configPath)
+	set := flagsSet(flag.CommandLine)
+	if config.Format != "" && !set["format"] && !set["json"] {
+		*outputFormat = config.Format
+	}
+	if config.MaxNodeFiles > 0 && !set["max-node-files"] {
+		*maxNodeFiles = config.MaxNodeFiles
+	}
+	if config.MinSeverity != "" && !set["min-severity"] {
+		*minSeverity = config.MinSeverity
+	}
+	if config.Policy != "" && !set["policy"] {
+		*policyPath = config.Policy
+	}
+	if config.Workers > 0 && !set["workers"] {
+		*workers = config.Workers
+	}
+
+	if *outputJson {
+		*outputFormat = "json"
+	}
+	switch *outputFormat {
+	case "text", "json", "ndjson", "sarif", "csv", "markdown", "html", "prometheus":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown output format: %s\n", *outputFormat)
+		os.Exit(1)
+	}
+	if *minSeverity != "" && !electronscan.ValidSeverity(*minSeverity) {
+		fmt.Fprintf(os.Stderr, "Error: Unknown severity: %s\n", *minSeverity)
+		os.Exit(1)
+	}
+
+	var policy *electronscan.Policy
+	if *policyPath != "" {
+		var err error
+		if policy, err = electronscan.LoadPolicy(*policyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Show version and exit if requested
 	if *showVersion {
 		fmt.Printf("Electron ASAR Integrity Scanner v%s\n", version)
 		os.Exit(0)
 	}
 
-	fmt.Println("Electron ASAR Integrity Scanner v" + version)
-	fmt.Println("-------------------------------")
+	logger := newLogger(*verbose)
+	goos := checkSupportedOS(*targetOS, flag.NArg() > 0 || *imagePath != "")
+	warnExpiredSuppressions(config, logger)
+
+	// Search roots in the config describe this computer, not the image
+	var fsys fs.FS
+	roots := config.SearchRoots
+	if *imagePath != "" {
+		if info, err := os.Stat(*imagePath); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: -image must be a folder: %s\n", *imagePath)
+			os.Exit(1)
+		}
+		fsys = os.DirFS(*imagePath)
+		roots = nil
+	}
 
-	// Check if we're running on a supported OS
-	if runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
-		fmt.Fprintf(os.Stderr, "Error: Unsupported operating system: %s. This tool only works on macOS and Windows.\n", runtime.GOOS)
-		os.Exit(1)
+	// Connect before scanning so a wrong target fails fast
+	var siem *syslogSink
+	if *syslogAddr != "" {
+		var err error
+		if siem, err = newSyslogSink(*syslogAddr, *syslogFormat, logger); err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to syslog: %v\n", err)
+			os.Exit(1)
+		}
+		defer siem.Close()
 	}
 
-	fmt.Printf("Scanning %s system for Electron applications...\n", runtime.GOOS)
+	// Open the output early so streaming formats can write as they go.
+	// Prometheus textfiles are replaced in one go instead.
+	var out io.Writer = os.Stdout
+	if *outputPath != "" && *outputFormat != "prometheus" {
+		f, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
 
-	// Scan for Electron applications
-	apps, err := internal.ScanForElectronApps(*verbose)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error scanning for applications: %v\n", err)
-		os.Exit(1)
+	// os.Exit skips deferred calls, so exit closes the syslog connection and
+	// the output file first
+	exit := func(code int) {
+		if siem != nil {
+			if err := siem.Close(); err != nil {
+				logger.Error("Error closing syslog connection", "error", err)
+			}
+		}
+		if f, ok := out.(*os.File); ok && f != os.Stdout {
+			f.Close()
+		}
+		os.Exit(code)
 	}
 
-	fmt.Printf("Found %d potential Electron applications\n", len(apps))
+	var stream *ndjsonWriter
+	if *outputFormat == "ndjson" {
+		stream = newNdjsonWriter(out)
+		stream.minSeverity = *minSeverity
+	}
 
-	// Check ASAR integrity for each application
-	var results []internal.AppResult
+	start := time.Now()
+	scanner := electronscan.New(electronscan.Options{
+		Roots:         roots,
+		GOOS:          goos,
+		FS:            fsys,
+		Logger:        logger,
+		Workers:       *workers,
+		SkipNodeFiles: !*listNodeFiles,
+		MaxNodeFiles:  *maxNodeFiles,
+		Exclude:       config.Exclude,
+		Suppressions:  config.Suppressions,
+		Policy:        policy,
+	})
+	results := scanApplications(scanner, logger, scanOptions{
+		paths:  flag.Args(),
+		image:  fsys != nil,
+		policy: policy != nil,
+		stream: stream,
+	})
+
+	// Count violations before filtering so hidden apps still fail the policy
+	violations := 0
+	for _, result := range results {
+		violations += len(result.PolicyViolations)
+	}
+
+	if *minSeverity != "" {
+		results = filterBySeverity(results, *minSeverity)
+	}
 
-	for _, app := range apps {
-		if *verbose {
-			fmt.Printf("Checking ASAR integrity for: %s\n", app)
+	report := scanner.NewReport(results, version, start, time.Now())
+	for _, dir := range report.Metadata.UnreadableDirs {
+		logger.Warn("Could not read directory", "dir", dir)
+	}
+
+	// Output results
+	switch *outputFormat {
+	case "ndjson":
+		stream.Done(len(results), &report.Metadata)
+	case "json":
+		outputResultsJson(out, report)
+	case "sarif":
+		outputResultsSarif(out, report)
+	case "csv":
+		outputResultsCsv(out, results)
+	case "markdown":
+		outputResultsMarkdown(out, results)
+	case "html":
+		if err := outputResultsHtml(out, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	case "prometheus":
+		if err := outputResultsPrometheus(*outputPath, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
 		}
+	default:
+		outputResultsText(out, results, *listNodeFiles)
+	}
 
-		result := internal.CheckAsarIntegrityForApp(app, *verbose)
+	if *outputPath != "" {
+		logger.Info("Results written", "path", *outputPath)
+	}
 
-		// Find .node files if requested
-		if *listNodeFiles && result.IsElectron {
-			if *verbose {
-				fmt.Printf("Searching for .node files in: %s\n", app)
-			}
-			result.NodeFiles = internal.FindNodeFiles(app, *maxNodeFiles, *verbose)
+	if siem != nil {
+		sent, err := siem.Findings(results)
+		if err != nil {
+			logger.Error("Error sending findings to syslog", "error", err, "sent", sent)
+		} else {
+			logger.Info("Findings sent to syslog", "count", sent, "format", *syslogFormat)
 		}
+	}
 
-		results = append(results, result)
+	// A failed policy fails the build
+	if policy != nil {
+		if violations > 0 {
+			logger.Error("Policy check failed", "violations", violations)
+			exit(2)
+		}
+		logger.Info("Policy check passed")
 	}
+}
 
-	// Output results
-	if *outputJson {
-		outputResultsJson(results)
+// newLogger creates the stderr logger; stdout carries only the selected output format
+func newLogger(verbose bool) *slog.Logger {
+	logLevel := slog.LevelInfo
+	if verbose {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+	logger.Info("Electron ASAR Integrity Scanner", "version", version)
+	return logger
+}
+
+// checkSupportedOS returns the OS whose applications are scanned and exits if it is
+// not supported. Installed applications are discovered on macOS, Windows and Linux,
+// and named paths and images can be checked from any OS given the target layout.
+func checkSupportedOS(targetOS string, offHost bool) string {
+	switch {
+	case targetOS == "":
+		targetOS = runtime.GOOS
+	case !offHost && targetOS != runtime.GOOS:
+		fmt.Fprintf(os.Stderr, "Error: -target-os %s needs application paths or -image; installed applications are only discovered for this OS.\n", targetOS)
+		os.Exit(1)
+	}
+	if targetOS == "darwin" || targetOS == "windows" || targetOS == "linux" {
+		return targetOS
+	}
+	if offHost {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported target OS: %s. Pass -target-os darwin, windows or linux.\n", targetOS)
 	} else {
-		outputResultsText(results, *listNodeFiles)
+		fmt.Fprintf(os.Stderr, "Error: Unsupported operating system: %s. This tool only works on macOS, Windows and Linux; elsewhere, pass application paths or -image with -target-os.\n", runtime.GOOS)
 	}
+	os.Exit(1)
+	return ""
+}
+
+// scanOptions controls which applications are checked and how progress is reported
+type scanOptions struct {
+	// paths are checked instead of discovering installed applications
+	paths []string
+	// image is set when the scanner reads an image, so paths are inside it
+	image bool
+	// policy is set when the scanner evaluates a policy, so named paths must be recognized
+	policy bool
+	// stream may be nil; when set, progress is written as NDJSON events
+	stream *ndjsonWriter
+}
+
+// scanApplications discovers applications, or takes the given paths, and checks each one
+func scanApplications(scanner *electronscan.Scanner, logger *slog.Logger, opts scanOptions) []electronscan.AppResult {
+	stream := opts.stream
+
+	apps := opts.paths
+	if len(apps) == 0 {
+		logger.Info("Scanning for Electron applications", "os", scanner.TargetOS())
+
+		// Scan for Electron applications
+		var err error
+		apps, err = scanner.Discover()
+		if err != nil {
+			if stream != nil {
+				stream.Error("", fmt.Sprintf("error scanning for applications: %v", err))
+				stream.Done(0, nil)
+			}
+			fmt.Fprintf(os.Stderr, "Error scanning for applications: %v\n", err)
+			os.Exit(1)
+		}
+
+		logger.Info("Found potential Electron applications", "count", len(apps))
+	} else {
+		for i, app := range apps {
+			if opts.image {
+				apps[i] = filepath.Join(string(filepath.Separator), app)
+			} else if abs, err := filepath.Abs(app); err == nil {
+				apps[i] = filepath.Clean(abs)
+			}
+		}
+	}
+
+	if stream != nil {
+		stream.Start(len(apps))
+	}
+
+	// Results come back one at a time, in completion order
+	return scanner.CheckApps(apps, func(result *electronscan.AppResult, checked int) {
+		if opts.policy && len(opts.paths) > 0 && result.Framework == "" {
+			// An app named explicitly must not pass just because it was not recognized
+			result.PolicyViolations = []electronscan.PolicyViolation{{
+				Requirement: "electron",
+				Message:     "Not a recognized Electron or Chromium-based application",
+				Path:        result.Path,
+			}}
+		}
+		for _, violation := range result.PolicyViolations {
+			logger.Error("Policy violation", "app", result.Path, "requirement", violation.Requirement, "message", violation.Message)
+		}
+
+		if stream != nil {
+			stream.App(*result, checked, len(apps))
+		}
+	})
+}
+
+// filterBySeverity keeps the apps whose risk score reaches the minimum severity
+func filterBySeverity(results []electronscan.AppResult, minSeverity string) []electronscan.AppResult {
+	var filtered []electronscan.AppResult
+	for _, result := range results {
+		if result.Risk != nil && electronscan.SeverityAtLeast(result.Risk.Severity, minSeverity) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
 }
 
 // outputResultsJson outputs the results in JSON format
-func outputResultsJson(results []internal.AppResult) {
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+func outputResultsJson(w io.Writer, report electronscan.Report) {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
 		return
 	}
 
-	fmt.Println(string(jsonData))
+	fmt.Fprintln(w, string(jsonData))
 }
 
 // outputResultsText outputs the results in human-readable text format
-func outputResultsText(results []internal.AppResult, showNodeFiles bool) {
-	fmt.Println("\nResults:")
-	fmt.Println("========")
+func outputResultsText(w io.Writer, results []electronscan.AppResult, showNodeFiles bool) {
+	// Riskiest apps first
+	results = slices.Clone(results)
+	sort.SliceStable(results, func(i, j int) bool {
+		return riskScore(results[i]) > riskScore(results[j])
+	})
+
+	fmt.Fprintln(w, "\nResults:")
+	fmt.Fprintln(w, "========")
 
 	// First output detailed results
 	index := 1
 	for _, result := range results {
-		// Skip non-Electron apps
-		if !result.IsElectron {
+		// Skip apps that do not embed Chromium
+		if result.Framework == "" {
 			continue
 		}
 
-		fmt.Printf("\n[%d] %s\n", index, result.Path)
-		fmt.Printf("  Is Electron App: %t\n", result.IsElectron)
-		fmt.Printf("  Electron Version: %s\n", result.Version)
-		fmt.Printf("  Has ASAR File: %t\n", result.HasAsarFile)
+		fmt.Fprintf(w, "\n[%d] %s\n", index, result.Path)
+		if result.Risk != nil {
+			fmt.Fprintf(w, "  Risk Score: %d/100 (%s)\n", result.Risk.Score, result.Risk.Severity)
+			for _, factor := range result.Risk.Factors {
+				fmt.Fprintf(w, "    +%d %s: %s", factor.Points, factor.RuleID, factor.Example)
+				if factor.Findings > 1 {
+					fmt.Fprintf(w, " (and %d more)", factor.Findings-1)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		if len(result.PolicyViolations) > 0 {
+			fmt.Fprintf(w, "  Policy Violations:\n")
+			for _, violation := range result.PolicyViolations {
+				fmt.Fprintf(w, "    ! %s: %s\n", violation.Requirement, violation.Message)
+			}
+		}
+		fmt.Fprintf(w, "  Is Electron App: %t\n", result.IsElectron)
+		if !result.IsElectron {
+			fmt.Fprintf(w, "  Framework: %s (%s)\n", result.Framework, result.FrameworkEvidence)
+			for _, finding := range result.FrameworkFindings {
+				fmt.Fprintf(w, "    ! %s\n", finding)
+			}
+		} else {
+			if result.Framework != electronscan.FrameworkElectron {
+				fmt.Fprintf(w, "  Framework: %s\n", result.Framework)
+			}
+			fmt.Fprintf(w, "  Electron Version: %s\n", result.Version)
+		}
+		fmt.Fprintf(w, "  Has ASAR File: %t\n", result.HasAsarFile)
 
 		if result.HasAsarFile {
-			fmt.Printf("  ASAR Integrity Enabled: %t\n", result.AsarIntegrity)
-			fmt.Printf("  OnlyLoadFromAsar Enabled: %t\n", result.OnlyLoadFromAsar)
+			fmt.Fprintf(w, "  ASAR Integrity Enabled: %t\n", result.AsarIntegrity)
+			fmt.Fprintf(w, "  OnlyLoadFromAsar Enabled: %t\n", result.OnlyLoadFromAsar)
 
 			if result.IntegrityError != "" {
-				fmt.Printf("  Error: %s\n", result.IntegrityError)
+				fmt.Fprintf(w, "  Error: %s\n", result.IntegrityError)
+			}
+		}
+
+		// Show fuse states in wire order
+		if len(result.Fuses) > 0 {
+			fmt.Fprintf(w, "  Fuses:\n")
+			for _, name := range electronscan.FuseNames {
+				if state, ok := result.Fuses[name]; ok {
+					fmt.Fprintf(w, "    %s: %s\n", name, state)
+				}
+			}
+		}
+
+		// Show the entry point and preload scripts
+		if result.EntryPoint != nil {
+			fmt.Fprintf(w, "  Entry Point (%s): %s\n", result.EntryPoint.Source, formatScript(result.EntryPoint.Main))
+			for _, preload := range result.EntryPoint.Preloads {
+				fmt.Fprintf(w, "  Preload: %s\n", formatScript(preload))
+			}
+			for _, pref := range result.EntryPoint.WebPreferences {
+				fmt.Fprintf(w, "  webPreferences: %s: %s (%s)\n", pref.Setting, pref.Value, pref.Script)
+			}
+		}
+
+		// Show the update mechanism and any weaknesses in it
+		if result.Updater != nil {
+			fmt.Fprintf(w, "  Updater: %s\n", strings.Join(result.Updater.Mechanisms, ", "))
+			if result.Updater.FeedURL != "" {
+				fmt.Fprintf(w, "    Feed: %s", result.Updater.FeedURL)
+				if result.Updater.Provider != "" {
+					fmt.Fprintf(w, " (%s)", result.Updater.Provider)
+				}
+				fmt.Fprintln(w)
+			}
+			if len(result.Updater.PublisherNames) > 0 {
+				fmt.Fprintf(w, "    Publisher: %s\n", strings.Join(result.Updater.PublisherNames, ", "))
+			}
+			if result.Updater.SignatureVerification != "" {
+				fmt.Fprintf(w, "    Signature Verification: %s\n", result.Updater.SignatureVerification)
+			}
+			if result.Updater.CachePath != "" {
+				fmt.Fprintf(w, "    Cache: %s (writable: %t)\n", result.Updater.CachePath, result.Updater.CacheWritable)
+			}
+			for _, issue := range result.Updater.Issues {
+				fmt.Fprintf(w, "    ! %s\n", issue)
+			}
+		}
+
+		// Show what starts the application without the user opening it
+		if result.AutoStart {
+			fmt.Fprintf(w, "  Auto Start:\n")
+			for _, entry := range result.AutoStartEntries {
+				fmt.Fprintf(w, "    %s\n", autoStartSummary(entry))
 			}
 		}
 
 		// Show .node files if available
 		if showNodeFiles && len(result.NodeFiles) > 0 {
-			fmt.Printf("  .node Files (%d found):\n", len(result.NodeFiles))
+			fmt.Fprintf(w, "  .node Files (%d found):\n", len(result.NodeFiles))
 			for i, nodeFile := range result.NodeFiles {
 				// Print the full path as requested by the user
-				fmt.Printf("    %d. %s\n", i+1, nodeFile)
+				fmt.Fprintf(w, "    %d. %s\n", i+1, nodeFile.Path)
+
+				pkg := "unknown package"
+				if nodeFile.Package != "" {
+					pkg = nodeFile.Package
+					if nodeFile.PackageVersion != "" {
+						pkg += "@" + nodeFile.PackageVersion
+					}
+				}
+				abi := nodeFile.ABI
+				if abi == "" {
+					abi = "unknown ABI"
+				} else if nodeFile.ABIVersion != "" {
+					abi += " v" + nodeFile.ABIVersion
+				}
+				fmt.Fprintf(w, "       %s, %s, %s %s, unpacked: %t, writable: %t\n", pkg, abi,
+					nodeFile.Format, strings.Join(nodeFile.Architectures, "/"), nodeFile.Unpacked, nodeFile.Writable)
+				fmt.Fprintf(w, "       sha256: %s (%d bytes)\n", nodeFile.SHA256, nodeFile.Size)
+
+				// Missing or writable libraries are a second way to hijack the addon
+				for _, dep := range nodeFile.Dependencies {
+					if dep.Missing {
+						fmt.Fprintf(w, "       ! missing dependency: %s (%s)\n", dep.Name, dep.Resolved)
+					} else if dep.Writable {
+						fmt.Fprintf(w, "       ! writable dependency: %s (%s)\n", dep.Name, dep.Resolved)
+					}
+				}
 			}
 		}
 
@@ -142,11 +537,19 @@ func outputResultsText(results []internal.AppResult, showNodeFiles bool) {
 
 	// Summary statistics
 	electronCount := 0
+	otherFrameworkCount := 0
 	asarCount := 0
 	integrityCount := 0
 	onlyLoadCount := 0
+	policyFailCount := 0
 
 	for _, result := range results {
+		if len(result.PolicyViolations) > 0 {
+			policyFailCount++
+		}
+		if !result.IsElectron && result.Framework != "" {
+			otherFrameworkCount++
+		}
 		if result.IsElectron {
 			electronCount++
 			if result.HasAsarFile {
@@ -161,18 +564,22 @@ func outputResultsText(results []internal.AppResult, showNodeFiles bool) {
 		}
 	}
 
-	fmt.Printf("\nSummary:\n")
-	fmt.Printf("  Total apps scanned: %d\n", len(results))
-	fmt.Printf("  Electron apps: %d\n", electronCount)
-	fmt.Printf("  Apps with ASAR files: %d\n", asarCount)
-	fmt.Printf("  Apps with ASAR integrity enabled: %d\n", integrityCount)
-	fmt.Printf("  Apps with OnlyLoadAppFromAsar enabled: %d\n", onlyLoadCount)
+	fmt.Fprintf(w, "\nSummary:\n")
+	fmt.Fprintf(w, "  Total apps scanned: %d\n", len(results))
+	fmt.Fprintf(w, "  Electron apps: %d\n", electronCount)
+	fmt.Fprintf(w, "  Other Chromium-based apps (NW.js, CEF, Tauri, WebView2): %d\n", otherFrameworkCount)
+	fmt.Fprintf(w, "  Apps with ASAR files: %d\n", asarCount)
+	fmt.Fprintf(w, "  Apps with ASAR integrity enabled: %d\n", integrityCount)
+	fmt.Fprintf(w, "  Apps with OnlyLoadAppFromAsar enabled: %d\n", onlyLoadCount)
+	if policyFailCount > 0 {
+		fmt.Fprintf(w, "  Apps violating policy: %d\n", policyFailCount)
+	}
 
 	// Add a table summary of Electron apps
-	fmt.Printf("\nSummary Table:\n")
-	fmt.Printf("===================================================================================\n")
-	fmt.Printf("%-30s | %-10s | %-10s | %-10s | %-15s\n", "Application", "Version", "ASAR File", "Integrity", "OnlyLoadAppFromAsar")
-	fmt.Printf("===================================================================================\n")
+	fmt.Fprintf(w, "\nSummary Table:\n")
+	fmt.Fprintf(w, "====================================================================================================\n")
+	fmt.Fprintf(w, "%-30s | %-10s | %-10s | %-10s | %-19s | %-12s\n", "Application", "Version", "ASAR File", "Integrity", "OnlyLoadAppFromAsar", "Risk")
+	fmt.Fprintf(w, "====================================================================================================\n")
 
 	// Only include electron apps in the table
 	for _, result := range results {
@@ -215,8 +622,51 @@ func outputResultsText(results []internal.AppResult, showNodeFiles bool) {
 				appName = appName[:25] + "..."
 			}
 
-			fmt.Printf("%-30s | %-10s | %-10s | %-10s | %-15s\n", appName, version, hasAsar, integrity, onlyLoad)
+			risk := "N/A"
+			if result.Risk != nil {
+				risk = fmt.Sprintf("%d (%s)", result.Risk.Score, result.Risk.Severity)
+			}
+
+			fmt.Fprintf(w, "%-30s | %-10s | %-10s | %-10s | %-19s | %-12s\n", appName, version, hasAsar, integrity, onlyLoad, risk)
 		}
 	}
-	fmt.Printf("===================================================================================\n")
+	fmt.Fprintf(w, "====================================================================================================\n")
+}
+
+// riskScore returns the risk score of an app, or -1 if it was not scored
+func riskScore(result electronscan.AppResult) int {
+	if result.Risk == nil {
+		return -1
+	}
+	return result.Risk.Score
+}
+
+// formatScript describes where a script lives and whether it can be modified
+func formatScript(script electronscan.ScriptInfo) string {
+	desc := fmt.Sprintf("%s [%s", script.Path, script.Location)
+	if script.Missing {
+		desc += ", missing"
+	}
+	if script.Writable {
+		desc += ", WRITABLE"
+	}
+	desc += "]"
+	if script.SHA256 != "" {
+		desc += " sha256:" + script.SHA256
+	}
+	return desc
+}
+
+// autoStartSummary describes an auto-start entry on one line, e.g.
+// "run-key Slack (HKCU\Software\Microsoft\Windows\CurrentVersion\Run) [user alice]"
+func autoStartSummary(entry electronscan.AutoStartEntry) string {
+	desc := entry.Mechanism
+	if entry.Name != "" {
+		desc += " " + entry.Name
+	}
+	desc += " (" + entry.Location + ")"
+	if entry.User != "" {
+		desc += " [user " + entry.User + "]"
+	}
+	return desc
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// TestScanLogsOnlyToLogger checks that scanning writes nothing to stdout,
+// which carries the results, and that what it logs is structured
+func TestScanLogsOnlyToLogger(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "darwin", Integrity: true, TamperedAsar: true}.Build(fsys, "Applications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["Applications/Notes.app/Contents/Info.plist"] = &fstest.MapFile{Data: []byte("<plist/>")}
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	scanner := electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys, Logger: logger, Policy: &electronscan.Policy{}})
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	results := scanApplications(scanner, logger, scanOptions{
+		paths:  []string{appPath, "Applications/Notes.app"},
+		image:  true,
+		policy: true,
+	})
+	os.Stdout = stdout
+	w.Close()
+	printed, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) > 0 {
+		t.Errorf("scan wrote to stdout: %q", printed)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	// Each line is one JSON record; the messages this scan must produce
+	// carry the application as an attribute rather than in the text
+	want := map[string]bool{
+		"ASAR integrity hash does not match app.asar": false,
+		"Policy violation": false,
+	}
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		msg, _ := record["msg"].(string)
+		if _, ok := want[msg]; ok {
+			if record["app"] == nil {
+				t.Errorf("%q has no app attribute: %s", msg, line)
+			}
+			want[msg] = true
+		}
+	}
+	for msg, seen := range want {
+		if !seen {
+			t.Errorf("missing log record %q", msg)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// scanDurationBuckets are the upper bounds in seconds of the scan duration histogram
+var scanDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800}
+
+// durationHistogram counts scan durations into scanDurationBuckets
+type durationHistogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// observe records one scan
+func (h *durationHistogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(scanDurationBuckets))
+	}
+	seconds := d.Seconds()
+	for i, bound := range scanDurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// outputResultsPrometheus writes the metrics of one scan in the Prometheus text
+// format. With a path the file is replaced atomically, as node_exporter's
+// textfile collector expects.
+func outputResultsPrometheus(path string, report electronscan.Report) error {
+	var histogram durationHistogram
+	histogram.observe(time.Duration(report.Metadata.DurationMs) * time.Millisecond)
+
+	if path == "" {
+		writeReportMetrics(os.Stdout, report, histogram)
+		return nil
+	}
+
+	// The collector must never read a half-written file
+	f, err := os.CreateTemp(filepath.Dir(path), ".asarscan-*.prom")
+	if err != nil {
+		return fmt.Errorf("error creating metrics file: %v", err)
+	}
+	writeReportMetrics(f, report, histogram)
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("error writing metrics file: %v", err)
+	}
+	os.Chmod(f.Name(), 0o644)
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("error writing metrics file: %v", err)
+	}
+	return nil
+}
+
+// writeReportMetrics writes the gauges describing a report and the scan duration histogram
+func writeReportMetrics(w io.Writer, report electronscan.Report, histogram durationHistogram) {
+	var apps, integrityEnabled, integrityValid, integrityMismatch, writableModules, violations int
+	fuses := make(map[string]int)
+	severities := make(map[string]int)
+	for _, result := range report.Results {
+		if result.Framework == "" {
+			continue
+		}
+		apps++
+		if result.AsarIntegrity {
+			integrityEnabled++
+		}
+		switch result.IntegrityHash {
+		case electronscan.IntegrityHashMatch:
+			integrityValid++
+		case electronscan.IntegrityHashMismatch:
+			integrityMismatch++
+		}
+		for name, state := range result.Fuses {
+			if state == electronscan.FuseEnabled {
+				fuses[name]++
+			}
+		}
+		if result.Risk != nil {
+			severities[result.Risk.Severity]++
+		}
+		for _, finding := range electronscan.Findings(result) {
+			if finding.RuleID == electronscan.RuleWritableNativeModule {
+				writableModules++
+			}
+		}
+		violations += len(result.PolicyViolations)
+	}
+
+	writeMetric(w, "asarscan_apps_total", "gauge", "Chromium-based applications found by the last scan", float64(apps))
+
+	writeMetricHeader(w, "asarscan_apps_fuse_enabled", "gauge", "Electron applications with the fuse enabled")
+	for _, name := range electronscan.FuseNames {
+		writeSample(w, "asarscan_apps_fuse_enabled", `fuse="`+name+`"`, float64(fuses[name]))
+	}
+
+	writeMetric(w, "asarscan_apps_integrity_enabled", "gauge", "Applications with ASAR integrity validation enabled", float64(integrityEnabled))
+	writeMetric(w, "asarscan_apps_integrity_valid", "gauge", "Applications whose embedded ASAR integrity hash matches app.asar", float64(integrityValid))
+	writeMetric(w, "asarscan_apps_integrity_mismatch", "gauge", "Applications whose app.asar no longer matches the embedded hash", float64(integrityMismatch))
+	writeMetric(w, "asarscan_writable_native_modules", "gauge", "Native modules the scanning user can replace", float64(writableModules))
+
+	writeMetricHeader(w, "asarscan_apps_risk", "gauge", "Applications by risk severity")
+	for _, severity := range []string{electronscan.SeverityCritical, electronscan.SeverityHigh, electronscan.SeverityMedium, electronscan.SeverityLow, electronscan.SeverityNone} {
+		writeSample(w, "asarscan_apps_risk", `severity="`+severity+`"`, float64(severities[severity]))
+	}
+
+	writeMetric(w, "asarscan_policy_violations", "gauge", "Policy requirements failed by the last scan", float64(violations))
+	writeMetric(w, "asarscan_unreadable_dirs", "gauge", "Directories the last scan could not read", float64(len(report.Metadata.UnreadableDirs)))
+	writeMetric(w, "asarscan_last_scan_timestamp_seconds", "gauge", "When the last scan finished", float64(report.Metadata.EndTime.Unix()))
+	writeMetric(w, "asarscan_last_scan_duration_seconds", "gauge", "How long the last scan took", float64(report.Metadata.DurationMs)/1000)
+
+	writeMetricHeader(w, "asarscan_scan_duration_seconds", "histogram", "How long scans took")
+	for i, bound := range scanDurationBuckets {
+		var count uint64
+		if histogram.counts != nil {
+			count = histogram.counts[i]
+		}
+		writeSample(w, "asarscan_scan_duration_seconds_bucket", `le="`+formatMetric(bound)+`"`, float64(count))
+	}
+	writeSample(w, "asarscan_scan_duration_seconds_bucket", `le="+Inf"`, float64(histogram.count))
+	writeSample(w, "asarscan_scan_duration_seconds_sum", "", histogram.sum)
+	writeSample(w, "asarscan_scan_duration_seconds_count", "", float64(histogram.count))
+}
+
+// writeMetric writes one unlabeled sample with its HELP and TYPE lines
+func writeMetric(w io.Writer, name string, kind string, help string, value float64) {
+	writeMetricHeader(w, name, kind, help)
+	writeSample(w, name, "", value)
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric family
+func writeMetricHeader(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample writes one sample; labels are already formatted, such as fuse="RunAsNode"
+func writeSample(w io.Writer, name string, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s %s\n", name, formatMetric(value))
+}
+
+// formatMetric formats a sample value without an exponent
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// boolMetric is 1 for true and 0 for false
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// cloneHistogram copies a histogram so it can be written outside its lock
+func cloneHistogram(h durationHistogram) durationHistogram {
+	h.counts = slices.Clone(h.counts)
+	return h
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// parseMetrics reads the samples of the Prometheus text format by name and
+// labels, checking that every family is declared once before its samples
+func parseMetrics(t *testing.T, text string) map[string]string {
+	t.Helper()
+	samples := make(map[string]string)
+	declared := make(map[string]bool)
+	lines := bufio.NewScanner(strings.NewReader(text))
+	for lines.Scan() {
+		line := lines.Text()
+		if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, _, _ = strings.Cut(name, " ")
+			if declared[name] {
+				t.Errorf("%s declared twice", name)
+			}
+			declared[name] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		sample, value, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("malformed sample %q", line)
+		}
+		// Histogram samples belong to the family without their suffix
+		family, _, _ := strings.Cut(sample, "{")
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			if base, ok := strings.CutSuffix(family, suffix); ok && declared[base] {
+				family = base
+			}
+		}
+		if !declared[family] {
+			t.Errorf("%s has no TYPE line before it", sample)
+		}
+		samples[sample] = value
+	}
+	return samples
+}
+
+func TestWriteReportMetrics(t *testing.T) {
+	end := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	report := electronscan.Report{
+		Metadata: electronscan.ScanMetadata{EndTime: end, DurationMs: 20500, UnreadableDirs: []string{"/Users/bob"}},
+		Results: []electronscan.AppResult{
+			{Path: "/Applications/Notes.app", AsarIntegrity: true},
+			{
+				Path:          "/Applications/A.app",
+				IsElectron:    true,
+				Framework:     electronscan.FrameworkElectron,
+				HasAsarFile:   true,
+				AsarIntegrity: true,
+				IntegrityHash: electronscan.IntegrityHashMismatch,
+				Fuses:         map[string]string{"RunAsNode": electronscan.FuseEnabled, "OnlyLoadAppFromAsar": electronscan.FuseRemoved},
+				NodeFiles:     []electronscan.NativeModule{{Path: "/a.node", Writable: true}, {Path: "/b.node", Writable: true}},
+				Risk:          &electronscan.RiskScore{Severity: electronscan.SeverityCritical},
+				PolicyViolations: []electronscan.PolicyViolation{
+					{Requirement: "require_asar_integrity"},
+					{Requirement: "fuses.RunAsNode"},
+				},
+			},
+			{
+				Path:          "/Applications/B.app",
+				IsElectron:    true,
+				Framework:     electronscan.FrameworkElectron,
+				AsarIntegrity: true,
+				IntegrityHash: electronscan.IntegrityHashMatch,
+				Fuses:         map[string]string{"RunAsNode": electronscan.FuseEnabled},
+				Risk:          &electronscan.RiskScore{Severity: electronscan.SeverityNone},
+			},
+		},
+	}
+
+	var histogram durationHistogram
+	histogram.observe(20500 * time.Millisecond)
+	histogram.observe(400 * time.Millisecond)
+	var buf strings.Builder
+	writeReportMetrics(&buf, report, histogram)
+	samples := parseMetrics(t, buf.String())
+
+	want := map[string]string{
+		"asarscan_apps_total":                          "2",
+		`asarscan_apps_fuse_enabled{fuse="RunAsNode"}`: "2",
+		// Removed fuses are not enabled, and every fuse has a sample
+		`asarscan_apps_fuse_enabled{fuse="OnlyLoadAppFromAsar"}`:    "0",
+		`asarscan_apps_fuse_enabled{fuse="EnableCookieEncryption"}`: "0",
+		"asarscan_apps_integrity_enabled":                           "2",
+		"asarscan_apps_integrity_valid":                             "1",
+		"asarscan_apps_integrity_mismatch":                          "1",
+		"asarscan_writable_native_modules":                          "2",
+		`asarscan_apps_risk{severity="critical"}`:                   "1",
+		`asarscan_apps_risk{severity="none"}`:                       "1",
+		`asarscan_apps_risk{severity="low"}`:                        "0",
+		"asarscan_policy_violations":                                "2",
+		"asarscan_unreadable_dirs":                                  "1",
+		"asarscan_last_scan_timestamp_seconds":                      "1792324800",
+		"asarscan_last_scan_duration_seconds":                       "20.5",
+		// Buckets are cumulative
+		`asarscan_scan_duration_seconds_bucket{le="1"}`:    "1",
+		`asarscan_scan_duration_seconds_bucket{le="15"}`:   "1",
+		`asarscan_scan_duration_seconds_bucket{le="30"}`:   "2",
+		`asarscan_scan_duration_seconds_bucket{le="1800"}`: "2",
+		`asarscan_scan_duration_seconds_bucket{le="+Inf"}`: "2",
+		"asarscan_scan_duration_seconds_sum":               "20.9",
+		"asarscan_scan_duration_seconds_count":             "2",
+	}
+	for sample, value := range want {
+		if samples[sample] != value {
+			t.Errorf("%s = %q, want %q", sample, samples[sample], value)
+		}
+	}
+}
+
+func TestOutputResultsPrometheusTextfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "asarscan.prom")
+	if err := os.WriteFile(path, []byte("stale\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := electronscan.Report{Metadata: electronscan.ScanMetadata{DurationMs: 1000}}
+	if err := outputResultsPrometheus(path, report); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if samples := parseMetrics(t, string(content)); samples["asarscan_apps_total"] != "0" || samples[`asarscan_scan_duration_seconds_bucket{le="1"}`] != "1" {
+		t.Errorf("metrics file:\n%s", content)
+	}
+
+	// The file is replaced, readable by node_exporter, and no temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the metrics file", len(entries))
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0o644 {
+		t.Errorf("metrics file mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	if err := outputResultsPrometheus(filepath.Join(dir, "missing", "asarscan.prom"), report); err == nil {
+		t.Error("metrics written into a missing directory")
+	}
+}
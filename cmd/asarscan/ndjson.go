@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// NDJSON event types
+const (
+	eventStart    = "start"
+	eventProgress = "progress"
+	eventApp      = "app"
+	eventError    = "error"
+	eventDone     = "done"
+)
+
+// ndjsonEvent is one line of NDJSON output. Type decides which fields are set.
+type ndjsonEvent struct {
+	Type          string                     `json:"type"`
+	Time          time.Time                  `json:"time"`
+	SchemaVersion string                     `json:"schema_version,omitempty"`
+	Version       string                     `json:"version,omitempty"`
+	OS            string                     `json:"os,omitempty"`
+	Path          string                     `json:"path,omitempty"`
+	Checked       int                        `json:"checked,omitempty"`
+	Total         int                        `json:"total,omitempty"`
+	App           *electronscan.AppResult    `json:"app,omitempty"`
+	Error         string                     `json:"error,omitempty"`
+	DurationMs    int64                      `json:"duration_ms,omitempty"`
+	Metadata      *electronscan.ScanMetadata `json:"metadata,omitempty"`
+}
+
+// ndjsonWriter streams scan events as newline-delimited JSON as they happen
+type ndjsonWriter struct {
+	enc         *json.Encoder
+	start       time.Time
+	minSeverity string
+}
+
+// newNdjsonWriter creates a writer that emits one compact JSON object per line
+func newNdjsonWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{
+		enc:   json.NewEncoder(w),
+		start: time.Now(),
+	}
+}
+
+// emit writes a single event line
+func (n *ndjsonWriter) emit(event ndjsonEvent) {
+	event.Time = time.Now().UTC()
+	if err := n.enc.Encode(event); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding NDJSON event: %v\n", err)
+	}
+}
+
+// Start announces the scan and how many applications will be checked
+func (n *ndjsonWriter) Start(total int) {
+	n.emit(ndjsonEvent{Type: eventStart, SchemaVersion: electronscan.SchemaVersion, Version: version, OS: runtime.GOOS, Total: total})
+}
+
+// App writes the result for one application along with a progress event.
+// Apps below the minimum severity only count towards progress.
+func (n *ndjsonWriter) App(result electronscan.AppResult, checked int, total int) {
+	if n.minSeverity == "" || result.Risk != nil && electronscan.SeverityAtLeast(result.Risk.Severity, n.minSeverity) {
+		n.emit(ndjsonEvent{Type: eventApp, Path: result.Path, App: &result})
+	}
+	if result.IntegrityError != "" {
+		n.Error(result.Path, result.IntegrityError)
+	}
+	n.emit(ndjsonEvent{Type: eventProgress, Path: result.Path, Checked: checked, Total: total})
+}
+
+// Error reports a failure that did not stop the scan
+func (n *ndjsonWriter) Error(path string, message string) {
+	n.emit(ndjsonEvent{Type: eventError, Path: path, Error: message})
+}
+
+// Done marks the end of the stream. metadata describes the scan and is nil if it failed.
+func (n *ndjsonWriter) Done(checked int, metadata *electronscan.ScanMetadata) {
+	n.emit(ndjsonEvent{Type: eventDone, Checked: checked, Total: checked, DurationMs: time.Since(n.start).Milliseconds(), Metadata: metadata})
+}
+
+// Watch writes a change seen by watch mode as its own line
+func (n *ndjsonWriter) Watch(event electronscan.WatchEvent) {
+	if err := n.enc.Encode(event); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding NDJSON event: %v\n", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// readEvents decodes NDJSON output, checking that each event is on one line
+func readEvents(t *testing.T, output []byte) []ndjsonEvent {
+	t.Helper()
+	var events []ndjsonEvent
+	lines := bufio.NewScanner(bytes.NewReader(output))
+	lines.Buffer(nil, 1<<20)
+	for lines.Scan() {
+		var event ndjsonEvent
+		if err := json.Unmarshal(lines.Bytes(), &event); err != nil {
+			t.Fatalf("line %q: %v", lines.Text(), err)
+		}
+		if event.Time.IsZero() {
+			t.Errorf("%s event has no time", event.Type)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestNdjsonWriterSequence(t *testing.T) {
+	var buf bytes.Buffer
+	n := newNdjsonWriter(&buf)
+	n.Start(2)
+	n.App(electronscan.AppResult{Path: "/Applications/A.app"}, 1, 2)
+	n.App(electronscan.AppResult{Path: "/Applications/B.app", IntegrityError: "unexpected EOF"}, 2, 2)
+	n.Done(2, &electronscan.ScanMetadata{Host: "build-07"})
+
+	var got []string
+	for _, event := range readEvents(t, buf.Bytes()) {
+		desc := event.Type
+		switch event.Type {
+		case eventStart:
+			desc += fmt.Sprintf(" schema=%s total=%d", event.SchemaVersion, event.Total)
+		case eventApp:
+			desc += " " + event.App.Path
+		case eventProgress:
+			desc += fmt.Sprintf(" %s %d/%d", event.Path, event.Checked, event.Total)
+		case eventError:
+			desc += " " + event.Path + ": " + event.Error
+		case eventDone:
+			desc += fmt.Sprintf(" checked=%d host=%s", event.Checked, event.Metadata.Host)
+		}
+		got = append(got, desc)
+	}
+	want := []string{
+		"start schema=" + electronscan.SchemaVersion + " total=2",
+		"app /Applications/A.app",
+		"progress /Applications/A.app 1/2",
+		"app /Applications/B.app",
+		"error /Applications/B.app: unexpected EOF",
+		"progress /Applications/B.app 2/2",
+		"done checked=2 host=build-07",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestNdjsonWriterMinSeverity(t *testing.T) {
+	var buf bytes.Buffer
+	n := newNdjsonWriter(&buf)
+	n.minSeverity = electronscan.SeverityMedium
+	apps := []electronscan.AppResult{
+		{Path: "unscored"},
+		{Path: "low", Risk: &electronscan.RiskScore{Severity: electronscan.SeverityLow}},
+		{Path: "medium", Risk: &electronscan.RiskScore{Severity: electronscan.SeverityMedium}},
+		{Path: "critical", Risk: &electronscan.RiskScore{Severity: electronscan.SeverityCritical}},
+	}
+	for i, app := range apps {
+		n.App(app, i+1, len(apps))
+	}
+
+	// Filtered apps still advance the progress count
+	var written []string
+	progress := 0
+	for _, event := range readEvents(t, buf.Bytes()) {
+		switch event.Type {
+		case eventApp:
+			written = append(written, event.Path)
+		case eventProgress:
+			progress++
+		}
+	}
+	if want := []string{"medium", "critical"}; !slices.Equal(written, want) {
+		t.Errorf("apps = %q, want %q", written, want)
+	}
+	if progress != len(apps) {
+		t.Errorf("got %d progress events, want %d", progress, len(apps))
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateSource string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(reportTemplateSource))
+
+// reportData is the view model for the HTML report
+type reportData struct {
+	Version     string
+	GeneratedAt string
+	Metadata    electronscan.ScanMetadata
+	Duration    time.Duration
+	TotalApps   int
+	Apps        []reportApp
+	Severities  []reportBar
+	RuleBars    []reportBar
+	ChartHeight int
+}
+
+// reportApp is one row of the application table plus its expandable details
+type reportApp struct {
+	electronscan.AppResult
+	Name          string
+	Findings      []electronscan.Finding
+	High          int
+	Medium        int
+	Low           int
+	WritablePaths []string
+	FuseList      []reportFuse
+}
+
+// reportFuse is a fuse state in wire order
+type reportFuse struct {
+	Name  string
+	State string
+}
+
+// reportBar is one bar of the inline SVG risk summary chart
+type reportBar struct {
+	Label    string
+	Count    int
+	Severity string
+	Y        int
+	Width    int
+}
+
+// chart geometry for the risk summary
+const (
+	chartBarHeight = 22
+	chartMaxWidth  = 420
+)
+
+// outputResultsHtml outputs a self-contained HTML report with no external assets
+func outputResultsHtml(w io.Writer, report electronscan.Report) error {
+	results := report.Results
+	data := reportData{
+		Version:     version,
+		GeneratedAt: time.Now().Format(time.RFC1123),
+		Metadata:    report.Metadata,
+		Duration:    time.Duration(report.Metadata.DurationMs) * time.Millisecond,
+		TotalApps:   len(results),
+	}
+
+	severityCounts := make(map[string]int)
+	ruleCounts := make(map[string]int)
+
+	for _, result := range results {
+		if result.Framework == "" {
+			continue
+		}
+
+		app := reportApp{
+			AppResult: result,
+			Name:      filepath.Base(result.Path),
+			Findings:  electronscan.Findings(result),
+		}
+		for _, finding := range app.Findings {
+			switch finding.Severity {
+			case electronscan.SeverityHigh:
+				app.High++
+			case electronscan.SeverityMedium:
+				app.Medium++
+			default:
+				app.Low++
+			}
+			severityCounts[finding.Severity]++
+			ruleCounts[finding.RuleID]++
+		}
+
+		for _, name := range electronscan.FuseNames {
+			if state, ok := result.Fuses[name]; ok {
+				app.FuseList = append(app.FuseList, reportFuse{Name: name, State: state})
+			}
+		}
+		app.WritablePaths = writablePaths(result)
+
+		data.Apps = append(data.Apps, app)
+	}
+
+	// Severity totals come first, then one bar per rule that fired
+	maxCount := 1
+	for _, count := range severityCounts {
+		maxCount = max(maxCount, count)
+	}
+	for _, count := range ruleCounts {
+		maxCount = max(maxCount, count)
+	}
+
+	y := 0
+	for _, severity := range []string{electronscan.SeverityHigh, electronscan.SeverityMedium, electronscan.SeverityLow} {
+		data.Severities = append(data.Severities, reportBar{
+			Label:    strings.ToUpper(severity[:1]) + severity[1:] + " findings",
+			Count:    severityCounts[severity],
+			Severity: severity,
+			Y:        y,
+			Width:    severityCounts[severity] * chartMaxWidth / maxCount,
+		})
+		y += chartBarHeight
+	}
+	y += chartBarHeight / 2
+
+	for _, rule := range electronscan.Rules {
+		count := ruleCounts[rule.ID]
+		if count == 0 {
+			continue
+		}
+		data.RuleBars = append(data.RuleBars, reportBar{
+			Label:    rule.ID,
+			Count:    count,
+			Severity: rule.Severity,
+			Y:        y,
+			Width:    count * chartMaxWidth / maxCount,
+		})
+		y += chartBarHeight
+	}
+	data.ChartHeight = y
+
+	// Most findings first so the report opens on what matters
+	sort.SliceStable(data.Apps, func(i, j int) bool {
+		a, b := data.Apps[i], data.Apps[j]
+		if a.High != b.High {
+			return a.High > b.High
+		}
+		return len(a.Findings) > len(b.Findings)
+	})
+
+	if err := reportTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("error rendering HTML report: %v", err)
+	}
+	return nil
+}
+
+// writablePaths collects every user-writable location found for an application
+func writablePaths(result electronscan.AppResult) []string {
+	var paths []string
+	for _, module := range result.NodeFiles {
+		if module.Writable {
+			paths = append(paths, module.Path)
+		}
+		for _, dep := range module.Dependencies {
+			if dep.Writable {
+				paths = append(paths, dep.Resolved)
+			}
+		}
+	}
+	if entry := result.EntryPoint; entry != nil {
+		for _, script := range append([]electronscan.ScriptInfo{entry.Main}, entry.Preloads...) {
+			if script.Writable {
+				paths = append(paths, script.Path)
+			}
+		}
+	}
+	if result.Updater != nil && result.Updater.CacheWritable {
+		paths = append(paths, result.Updater.CachePath)
+	}
+	return paths
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+func TestOutputResultsHtml(t *testing.T) {
+	report := electronscan.Report{
+		Metadata: electronscan.ScanMetadata{
+			Host:           "build-07",
+			OSVersion:      "macOS 14.5",
+			Arch:           "arm64",
+			User:           "alice",
+			DurationMs:     1500,
+			ScannedDirs:    []string{"/Applications", "/Users/alice/Applications"},
+			UnreadableDirs: []string{"/Users/bob/Applications"},
+		},
+		Results: []electronscan.AppResult{
+			{Path: "/Applications/Notes.app"},
+			{
+				Path:       "/Applications/Quiet.app",
+				IsElectron: true,
+				Framework:  electronscan.FrameworkElectron,
+				Version:    "unknown",
+				Signature:  electronscan.SignatureUnsigned,
+			},
+			{
+				Path:       "/Applications/<img src=x onerror=alert(1)>.app",
+				IsElectron: true,
+				Framework:  electronscan.FrameworkElectron,
+				Version:    "unknown",
+				Fuses: map[string]string{
+					"RunAsNode":                     electronscan.FuseEnabled,
+					"EnableNodeCliInspectArguments": electronscan.FuseEnabled,
+				},
+				Updater: &electronscan.UpdaterInfo{
+					Mechanisms:    []string{"electron-updater"},
+					CachePath:     "/Users/alice/Library/Caches/demo-updater",
+					CacheWritable: true,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := outputResultsHtml(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	// Application names come from the disk and must not become markup
+	if strings.Contains(html, "<img") || !strings.Contains(html, "&lt;img src=x onerror=alert(1)&gt;.app") {
+		t.Error("application name is not escaped")
+	}
+
+	// The report is a single file: no stylesheet, script or image is fetched
+	if external := regexp.MustCompile(`(?i)(src|href)="(https?:)?//`).FindString(html); external != "" {
+		t.Errorf("report references an external asset: %s", external)
+	}
+
+	for _, want := range []string{
+		"3 apps scanned, 2 Chromium-based",
+		"build-07 &middot; macOS 14.5 &middot; arm64 &middot; scanned as alice in 1.5s",
+		"/Applications, /Users/alice/Applications",
+		"Could not read: /Users/bob/Applications",
+		"<li><code>/Users/alice/Library/Caches/demo-updater</code></li>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	// The application with high findings is listed before the one with a
+	// single medium finding
+	names := regexp.MustCompile(`<summary>(.*?) &mdash; (\d+) findings</summary>`).FindAllStringSubmatch(html, -1)
+	var order []string
+	for _, name := range names {
+		order = append(order, name[1]+" "+name[2])
+	}
+	if want := []string{"&lt;img src=x onerror=alert(1)&gt;.app 3", "Quiet.app 1"}; !slices.Equal(order, want) {
+		t.Errorf("applications = %q, want %q", order, want)
+	}
+}
+
+func TestOutputResultsHtmlChart(t *testing.T) {
+	var results []electronscan.AppResult
+	for range 4 {
+		results = append(results, electronscan.AppResult{
+			Path:       "/Applications/Demo.app",
+			IsElectron: true,
+			Framework:  electronscan.FrameworkElectron,
+			Version:    "unknown",
+			Fuses:      map[string]string{"RunAsNode": electronscan.FuseEnabled},
+		})
+	}
+	results[0].Signature = electronscan.SignatureUnsigned
+
+	var buf bytes.Buffer
+	if err := outputResultsHtml(&buf, electronscan.Report{Results: results}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The longest bar spans the chart; the others scale against it and
+	// rules that never fired get no bar
+	bars := regexp.MustCompile(`<rect x="240" y="\d+" width="(\d+)" height="16" class="bar-(\w+)"/>`).FindAllStringSubmatch(buf.String(), -1)
+	var got []string
+	for _, bar := range bars {
+		got = append(got, bar[2]+" "+bar[1])
+	}
+	want := []string{
+		"high 420",   // four RunAsNode findings
+		"medium 105", // one unsigned executable
+		"low 0",
+		"high 420",
+		"medium 105",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("bars = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// SARIF 2.1.0 structures, limited to the fields we emit
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	StartTimeUTC               string              `json:"startTimeUtc"`
+	EndTimeUTC                 string              `json:"endTimeUtc"`
+	Machine                    string              `json:"machine,omitempty"`
+	Account                    string              `json:"account,omitempty"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	Properties                 map[string]any      `json:"properties"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps a finding severity to a SARIF level
+func sarifLevel(severity string) string {
+	switch severity {
+	case electronscan.SeverityHigh:
+		return "error"
+	case electronscan.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps a finding severity to the numeric score code scanning dashboards sort by
+func sarifSecuritySeverity(severity string) string {
+	switch severity {
+	case electronscan.SeverityHigh:
+		return "8.0"
+	case electronscan.SeverityMedium:
+		return "5.0"
+	default:
+		return "3.0"
+	}
+}
+
+// fileURI converts an absolute path into a file:// URI
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// outputResultsSarif outputs every finding as a SARIF 2.1.0 log
+func outputResultsSarif(w io.Writer, report electronscan.Report) {
+	driver := sarifDriver{
+		Name:           "asarscan",
+		Version:        version,
+		InformationURI: "https://github.com/adversis/asar-scan",
+	}
+
+	ruleIndex := make(map[string]int)
+	for i, rule := range electronscan.Rules {
+		ruleIndex[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(rule.Severity)},
+			Properties: map[string]string{
+				"security-severity": sarifSecuritySeverity(rule.Severity),
+			},
+		})
+	}
+
+	// The invocation carries the scan metadata so logs from many machines can be told apart
+	metadata := report.Metadata
+	invocation := sarifInvocation{
+		ExecutionSuccessful: true,
+		StartTimeUTC:        metadata.StartTime.Format(time.RFC3339),
+		EndTimeUTC:          metadata.EndTime.Format(time.RFC3339),
+		Machine:             metadata.Host,
+		Account:             metadata.User,
+		Properties: map[string]any{
+			"schema_version": report.SchemaVersion,
+			"os":             metadata.OS,
+			"os_version":     metadata.OSVersion,
+			"arch":           metadata.Arch,
+			"privilege":      metadata.Privilege,
+			"duration_ms":    metadata.DurationMs,
+			"scanned_dirs":   metadata.ScannedDirs,
+		},
+	}
+	for _, dir := range metadata.UnreadableDirs {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+			Level:   "warning",
+			Message: sarifMessage{Text: "Could not read directory"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: fileURI(dir)},
+				},
+			}},
+		})
+	}
+
+	run := sarifRun{
+		Tool:        sarifTool{Driver: driver},
+		Invocations: []sarifInvocation{invocation},
+		Results:     []sarifResult{},
+	}
+
+	for _, result := range report.Results {
+		for _, finding := range electronscan.Findings(result) {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    finding.RuleID,
+				RuleIndex: ruleIndex[finding.RuleID],
+				Level:     sarifLevel(finding.Severity),
+				Message:   sarifMessage{Text: finding.Message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: fileURI(finding.Path)},
+					},
+				}},
+				Properties: map[string]string{
+					"application": result.Path,
+				},
+			})
+		}
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	jsonData, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding SARIF: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(w, string(jsonData))
+}
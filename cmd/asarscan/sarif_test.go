@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, rewriting the file with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (rerun with -update to accept it):\n%s", path, got)
+	}
+}
+
+func TestOutputResultsSarif(t *testing.T) {
+	if filepath.Separator != '/' {
+		t.Skip("the golden file holds slash-separated paths")
+	}
+	start := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC)
+	unsigned := electronscan.Finding{
+		RuleID:   electronscan.RuleUnsignedExecutable,
+		Severity: electronscan.SeverityMedium,
+		Message:  "Demo is not code signed",
+		Path:     "/Applications/Demo.app/Contents/MacOS/Demo",
+	}
+	report := electronscan.Report{
+		SchemaVersion: electronscan.SchemaVersion,
+		Metadata: electronscan.ScanMetadata{
+			Tool:           "asarscan",
+			ToolVersion:    version,
+			Host:           "build-mac-07",
+			OS:             "darwin",
+			OSVersion:      "15.3",
+			Arch:           "arm64",
+			User:           "ci",
+			Privilege:      "user",
+			StartTime:      start,
+			EndTime:        start.Add(1500 * time.Millisecond),
+			DurationMs:     1500,
+			ScannedDirs:    []string{"/Applications"},
+			UnreadableDirs: []string{"/Applications/Locked App.app"},
+		},
+		Results: []electronscan.AppResult{
+			{
+				Path:         "/Applications/Demo.app",
+				IsElectron:   true,
+				Framework:    electronscan.FrameworkElectron,
+				HasAsarFile:  true,
+				Executable:   "/Applications/Demo.app/Contents/MacOS/Demo",
+				ResourcesDir: "/Applications/Demo.app/Contents/Resources",
+				Fuses:        map[string]string{"RunAsNode": electronscan.FuseEnabled},
+				Signature:    electronscan.SignatureUnsigned,
+				SuppressedFindings: []electronscan.SuppressedFinding{
+					{Finding: unsigned, Justification: "internal build", Expires: "2027-01-01"},
+				},
+			},
+			{
+				Path:              "/Applications/Kiosk.app",
+				Framework:         electronscan.FrameworkNWJS,
+				FrameworkEvidence: "/Applications/Kiosk.app/Contents/Resources/app.nw/package.json",
+				FrameworkFindings: []string{"node-remote allows remote pages to use Node.js"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	outputResultsSarif(&buf, report)
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("output is not valid JSON:\n%s", buf.String())
+	}
+	golden(t, "report.sarif", buf.Bytes())
+}
+
+func TestFileURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/Applications/Demo.app", "file:///Applications/Demo.app"},
+		{"/opt/My App/resources/app.asar", "file:///opt/My%20App/resources/app.asar"},
+		{"/tmp/100%/a#b?c", "file:///tmp/100%25/a%23b%3Fc"},
+	}
+	for _, tt := range tests {
+		if got := fileURI(tt.path); got != tt.want {
+			t.Errorf("fileURI(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
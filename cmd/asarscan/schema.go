@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// runSchema implements "asarscan schema": print the JSON Schema of -format json reports
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Parse(args)
+
+	os.Stdout.Write(electronscan.ReportSchema())
+}
+
+// runValidate implements "asarscan validate report.json...": check reports
+// against the schema. It exits with status 1 if any report does not conform.
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asarscan validate report.json...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = electronscan.ValidateReport(data)
+		}
+
+		var invalid *electronscan.SchemaError
+		switch {
+		case errors.As(err, &invalid):
+			for _, problem := range invalid.Problems {
+				fmt.Printf("%s: %s\n", path, problem)
+			}
+			failed = true
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			failed = true
+		default:
+			fmt.Printf("%s: valid (schema %s)\n", path, electronscan.SchemaVersion)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// runServe implements "asarscan serve": an HTTP agent a central collector can
+// poll for the latest report and ask to rescan
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "Enable debug logging on stderr")
+	configPath := fs.String("config", electronscan.DefaultConfigPath(), "Read search roots, exclusions, suppressions and the policy from this JSON or YAML file")
+	listen := fs.String("listen", "127.0.0.1:8080", "Address to listen on")
+	token := fs.String("token", os.Getenv("ASARSCAN_TOKEN"), "Require this bearer token on every request (default $ASARSCAN_TOKEN)")
+	workers := fs.Int("workers", 0, "Number of applications to check in parallel (0 for one per CPU)")
+	fs.Parse(args)
+
+	config := loadConfig(*configPath)
+	logger := newLogger(*verbose)
+	checkSupportedOS("", false)
+	warnExpiredSuppressions(config, logger)
+
+	var policy *electronscan.Policy
+	if config.Policy != "" {
+		var err error
+		if policy, err = electronscan.LoadPolicy(config.Policy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *workers == 0 {
+		*workers = config.Workers
+	}
+
+	if host, _, err := net.SplitHostPort(*listen); err == nil && *token == "" {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			logger.Warn("Listening beyond localhost without -token; anyone who can connect can read results and trigger scans", "listen", *listen)
+		}
+	}
+
+	agent := &scanAgent{
+		scanner: electronscan.New(electronscan.Options{
+			Roots:        config.SearchRoots,
+			Logger:       logger,
+			Workers:      *workers,
+			MaxNodeFiles: config.MaxNodeFiles,
+			Exclude:      config.Exclude,
+			Suppressions: config.Suppressions,
+			Policy:       policy,
+		}),
+		logger: logger,
+		token:  *token,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := &http.Server{
+		Handler:           agent.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	logger.Info("Serving", "listen", listener.Addr().String())
+
+	// Have results ready for the first poll
+	agent.scan()
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("Stopping server")
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// scanAgent runs one scan at a time and keeps the latest report
+type scanAgent struct {
+	scanner *electronscan.Scanner
+	logger  *slog.Logger
+	token   string
+
+	mu sync.Mutex
+	// running is closed when the scan in progress finishes; nil when idle
+	running   chan struct{}
+	report    *electronscan.Report
+	scans     int
+	failures  int
+	durations durationHistogram
+}
+
+// scan starts a scan unless one is already running. The returned channel is
+// closed when that scan, new or already running, has finished.
+func (a *scanAgent) scan() <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running != nil {
+		return a.running
+	}
+
+	done := make(chan struct{})
+	a.running = done
+	go func() {
+		start := time.Now()
+		results, err := a.scanner.Scan()
+		var report electronscan.Report
+		if err == nil {
+			report = a.scanner.NewReport(results, version, start, time.Now())
+		}
+
+		a.mu.Lock()
+		if err != nil {
+			a.logger.Error("Error scanning for applications", "error", err)
+			a.failures++
+		} else {
+			a.logger.Info("Scan finished", "apps", len(results), "duration", time.Since(start).Round(time.Millisecond))
+			a.report = &report
+			a.scans++
+			a.durations.observe(time.Since(start))
+		}
+		a.running = nil
+		a.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+// latest returns the report of the last successful scan, or nil
+func (a *scanAgent) latest() *electronscan.Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.report
+}
+
+// handler routes the agent's endpoints
+func (a *scanAgent) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan", a.handleScan)
+	mux.HandleFunc("GET /results", a.handleResults)
+	mux.HandleFunc("GET /apps/{id}", a.handleApp)
+	mux.HandleFunc("GET /metrics", a.handleMetrics)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.logger.Debug("Request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+		if a.token != "" {
+			given, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(a.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or wrong bearer token"})
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// handleScan starts a scan, or joins the one running. With ?wait=true it
+// responds with the report once the scan finishes.
+func (a *scanAgent) handleScan(w http.ResponseWriter, r *http.Request) {
+	done := a.scan()
+	if r.URL.Query().Get("wait") != "true" {
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "scanning"})
+		return
+	}
+
+	select {
+	case <-done:
+	case <-r.Context().Done():
+		return
+	}
+	a.handleResults(w, r)
+}
+
+// handleResults responds with the latest report
+func (a *scanAgent) handleResults(w http.ResponseWriter, r *http.Request) {
+	report := a.latest()
+	if report == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "no scan has completed yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleApp responds with the result of one application from the latest report,
+// found by bundle ID or by bundle or executable name as in "asarscan aggregate"
+func (a *scanAgent) handleApp(w http.ResponseWriter, r *http.Request) {
+	report := a.latest()
+	if report == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "no scan has completed yet"})
+		return
+	}
+
+	id := r.PathValue("id")
+	for _, result := range report.Results {
+		if result.Framework != "" && strings.EqualFold(electronscan.AppID(result), id) {
+			writeJSON(w, http.StatusOK, result)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "no application " + id})
+}
+
+// handleMetrics responds in the Prometheus text exposition format
+func (a *scanAgent) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	report, scans, failures, running := a.report, a.scans, a.failures, a.running != nil
+	durations := cloneHistogram(a.durations)
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "asarscan_scans_total", "counter", "Scans completed since the agent started", float64(scans))
+	writeMetric(w, "asarscan_scan_failures_total", "counter", "Scans that could not search for applications", float64(failures))
+	writeMetric(w, "asarscan_scan_in_progress", "gauge", "Whether a scan is running", boolMetric(running))
+	if report != nil {
+		writeReportMetrics(w, *report, durations)
+	}
+}
+
+// writeJSON responds with an indented JSON body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(jsonData, '\n'))
+}
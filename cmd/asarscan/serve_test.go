@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// gatedFS holds every file access until gate is closed, closing entered on the first
+type gatedFS struct {
+	fsys    fstest.MapFS
+	once    sync.Once
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (g *gatedFS) Open(name string) (fs.File, error) {
+	g.once.Do(func() { close(g.entered) })
+	<-g.gate
+	return g.fsys.Open(name)
+}
+
+// demoImage returns an image with one Electron application in /Applications
+func demoImage(t *testing.T) fstest.MapFS {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	if _, err := (fixture.App{}).Build(fsys, "Applications"); err != nil {
+		t.Fatal(err)
+	}
+	return fsys
+}
+
+// newTestAgent returns an agent scanning fsys as a macOS image
+func newTestAgent(fsys fs.FS, token string) *scanAgent {
+	return &scanAgent{
+		scanner: electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys, Workers: 1}),
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		token:   token,
+	}
+}
+
+// serve sends one request to the agent's handler
+func serve(agent *scanAgent, method string, target string, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	agent.handler().ServeHTTP(w, r)
+	return w
+}
+
+func TestServeRequiresToken(t *testing.T) {
+	agent := newTestAgent(demoImage(t), "s3cret")
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "guess", http.StatusUnauthorized},
+		{"token prefix", "s3c", http.StatusUnauthorized},
+		{"right token", "s3cret", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(agent, http.MethodGet, "/results", tt.token)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestServeBeforeFirstScan(t *testing.T) {
+	agent := newTestAgent(demoImage(t), "")
+	for _, target := range []string{"/results", "/apps/com.example.demo"} {
+		if w := serve(agent, http.MethodGet, target, ""); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("GET %s status = %d, want %d", target, w.Code, http.StatusServiceUnavailable)
+		}
+	}
+	if w := serve(agent, http.MethodGet, "/scan", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /scan status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServeScanAndApps(t *testing.T) {
+	agent := newTestAgent(demoImage(t), "")
+
+	w := serve(agent, http.MethodPost, "/scan?wait=true", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /scan?wait=true status = %d: %s", w.Code, w.Body)
+	}
+	var report electronscan.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.SchemaVersion != electronscan.SchemaVersion || len(report.Results) != 1 || report.Results[0].BundleID != "com.example.demo" {
+		t.Fatalf("report = %+v", report)
+	}
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"com.example.demo", http.StatusOK},
+		{"COM.EXAMPLE.DEMO", http.StatusOK},
+		{"com.example.other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(agent, http.MethodGet, "/apps/"+tt.id, ""); w.Code != tt.want {
+			t.Errorf("GET /apps/%s status = %d, want %d", tt.id, w.Code, tt.want)
+		}
+	}
+}
+
+func TestServeSharesRunningScan(t *testing.T) {
+	image := &gatedFS{fsys: demoImage(t), entered: make(chan struct{}), gate: make(chan struct{})}
+	agent := newTestAgent(image, "")
+
+	waited := make(chan *httptest.ResponseRecorder)
+	go func() { waited <- serve(agent, http.MethodPost, "/scan?wait=true", "") }()
+	<-image.entered
+
+	// A second request while the first scan is held joins it
+	if w := serve(agent, http.MethodPost, "/scan", ""); w.Code != http.StatusAccepted {
+		t.Errorf("second POST /scan status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if metrics := serve(agent, http.MethodGet, "/metrics", "").Body.String(); !strings.Contains(metrics, "\nasarscan_scan_in_progress 1\n") {
+		t.Errorf("metrics during the scan:\n%s", metrics)
+	}
+
+	close(image.gate)
+	if w := <-waited; w.Code != http.StatusOK {
+		t.Fatalf("POST /scan?wait=true status = %d", w.Code)
+	}
+	agent.mu.Lock()
+	scans := agent.scans
+	agent.mu.Unlock()
+	if scans != 1 {
+		t.Errorf("scans = %d, want the two requests to share one", scans)
+	}
+}
+
+// metricSampleRegex matches a sample line: name, optional labels and a value
+var metricSampleRegex = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{(?:[a-zA-Z_][a-zA-Z0-9_]*="[^"]*",?)*\})? (\S+)$`)
+
+func TestServeMetrics(t *testing.T) {
+	agent := newTestAgent(demoImage(t), "")
+	<-agent.scan()
+
+	w := serve(agent, http.MethodGet, "/metrics", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("status = %d, content type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// Every sample belongs to a family declared before it with HELP and TYPE
+	types := make(map[string]string)
+	samples := make(map[string]float64)
+	for _, line := range strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n") {
+		if rest, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			name, kind, _ := strings.Cut(rest, " ")
+			types[name] = kind
+			continue
+		}
+		if strings.HasPrefix(line, "# HELP ") {
+			continue
+		}
+		matches := metricSampleRegex.FindStringSubmatch(line)
+		if matches == nil {
+			t.Errorf("malformed sample: %q", line)
+			continue
+		}
+		family := matches[1]
+		if _, ok := types[family]; !ok {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				if base, ok := strings.CutSuffix(family, suffix); ok && types[base] == "histogram" {
+					family = base
+				}
+			}
+		}
+		if _, ok := types[family]; !ok {
+			t.Errorf("sample without a TYPE line: %q", line)
+		}
+		value, err := strconv.ParseFloat(matches[3], 64)
+		if err != nil {
+			t.Errorf("sample value: %q", line)
+		}
+		samples[matches[1]+matches[2]] = value
+	}
+
+	want := map[string]float64{
+		"asarscan_scans_total":                                   1,
+		"asarscan_scan_failures_total":                           0,
+		"asarscan_scan_in_progress":                              0,
+		"asarscan_apps_total":                                    1,
+		`asarscan_apps_fuse_enabled{fuse="RunAsNode"}`:           1,
+		`asarscan_apps_fuse_enabled{fuse="OnlyLoadAppFromAsar"}`: 0,
+		`asarscan_scan_duration_seconds_bucket{le="+Inf"}`:       1,
+		"asarscan_scan_duration_seconds_count":                   1,
+	}
+	for sample, value := range want {
+		if got, ok := samples[sample]; !ok || got != value {
+			t.Errorf("%s = %v (present %t), want %v", sample, got, ok, value)
+		}
+	}
+}
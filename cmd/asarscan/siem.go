@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// siemFormats are the message formats of -syslog-format
+var siemFormats = []string{"cef", "leef", "json"}
+
+// validSiemFormat reports whether format is one of siemFormats
+func validSiemFormat(format string) bool {
+	return slices.Contains(siemFormats, format)
+}
+
+// Event IDs of watch events, alongside the rule IDs of findings
+const (
+	eventUnexpectedChange = "unexpected-code-change"
+	eventAppUpdated       = "app-updated"
+)
+
+// siemEvent is one finding or watch event with the fields SIEMs index
+type siemEvent struct {
+	Time      time.Time `json:"time"`
+	Host      string    `json:"host"`
+	EventID   string    `json:"event_id"`
+	Name      string    `json:"name"`
+	Severity  string    `json:"severity"`
+	Message   string    `json:"message"`
+	AppPath   string    `json:"app_path"`
+	BundleID  string    `json:"bundle_id,omitempty"`
+	Framework string    `json:"framework,omitempty"`
+	Version   string    `json:"electron_version,omitempty"`
+	FilePath  string    `json:"file_path,omitempty"`
+}
+
+// findingSiemEvent describes a finding of an application
+func findingSiemEvent(result electronscan.AppResult, finding electronscan.Finding) siemEvent {
+	host, _ := os.Hostname()
+	name := finding.RuleID
+	if rule, ok := electronscan.RuleByID(finding.RuleID); ok {
+		name = rule.Name
+	}
+	return siemEvent{
+		Time:      time.Now(),
+		Host:      host,
+		EventID:   finding.RuleID,
+		Name:      name,
+		Severity:  finding.Severity,
+		Message:   finding.Message,
+		AppPath:   result.Path,
+		BundleID:  result.BundleID,
+		Framework: result.Framework,
+		Version:   result.Version,
+		FilePath:  finding.Path,
+	}
+}
+
+// watchSiemEvent describes a watch event. Alerts are high severity; updates are expected.
+func watchSiemEvent(event electronscan.WatchEvent) siemEvent {
+	host, _ := os.Hostname()
+	e := siemEvent{
+		Time:     event.Time,
+		Host:     host,
+		AppPath:  event.App,
+		FilePath: event.Path,
+	}
+	if event.Type == electronscan.WatchAlert {
+		e.EventID, e.Name, e.Severity = eventUnexpectedChange, "UnexpectedCodeChange", electronscan.SeverityHigh
+		e.Message = fmt.Sprintf("%s file %s outside of a verified update", event.Kind, event.Change)
+	} else {
+		e.EventID, e.Name, e.Severity = eventAppUpdated, "AppUpdated", electronscan.SeverityLow
+		e.Message = fmt.Sprintf("Updated from %s to %s", valueOrNone(event.OldVersion), valueOrNone(event.NewVersion))
+	}
+	return e
+}
+
+// numericSeverity maps a severity to the 0-10 scale of CEF and LEEF
+func (e siemEvent) numericSeverity() int {
+	switch e.Severity {
+	case electronscan.SeverityHigh:
+		return 8
+	case electronscan.SeverityMedium:
+		return 5
+	case electronscan.SeverityLow:
+		return 3
+	}
+	return 0
+}
+
+// syslogSeverity maps a severity to a syslog severity
+func (e siemEvent) syslogSeverity() int {
+	switch e.Severity {
+	case electronscan.SeverityHigh:
+		return syslogError
+	case electronscan.SeverityMedium:
+		return syslogWarning
+	case electronscan.SeverityLow:
+		return syslogNotice
+	}
+	return syslogInfo
+}
+
+// format renders the event as "cef", "leef" or "json"
+func (e siemEvent) format(format string) string {
+	switch format {
+	case "leef":
+		return e.leef()
+	case "json":
+		jsonData, _ := json.Marshal(e)
+		return string(jsonData)
+	default:
+		return e.cef()
+	}
+}
+
+// cef renders the event in ArcSight Common Event Format:
+// CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func (e siemEvent) cef() string {
+	header := strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	value := strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+	var extension []string
+	add := func(key string, v string) {
+		if v != "" {
+			extension = append(extension, key+"="+value.Replace(v))
+		}
+	}
+	add("rt", strconv.FormatInt(e.Time.UnixMilli(), 10))
+	add("dvchost", e.Host)
+	add("cat", e.EventID)
+	add("msg", e.Message)
+	add("filePath", e.FilePath)
+	if e.AppPath != "" {
+		add("cs1Label", "appPath")
+		add("cs1", e.AppPath)
+	}
+	if e.BundleID != "" {
+		add("cs2Label", "bundleId")
+		add("cs2", e.BundleID)
+	}
+	if e.Framework != "" {
+		add("cs3Label", "framework")
+		add("cs3", e.Framework)
+	}
+	if e.Version != "" {
+		add("cs4Label", "electronVersion")
+		add("cs4", e.Version)
+	}
+	add("cs5Label", "severity")
+	add("cs5", e.Severity)
+
+	return fmt.Sprintf("CEF:0|Adversis|asarscan|%s|%s|%s|%d|%s",
+		header.Replace(version), header.Replace(e.EventID), header.Replace(e.Name), e.numericSeverity(), strings.Join(extension, " "))
+}
+
+// leef renders the event in IBM QRadar Log Event Extended Format 1.0, whose
+// attributes are separated by tabs:
+// LEEF:Version|Vendor|Product|Version|EventID|Attributes
+func (e siemEvent) leef() string {
+	header := strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	value := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+	var attributes []string
+	add := func(key string, v string) {
+		if v != "" {
+			attributes = append(attributes, key+"="+value.Replace(v))
+		}
+	}
+	add("devTime", e.Time.UTC().Format("Jan 02 2006 15:04:05.000 MST"))
+	add("devTimeFormat", "MMM dd yyyy HH:mm:ss.SSS z")
+	add("identHostName", e.Host)
+	add("cat", e.EventID)
+	add("sev", strconv.Itoa(e.numericSeverity()))
+	add("severity", e.Severity)
+	add("name", e.Name)
+	add("msg", e.Message)
+	add("appPath", e.AppPath)
+	add("bundleId", e.BundleID)
+	add("framework", e.Framework)
+	add("electronVersion", e.Version)
+	add("filePath", e.FilePath)
+
+	return fmt.Sprintf("LEEF:1.0|Adversis|asarscan|%s|%s|%s",
+		header.Replace(version), header.Replace(e.EventID), strings.Join(attributes, "\t"))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// siemTestEvent carries every header and extension character CEF and LEEF escape
+var siemTestEvent = siemEvent{
+	Time:      time.Date(2026, time.March, 2, 9, 30, 0, 250_000_000, time.UTC),
+	Host:      "build-mac-07",
+	EventID:   electronscan.RuleFrameworkWeakness,
+	Name:      "Framework|Weakness",
+	Severity:  electronscan.SeverityMedium,
+	Message:   "a=b\\c\nd\te|f",
+	AppPath:   `C:\Program Files\Demo\Demo.exe`,
+	BundleID:  "com.example.demo",
+	Framework: electronscan.FrameworkNWJS,
+	FilePath:  `C:\Program Files\Demo\package.json`,
+}
+
+func TestSiemEventCEF(t *testing.T) {
+	want := `CEF:0|Adversis|asarscan|dev|framework-weakness|Framework\|Weakness|5|` +
+		`rt=1772443800250 dvchost=build-mac-07 cat=framework-weakness msg=a\=b\\c\nd` + "\t" + `e|f ` +
+		`filePath=C:\\Program Files\\Demo\\package.json cs1Label=appPath cs1=C:\\Program Files\\Demo\\Demo.exe ` +
+		`cs2Label=bundleId cs2=com.example.demo cs3Label=framework cs3=nwjs cs5Label=severity cs5=medium`
+	if got := siemTestEvent.format("cef"); got != want {
+		t.Errorf("cef:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSiemEventLEEF(t *testing.T) {
+	want := strings.Join([]string{
+		`LEEF:1.0|Adversis|asarscan|dev|framework-weakness|devTime=Mar 02 2026 09:30:00.250 UTC`,
+		"devTimeFormat=MMM dd yyyy HH:mm:ss.SSS z",
+		"identHostName=build-mac-07",
+		"cat=framework-weakness",
+		"sev=5",
+		"severity=medium",
+		"name=Framework|Weakness",
+		`msg=a=b\c d e|f`,
+		`appPath=C:\Program Files\Demo\Demo.exe`,
+		"bundleId=com.example.demo",
+		"framework=nwjs",
+		`filePath=C:\Program Files\Demo\package.json`,
+	}, "\t")
+	if got := siemTestEvent.format("leef"); got != want {
+		t.Errorf("leef:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestWatchSiemEvent(t *testing.T) {
+	tests := []struct {
+		event    electronscan.WatchEvent
+		id       string
+		severity int
+		message  string
+	}{
+		{
+			electronscan.WatchEvent{Type: electronscan.WatchAlert, Kind: electronscan.WatchKindAsar, Change: electronscan.FileModified},
+			eventUnexpectedChange, syslogError, "asar file modified outside of a verified update",
+		},
+		{
+			electronscan.WatchEvent{Type: electronscan.WatchUpdate, NewVersion: "2.0.0"},
+			eventAppUpdated, syslogNotice, "Updated from (none) to 2.0.0",
+		},
+	}
+	for _, tt := range tests {
+		e := watchSiemEvent(tt.event)
+		if e.EventID != tt.id || e.syslogSeverity() != tt.severity || e.Message != tt.message {
+			t.Errorf("watchSiemEvent(%+v) = %s, severity %d, %q", tt.event, e.EventID, e.syslogSeverity(), e.Message)
+		}
+	}
+}
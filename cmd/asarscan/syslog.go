@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// Syslog severities (RFC 5424 section 6.2.1)
+const (
+	syslogError   = 3
+	syslogWarning = 4
+	syslogNotice  = 5
+	syslogInfo    = 6
+)
+
+// syslogFacility is "daemon"
+const syslogFacility = 3
+
+// localSyslogSockets are where syslog daemons listen on macOS, Linux and BSD
+var localSyslogSockets = []string{"/var/run/syslog", "/dev/log", "/var/run/log"}
+
+// syslogClient sends RFC 5424 messages over UDP, TCP or a unix socket.
+// TCP messages are framed by octet counting, those on stream sockets by a newline (RFC 6587).
+type syslogClient struct {
+	network  string
+	addr     string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// dialSyslog connects to the local syslog daemon when target is "local",
+// otherwise to "udp://host:514", "tcp://host:514" or "unix:///path/to/socket"
+func dialSyslog(target string) (*syslogClient, error) {
+	hostname, _ := os.Hostname()
+	c := &syslogClient{hostname: hostname}
+	if c.hostname == "" {
+		c.hostname = "-"
+	}
+
+	if target == "local" {
+		for _, path := range localSyslogSockets {
+			c.network, c.addr = "unixgram", path
+			if err := c.connect(); err == nil {
+				return c, nil
+			}
+		}
+		return nil, errors.New("no local syslog socket found")
+	}
+
+	scheme, addr, ok := strings.Cut(target, "://")
+	if !ok {
+		return nil, fmt.Errorf("syslog target %q must be local, udp://host:port, tcp://host:port or unix:///path", target)
+	}
+	switch scheme {
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "514")
+		}
+		c.network, c.addr = scheme, addr
+	case "unix":
+		// Daemons listen on datagram sockets; fall back to a stream socket
+		c.network, c.addr = "unixgram", addr
+		if err := c.connect(); err == nil {
+			return c, nil
+		}
+		c.network = "unix"
+	default:
+		return nil, fmt.Errorf("unknown syslog transport %q", scheme)
+	}
+
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect (re)opens the connection
+func (c *syslogClient) connect() error {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	conn, err := net.DialTimeout(c.network, c.addr, 10*time.Second)
+	if err != nil {
+		c.conn = nil
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+// Send writes one message, reconnecting once if the connection was lost
+func (c *syslogClient) Send(severity int, msgID string, message string) error {
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	line := fmt.Sprintf("<%d>1 %s %s asarscan %d %s - %s",
+		syslogFacility*8+severity,
+		time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		c.hostname, os.Getpid(), msgID, message)
+	switch c.network {
+	case "tcp":
+		line = fmt.Sprintf("%d %s", len(line), line)
+	case "unix":
+		line += "\n"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		if _, err := c.conn.Write([]byte(line)); err == nil {
+			return nil
+		}
+	}
+	if err := c.connect(); err != nil {
+		return err
+	}
+	_, err := c.conn.Write([]byte(line))
+	return err
+}
+
+// Close closes the connection
+func (c *syslogClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// syslogSink sends findings and watch events to syslog as CEF, LEEF or JSON messages
+type syslogSink struct {
+	client *syslogClient
+	format string
+	logger *slog.Logger
+}
+
+// newSyslogSink connects to a syslog target; format is "cef", "leef" or "json".
+// Watch events that cannot be sent are reported to logger.
+func newSyslogSink(target string, format string, logger *slog.Logger) (*syslogSink, error) {
+	if !validSiemFormat(format) {
+		return nil, fmt.Errorf("unknown syslog format %q", format)
+	}
+	client, err := dialSyslog(target)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{client: client, format: format, logger: logger}, nil
+}
+
+// Findings sends one message per active finding and returns how many were sent
+func (s *syslogSink) Findings(results []electronscan.AppResult) (int, error) {
+	sent := 0
+	for _, result := range results {
+		if result.Framework == "" {
+			continue
+		}
+		for _, finding := range electronscan.Findings(result) {
+			event := findingSiemEvent(result, finding)
+			if err := s.client.Send(event.syslogSeverity(), "finding", event.format(s.format)); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// Watch sends an event at the syslog severity matching the one in its CEF or
+// LEEF body. The JSON format sends the event as it appears in NDJSON output.
+func (s *syslogSink) Watch(event electronscan.WatchEvent) {
+	siem := watchSiemEvent(event)
+	message := siem.format(s.format)
+	if s.format == "json" {
+		jsonData, err := json.Marshal(event)
+		if err != nil {
+			s.logger.Error("Error encoding watch event", "app", event.App, "error", err)
+			return
+		}
+		message = string(jsonData)
+	}
+
+	if err := s.client.Send(siem.syslogSeverity(), event.Type, message); err != nil {
+		s.logger.Error("Error sending watch event to syslog", "app", event.App, "type", event.Type, "path", event.Path, "error", err)
+	}
+}
+
+// Close closes the connection to syslog
+func (s *syslogSink) Close() error {
+	return s.client.Close()
+}
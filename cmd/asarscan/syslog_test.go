@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// syslogHeader matches the RFC 5424 header up to the message
+var syslogHeader = regexp.MustCompile(`^<(\d+)>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ asarscan \d+ (\S+) - `)
+
+// checkSyslogMessage checks the header of one message and returns its body
+func checkSyslogMessage(t *testing.T, message string, pri int, msgID string) string {
+	t.Helper()
+	m := syslogHeader.FindStringSubmatch(message)
+	if m == nil {
+		t.Fatalf("malformed message %q", message)
+	}
+	if m[1] != strconv.Itoa(pri) || m[2] != msgID {
+		t.Errorf("PRI %s and MSGID %s, want %d and %s", m[1], m[2], pri, msgID)
+	}
+	return message[len(m[0]):]
+}
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	sink, err := newSyslogSink("udp://"+conn.LocalAddr().String(), "cef", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// One datagram per active finding: the suppressed one and the
+	// unrecognized application send nothing
+	result := electronscan.AppResult{
+		Path:       "/Applications/Demo.app",
+		IsElectron: true,
+		Framework:  electronscan.FrameworkElectron,
+		Executable: "/Applications/Demo.app/Contents/MacOS/Demo",
+		Fuses:      map[string]string{"RunAsNode": electronscan.FuseEnabled, "EnableNodeCliInspectArguments": electronscan.FuseEnabled},
+	}
+	result.SuppressedFindings = []electronscan.SuppressedFinding{{Finding: electronscan.Findings(result)[1]}}
+	sent, err := sink.Findings([]electronscan.AppResult{{Path: "/Applications/Notes.app"}, result})
+	if err != nil || sent != 1 {
+		t.Fatalf("sent %d findings, %v; want 1", sent, err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// daemon.err for a high finding
+	body := checkSyslogMessage(t, string(buf[:n]), syslogFacility*8+syslogError, "finding")
+	if !strings.HasPrefix(body, "CEF:0|Adversis|asarscan|") || !strings.Contains(body, electronscan.RuleRunAsNodeEnabled) {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	client, err := dialSyslog("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// Messages may contain newlines, which is why TCP frames by length
+	messages := []string{"first line\nsecond line", "short"}
+	for _, message := range messages {
+		if err := client.Send(syslogWarning, "alert", message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+
+	r := bufio.NewReader(server)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, want := range messages {
+		count, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(count, " "))
+		if err != nil {
+			t.Fatalf("frame length %q: %v", count, err)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			t.Fatal(err)
+		}
+		if body := checkSyslogMessage(t, string(frame), syslogFacility*8+syslogWarning, "alert"); body != want {
+			t.Errorf("body = %q, want %q", body, want)
+		}
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Errorf("trailing data after the frames: %v", err)
+	}
+}
+
+func TestSyslogUnixStream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix sockets")
+	}
+	socket := filepath.Join(t.TempDir(), "log")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	// A stream socket is used when no datagram socket listens at the path
+	client, err := dialSyslog("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if err := client.Send(syslogInfo, "update", "updated"); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(server).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := checkSyslogMessage(t, line, syslogFacility*8+syslogInfo, "update"); body != "updated\n" {
+		t.Errorf("body = %q, want a newline-terminated message", body)
+	}
+}
+
+func TestDialSyslogTargets(t *testing.T) {
+	client, err := dialSyslog("udp://127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	if client.addr != "127.0.0.1:514" {
+		t.Errorf("address = %q, want the default port", client.addr)
+	}
+
+	for target, want := range map[string]string{
+		"127.0.0.1:514":   "must be local",
+		"ftp://127.0.0.1": "unknown syslog transport",
+	} {
+		if _, err := dialSyslog(target); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error = %v, want it to mention %q", target, err, want)
+		}
+	}
+
+	if _, err := newSyslogSink("udp://127.0.0.1", "xml", nil); err == nil || !strings.Contains(err.Error(), "unknown syslog format") {
+		t.Errorf("xml format error = %v", err)
+	}
+}
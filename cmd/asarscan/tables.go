@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// tableHeaders names the columns written by the CSV and Markdown exporters.
+// Every AppResult field has a column named after its JSON key, or columns
+// prefixed with it when it is flattened into several.
+var tableHeaders = []string{
+	"path",
+	"bundle_id",
+	"framework",
+	"framework_evidence",
+	"framework_findings",
+	"is_electron",
+	"electron_version",
+	"app_version",
+	"executable",
+	"code_signature",
+	"resources_dir",
+	"has_asar_file",
+	"asar_integrity_enabled",
+	"asar_integrity_hash",
+	"only_load_from_asar",
+	"fuses",
+	"node_files",
+	"entry_point_source",
+	"main_script",
+	"preload_scripts",
+	"web_preferences",
+	"updater_mechanisms",
+	"updater_feed_url",
+	"updater_provider",
+	"updater_publishers",
+	"updater_signature_verification",
+	"updater_cache",
+	"updater_cache_writable",
+	"updater_issues",
+	"autostart",
+	"autostart_entries",
+	"risk_score",
+	"risk_severity",
+	"risk_factors",
+	"policy_violations",
+	"suppressed_findings",
+	"integrity_error",
+}
+
+// tableRecord flattens an AppResult into one cell per header.
+// Lists are joined with sep so each application stays on one row.
+func tableRecord(result electronscan.AppResult, sep string) []string {
+	var fuses []string
+	for _, name := range electronscan.FuseNames {
+		if state, ok := result.Fuses[name]; ok {
+			fuses = append(fuses, name+"="+state)
+		}
+	}
+
+	var nodeFiles []string
+	for _, module := range result.NodeFiles {
+		pkg := module.Package
+		if module.PackageVersion != "" {
+			pkg += "@" + module.PackageVersion
+		}
+		abi := module.ABI
+		if module.ABIVersion != "" {
+			abi += " v" + module.ABIVersion
+		}
+		var deps []string
+		for _, dep := range module.Dependencies {
+			switch {
+			case dep.Missing:
+				deps = append(deps, dep.Name+" [missing: "+dep.Resolved+"]")
+			case dep.Writable:
+				deps = append(deps, dep.Name+" [writable: "+dep.Resolved+"]")
+			default:
+				deps = append(deps, dep.Name)
+			}
+		}
+		nodeFiles = append(nodeFiles, fmt.Sprintf("%s (package=%s, abi=%s, format=%s, arch=%s, size=%d, sha256=%s, unpacked=%t, writable=%t, dependencies=%s)",
+			module.Path, pkg, abi, module.Format, strings.Join(module.Architectures, "/"),
+			module.Size, module.SHA256, module.Unpacked, module.Writable, strings.Join(deps, ", ")))
+	}
+
+	var entrySource, mainScript string
+	var preloads, webPreferences []string
+	if entry := result.EntryPoint; entry != nil {
+		entrySource = entry.Source
+		mainScript = formatScript(entry.Main)
+		for _, preload := range entry.Preloads {
+			preloads = append(preloads, formatScript(preload))
+		}
+		for _, pref := range entry.WebPreferences {
+			webPreferences = append(webPreferences, fmt.Sprintf("%s=%s (%s)", pref.Setting, pref.Value, pref.Script))
+		}
+	}
+
+	var autoStart []string
+	for _, entry := range result.AutoStartEntries {
+		autoStart = append(autoStart, autoStartSummary(entry))
+	}
+
+	var updater electronscan.UpdaterInfo
+	if result.Updater != nil {
+		updater = *result.Updater
+	}
+
+	var riskScore, riskSeverity string
+	var riskFactors []string
+	if risk := result.Risk; risk != nil {
+		riskScore = strconv.Itoa(risk.Score)
+		riskSeverity = risk.Severity
+		for _, factor := range risk.Factors {
+			riskFactors = append(riskFactors, fmt.Sprintf("%s +%d (%d findings)", factor.RuleID, factor.Points, factor.Findings))
+		}
+	}
+
+	var violations []string
+	for _, violation := range result.PolicyViolations {
+		violations = append(violations, violation.Requirement+": "+violation.Message)
+	}
+
+	var suppressed []string
+	for _, finding := range result.SuppressedFindings {
+		suppressed = append(suppressed, fmt.Sprintf("%s %s (%s, expires %s)", finding.RuleID, finding.Path, finding.Justification, finding.Expires))
+	}
+
+	return []string{
+		result.Path,
+		result.BundleID,
+		result.Framework,
+		result.FrameworkEvidence,
+		strings.Join(result.FrameworkFindings, sep),
+		strconv.FormatBool(result.IsElectron),
+		result.Version,
+		result.AppVersion,
+		result.Executable,
+		result.Signature,
+		result.ResourcesDir,
+		strconv.FormatBool(result.HasAsarFile),
+		strconv.FormatBool(result.AsarIntegrity),
+		result.IntegrityHash,
+		strconv.FormatBool(result.OnlyLoadFromAsar),
+		strings.Join(fuses, sep),
+		strings.Join(nodeFiles, sep),
+		entrySource,
+		mainScript,
+		strings.Join(preloads, sep),
+		strings.Join(webPreferences, sep),
+		strings.Join(updater.Mechanisms, sep),
+		updater.FeedURL,
+		updater.Provider,
+		strings.Join(updater.PublisherNames, sep),
+		updater.SignatureVerification,
+		updater.CachePath,
+		strconv.FormatBool(updater.CacheWritable),
+		strings.Join(updater.Issues, sep),
+		strconv.FormatBool(result.AutoStart),
+		strings.Join(autoStart, sep),
+		riskScore,
+		riskSeverity,
+		strings.Join(riskFactors, sep),
+		strings.Join(violations, sep),
+		strings.Join(suppressed, sep),
+		result.IntegrityError,
+	}
+}
+
+// outputResultsCsv outputs one row per Chromium-based application
+func outputResultsCsv(w io.Writer, results []electronscan.AppResult) {
+	writer := csv.NewWriter(w)
+	writer.Write(tableHeaders)
+	for _, result := range results {
+		if result.Framework == "" {
+			continue
+		}
+		writer.Write(tableRecord(result, "; "))
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+	}
+}
+
+// outputResultsMarkdown outputs a GitHub-flavored Markdown table of Chromium-based applications
+func outputResultsMarkdown(w io.Writer, results []electronscan.AppResult) {
+	escape := func(cell string) string {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		return strings.ReplaceAll(cell, "\n", " ")
+	}
+
+	fmt.Fprintf(w, "| %s |\n", strings.Join(tableHeaders, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(tableHeaders)))
+	for _, result := range results {
+		if result.Framework == "" {
+			continue
+		}
+
+		record := tableRecord(result, "<br>")
+		for i, cell := range record {
+			record[i] = escape(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(record, " | "))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// TestTableHeadersCoverAppResult keeps the table exports in step with the
+// JSON report: every AppResult field needs a column named after its JSON key,
+// or columns prefixed with it
+func TestTableHeadersCoverAppResult(t *testing.T) {
+	fields := reflect.TypeFor[electronscan.AppResult]()
+	for i := range fields.NumField() {
+		key, _, _ := strings.Cut(fields.Field(i).Tag.Get("json"), ",")
+		if !slices.ContainsFunc(tableHeaders, func(header string) bool {
+			return header == key || strings.HasPrefix(header, key+"_")
+		}) {
+			t.Errorf("AppResult.%s (%q) has no table column", fields.Field(i).Name, key)
+		}
+	}
+}
+
+func TestOutputResultsCsv(t *testing.T) {
+	results := []electronscan.AppResult{
+		{Path: `C:\Program Files\Notes\notes.exe`},
+		{
+			Path:       `C:\Program Files\Demo\Demo.exe`,
+			IsElectron: true,
+			Framework:  electronscan.FrameworkElectron,
+			AppVersion: "1.2.3",
+			Signature:  electronscan.SignatureUnsigned,
+			Risk: &electronscan.RiskScore{Score: 75, Severity: electronscan.SeverityHigh, Factors: []electronscan.RiskFactor{
+				{RuleID: "run-as-node-enabled", Points: 20, Findings: 1},
+			}},
+			PolicyViolations: []electronscan.PolicyViolation{
+				{Requirement: "require_signed_executable", Message: "Demo.exe is not code signed"},
+				{Requirement: "max_risk_score", Message: "Risk score 75 exceeds the allowed 40"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	outputResultsCsv(&buf, results)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d rows, want the header and one application", len(records))
+	}
+	row := make(map[string]string)
+	for i, header := range records[0] {
+		row[header] = records[1][i]
+	}
+
+	want := map[string]string{
+		"app_version":       "1.2.3",
+		"code_signature":    electronscan.SignatureUnsigned,
+		"risk_score":        "75",
+		"risk_severity":     electronscan.SeverityHigh,
+		"risk_factors":      "run-as-node-enabled +20 (1 findings)",
+		"policy_violations": "require_signed_executable: Demo.exe is not code signed; max_risk_score: Risk score 75 exceeds the allowed 40",
+	}
+	for header, value := range want {
+		if row[header] != value {
+			t.Errorf("%s = %q, want %q", header, row[header], value)
+		}
+	}
+}
+
+func TestOutputResultsCsvQuotesCells(t *testing.T) {
+	path := "/Applications/Demo, \"Beta\"\nEdition.app"
+	var buf bytes.Buffer
+	outputResultsCsv(&buf, []electronscan.AppResult{{Path: path, Framework: electronscan.FrameworkElectron}})
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][0] != path {
+		t.Errorf("records = %q, want the path %q round-tripped", records, path)
+	}
+
+	buf.Reset()
+	outputResultsCsv(&buf, nil)
+	if got, want := buf.String(), strings.Join(tableHeaders, ",")+"\n"; got != want {
+		t.Errorf("empty scan = %q, want only the header", got)
+	}
+}
+
+func TestTableRecordFlattensLists(t *testing.T) {
+	record := tableRecord(electronscan.AppResult{
+		Path:      "/Applications/Demo.app",
+		Framework: electronscan.FrameworkElectron,
+		Fuses: map[string]string{
+			"OnlyLoadAppFromAsar": electronscan.FuseEnabled,
+			"RunAsNode":           electronscan.FuseDisabled,
+		},
+		NodeFiles: []electronscan.NativeModule{{
+			Path:           "/Applications/Demo.app/Contents/Resources/app.asar.unpacked/keytar.node",
+			Package:        "keytar",
+			PackageVersion: "7.9.0",
+			ABI:            "N-API",
+			ABIVersion:     "8",
+			Format:         "Mach-O",
+			Architectures:  []string{"x86_64", "arm64"},
+			Unpacked:       true,
+			Dependencies: []electronscan.LibraryDependency{
+				{Name: "/usr/lib/libc++.1.dylib"},
+				{Name: "@rpath/libsecret.dylib", Missing: true, Resolved: "/Applications/Demo.app/Contents/Frameworks/libsecret.dylib"},
+			},
+		}},
+		EntryPoint: &electronscan.EntryPoint{
+			Source:   "asar",
+			Main:     electronscan.ScriptInfo{Path: "main.js", Location: "asar"},
+			Preloads: []electronscan.ScriptInfo{{Path: "a.js", Location: "asar"}, {Path: "b.js", Location: "asar"}},
+		},
+	}, "; ")
+	row := make(map[string]string)
+	for i, header := range tableHeaders {
+		row[header] = record[i]
+	}
+
+	want := map[string]string{
+		// Fuses follow the fuse wire order, not the map order
+		"fuses":                  "RunAsNode=disabled; OnlyLoadAppFromAsar=enabled",
+		"node_files":             "/Applications/Demo.app/Contents/Resources/app.asar.unpacked/keytar.node (package=keytar@7.9.0, abi=N-API v8, format=Mach-O, arch=x86_64/arm64, size=0, sha256=, unpacked=true, writable=false, dependencies=/usr/lib/libc++.1.dylib, @rpath/libsecret.dylib [missing: /Applications/Demo.app/Contents/Frameworks/libsecret.dylib])",
+		"entry_point_source":     "asar",
+		"preload_scripts":        "a.js [asar]; b.js [asar]",
+		"updater_mechanisms":     "",
+		"updater_cache_writable": "false",
+		"risk_score":             "",
+	}
+	for header, value := range want {
+		if row[header] != value {
+			t.Errorf("%s = %q, want %q", header, row[header], value)
+		}
+	}
+}
+
+func TestOutputResultsMarkdownEscapesCells(t *testing.T) {
+	var buf bytes.Buffer
+	outputResultsMarkdown(&buf, []electronscan.AppResult{{
+		Path:              "/Applications/Pipe|Name.app",
+		Framework:         electronscan.FrameworkNWJS,
+		FrameworkFindings: []string{"first", "second\nline"},
+	}})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header, separator and one row:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[2], `Pipe\|Name.app`) || !strings.Contains(lines[2], "first<br>second line") {
+		t.Errorf("row = %s", lines[2])
+	}
+	if cells := strings.Count(lines[0], " | "); strings.Count(lines[2], " | ") != cells {
+		t.Errorf("row has %d separators, header %d", strings.Count(lines[2], " | "), cells)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// watchSink receives the events produced by watch mode
+type watchSink interface {
+	Watch(event electronscan.WatchEvent)
+}
+
+// runWatch implements "asarscan watch": keep polling the discovered applications
+// and report code changes that were not part of a verified update
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "Enable debug logging on stderr")
+	configPath := fs.String("config", electronscan.DefaultConfigPath(), "Read search roots and exclusions from this JSON or YAML file")
+	interval := fs.Duration("interval", time.Minute, "How often to check watched files")
+	rescan := fs.Duration("rescan", time.Hour, "How often to look for newly installed applications")
+	outputPath := fs.String("o", "", "Append NDJSON events to this file instead of stdout")
+	syslogAddr := fs.String("syslog", "", "Send events to syslog instead: \"local\", \"udp://host:514\", \"tcp://host:514\" or \"unix:///path\"")
+	syslogFormat := fs.String("syslog-format", "json", "Syslog message format: json, cef or leef")
+	fs.Parse(args)
+
+	config := loadConfig(*configPath)
+	logger := newLogger(*verbose)
+	checkSupportedOS("", false)
+
+	var sink watchSink
+	if *syslogAddr != "" {
+		syslog, err := newSyslogSink(*syslogAddr, *syslogFormat, logger)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to syslog: %v\n", err)
+			os.Exit(1)
+		}
+		defer syslog.Close()
+		sink = syslog
+	} else {
+		var out io.Writer = os.Stdout
+		if *outputPath != "" {
+			f, err := os.OpenFile(*outputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening output file: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+		sink = newNdjsonWriter(out)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	scanner := electronscan.New(electronscan.Options{
+		Roots:   config.SearchRoots,
+		Logger:  logger,
+		Exclude: config.Exclude,
+	})
+	watcher := scanner.NewWatcher()
+	apps := discoverElectronApps(scanner, logger)
+	lastDiscovery := time.Now()
+
+	// The first poll records the starting state
+	watcher.Poll(apps)
+	logger.Info("Watching applications", "count", len(apps), "interval", *interval)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopping watch")
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(lastDiscovery) >= *rescan {
+			apps = discoverElectronApps(scanner, logger)
+			lastDiscovery = time.Now()
+		}
+
+		for _, event := range watcher.Poll(apps) {
+			sink.Watch(event)
+		}
+	}
+}
+
+// discoverElectronApps returns the Electron applications installed on this system,
+// leaving out those excluded by the config
+func discoverElectronApps(scanner *electronscan.Scanner, logger *slog.Logger) []string {
+	candidates, err := scanner.Discover()
+	if err != nil {
+		logger.Error("Error scanning for applications", "error", err)
+		return nil
+	}
+
+	var apps []string
+	for _, app := range candidates {
+		if isElectron, _, _ := scanner.IsElectronApp(app); isElectron {
+			apps = append(apps, app)
+		}
+	}
+	logger.Debug("Discovered Electron applications", "count", len(apps))
+	return apps
+}
@@ -0,0 +1,188 @@
+package electronscan
+
+import (
+	"cmp"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// writableRules are the findings that mean an install can be modified by a user
+var writableRules = []string{
+	RuleWritableNativeModule,
+	RuleHijackableDependency,
+	RuleWritableEntryScript,
+	RuleWritableUpdateCache,
+}
+
+// Fleet is the merged view of reports from many hosts
+type Fleet struct {
+	Reports         int        `json:"reports"`
+	Hosts           int        `json:"hosts"`
+	IncompleteHosts []string   `json:"incomplete_hosts,omitempty"`
+	Apps            []FleetApp `json:"apps"`
+}
+
+// FleetApp summarizes one application across every host it is installed on
+type FleetApp struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Framework      string            `json:"framework"`
+	Hosts          int               `json:"hosts"`
+	Versions       []FleetVersion    `json:"versions"`
+	FuseConfigs    []FleetFuseConfig `json:"fuse_configurations,omitempty"`
+	Severities     map[string]int    `json:"severities,omitempty"`
+	TamperedHosts  []FleetInstall    `json:"tampered_hosts,omitempty"`
+	WritableHosts  []FleetInstall    `json:"writable_hosts,omitempty"`
+	PolicyFailures int               `json:"policy_failures,omitempty"`
+	installs       map[string]AppResult
+}
+
+// FleetVersion counts the hosts running one application and Electron version
+type FleetVersion struct {
+	AppVersion      string `json:"app_version,omitempty"`
+	ElectronVersion string `json:"electron_version,omitempty"`
+	Hosts           int    `json:"hosts"`
+}
+
+// FleetFuseConfig counts the hosts with one fuse configuration of an application version
+type FleetFuseConfig struct {
+	AppVersion string            `json:"app_version,omitempty"`
+	Fuses      map[string]string `json:"fuses"`
+	Hosts      int               `json:"hosts"`
+}
+
+// FleetInstall points at an install on one host and why it was listed
+type FleetInstall struct {
+	Host  string   `json:"host"`
+	Path  string   `json:"path"`
+	Rules []string `json:"rules,omitempty"`
+}
+
+// AppID identifies an application across hosts: its bundle identifier on macOS,
+// otherwise the name of the bundle or executable
+func AppID(result AppResult) string {
+	if result.BundleID != "" {
+		return result.BundleID
+	}
+	return strings.ToLower(filepath.Base(strings.ReplaceAll(result.Path, `\`, "/")))
+}
+
+// Aggregate merges reports from many hosts. A host is identified by
+// Metadata.Host; when a host sent several reports only the latest counts.
+// Applications that do not embed Chromium are left out.
+func Aggregate(reports []Report) Fleet {
+	latest := make(map[string]Report)
+	for _, report := range reports {
+		host := report.Metadata.Host
+		if previous, ok := latest[host]; !ok || report.Metadata.EndTime.After(previous.Metadata.EndTime) {
+			latest[host] = report
+		}
+	}
+
+	fleet := Fleet{Reports: len(reports), Hosts: len(latest), Apps: []FleetApp{}}
+	apps := make(map[string]*FleetApp)
+	for _, host := range slices.Sorted(maps.Keys(latest)) {
+		report := latest[host]
+		if len(report.Metadata.UnreadableDirs) > 0 {
+			fleet.IncompleteHosts = append(fleet.IncompleteHosts, host)
+		}
+
+		for _, result := range report.Results {
+			if result.Framework == "" {
+				continue
+			}
+			id := AppID(result)
+			app := apps[id]
+			if app == nil {
+				app = &FleetApp{
+					ID:        id,
+					Name:      filepath.Base(strings.ReplaceAll(result.Path, `\`, "/")),
+					Framework: result.Framework,
+					installs:  make(map[string]AppResult),
+				}
+				apps[id] = app
+			}
+			// Several copies on one host count once; the first one found wins
+			if _, ok := app.installs[host]; !ok {
+				app.installs[host] = result
+			}
+		}
+	}
+
+	for _, app := range apps {
+		app.summarize()
+		fleet.Apps = append(fleet.Apps, *app)
+	}
+	slices.SortFunc(fleet.Apps, func(a, b FleetApp) int {
+		return cmp.Or(cmp.Compare(b.Hosts, a.Hosts), cmp.Compare(a.ID, b.ID))
+	})
+	return fleet
+}
+
+// summarize computes the statistics of an application from its installs
+func (app *FleetApp) summarize() {
+	versions := make(map[FleetVersion]int)
+	type fuseKey struct{ appVersion, fuses string }
+	fuseConfigs := make(map[fuseKey]*FleetFuseConfig)
+	app.Severities = make(map[string]int)
+
+	for _, host := range slices.Sorted(maps.Keys(app.installs)) {
+		result := app.installs[host]
+		app.Hosts++
+		versions[FleetVersion{AppVersion: result.AppVersion, ElectronVersion: result.Version}]++
+
+		if len(result.Fuses) > 0 {
+			key := fuseKey{result.AppVersion, fuseSignature(result.Fuses)}
+			if fuseConfigs[key] == nil {
+				fuseConfigs[key] = &FleetFuseConfig{AppVersion: result.AppVersion, Fuses: result.Fuses}
+			}
+			fuseConfigs[key].Hosts++
+		}
+
+		if result.Risk != nil {
+			app.Severities[result.Risk.Severity]++
+		}
+		if len(result.PolicyViolations) > 0 {
+			app.PolicyFailures++
+		}
+
+		if result.IntegrityHash == IntegrityHashMismatch {
+			app.TamperedHosts = append(app.TamperedHosts, FleetInstall{Host: host, Path: result.Path, Rules: []string{RuleAsarIntegrityMismatch}})
+		}
+		var rules []string
+		for _, finding := range Findings(result) {
+			if slices.Contains(writableRules, finding.RuleID) && !slices.Contains(rules, finding.RuleID) {
+				rules = append(rules, finding.RuleID)
+			}
+		}
+		if len(rules) > 0 {
+			app.WritableHosts = append(app.WritableHosts, FleetInstall{Host: host, Path: result.Path, Rules: rules})
+		}
+	}
+
+	for version, hosts := range versions {
+		version.Hosts = hosts
+		app.Versions = append(app.Versions, version)
+	}
+	slices.SortFunc(app.Versions, func(a, b FleetVersion) int {
+		return cmp.Or(cmp.Compare(b.Hosts, a.Hosts), -compareVersions(a.AppVersion, b.AppVersion), -compareVersions(a.ElectronVersion, b.ElectronVersion))
+	})
+
+	for _, config := range fuseConfigs {
+		app.FuseConfigs = append(app.FuseConfigs, *config)
+	}
+	slices.SortFunc(app.FuseConfigs, func(a, b FleetFuseConfig) int {
+		return cmp.Or(-compareVersions(a.AppVersion, b.AppVersion), cmp.Compare(b.Hosts, a.Hosts), cmp.Compare(fuseSignature(a.Fuses), fuseSignature(b.Fuses)))
+	})
+}
+
+// fuseSignature is a stable string form of a fuse configuration
+func fuseSignature(fuses map[string]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(fuses)) {
+		b.WriteString(name + "=" + fuses[name] + ";")
+	}
+	return b.String()
+}
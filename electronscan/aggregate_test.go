@@ -0,0 +1,112 @@
+package electronscan_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+func TestAggregate(t *testing.T) {
+	t0 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	report := func(host string, end time.Time, unreadable []string, results ...electronscan.AppResult) electronscan.Report {
+		return electronscan.Report{
+			Metadata: electronscan.ScanMetadata{Host: host, EndTime: end, UnreadableDirs: unreadable},
+			Results:  results,
+		}
+	}
+	demo := func(path, appVersion string, fuses map[string]string) electronscan.AppResult {
+		return electronscan.AppResult{
+			Path:       path,
+			BundleID:   "com.example.demo",
+			IsElectron: true,
+			Framework:  electronscan.FrameworkElectron,
+			AppVersion: appVersion,
+			Version:    "30.1.0",
+			Fuses:      fuses,
+			Risk:       &electronscan.RiskScore{Severity: electronscan.SeverityHigh},
+		}
+	}
+	hardened := map[string]string{"RunAsNode": electronscan.FuseDisabled}
+	defaults := map[string]string{"RunAsNode": electronscan.FuseEnabled}
+
+	tampered := electronscan.AppResult{
+		Path:          `C:\Program Files\Chat\Chat.exe`,
+		IsElectron:    true,
+		Framework:     electronscan.FrameworkElectron,
+		AppVersion:    "3.0.0",
+		IntegrityHash: electronscan.IntegrityHashMismatch,
+		NodeFiles: []electronscan.NativeModule{{
+			Path:         `C:\Program Files\Chat\resources\app.asar.unpacked\a.node`,
+			Writable:     true,
+			Dependencies: []electronscan.LibraryDependency{{Name: "a.dll", Missing: true}},
+		}},
+		PolicyViolations: []electronscan.PolicyViolation{{Requirement: "require_asar_integrity"}},
+	}
+
+	fleet := electronscan.Aggregate([]electronscan.Report{
+		// alice's newer report replaces the older one, whatever the order they arrive in
+		report("alice", t0.Add(time.Hour), nil, demo("/Applications/Demo.app", "2.0.0", hardened)),
+		report("alice", t0, nil, demo("/Applications/Demo.app", "1.0.0", defaults)),
+		// The same bundle ID under another name is the same application;
+		// a second copy on one host counts once
+		report("bob", t0, nil,
+			demo("/Users/bob/Applications/Demo (old).app", "1.0.0", defaults),
+			demo("/Applications/Demo.app", "2.0.0", hardened),
+			electronscan.AppResult{Path: "/Applications/Notes.app"},
+		),
+		report("carol", t0, []string{`C:\Users\dave`},
+			tampered,
+			electronscan.AppResult{Path: `C:\Users\carol\AppData\Local\Programs\chat\CHAT.EXE`, Framework: electronscan.FrameworkElectron, AppVersion: "3.1.0"},
+		),
+	})
+
+	if fleet.Reports != 4 || fleet.Hosts != 3 {
+		t.Errorf("reports = %d, hosts = %d, want 4 from 3 hosts", fleet.Reports, fleet.Hosts)
+	}
+	if !slices.Equal(fleet.IncompleteHosts, []string{"carol"}) {
+		t.Errorf("incomplete hosts = %q", fleet.IncompleteHosts)
+	}
+
+	var ids []string
+	for _, app := range fleet.Apps {
+		ids = append(ids, app.ID)
+	}
+	// Most widely installed first; executables match by name without case
+	if want := []string{"com.example.demo", "chat.exe"}; !slices.Equal(ids, want) {
+		t.Fatalf("apps = %q, want %q", ids, want)
+	}
+
+	demoApp := fleet.Apps[0]
+	if demoApp.Hosts != 2 || demoApp.Severities[electronscan.SeverityHigh] != 2 {
+		t.Errorf("demo on %d hosts with severities %v", demoApp.Hosts, demoApp.Severities)
+	}
+	// Versions on as many hosts list the newest first
+	wantVersions := []electronscan.FleetVersion{{AppVersion: "2.0.0", ElectronVersion: "30.1.0", Hosts: 1}, {AppVersion: "1.0.0", ElectronVersion: "30.1.0", Hosts: 1}}
+	if !slices.Equal(demoApp.Versions, wantVersions) {
+		t.Errorf("versions = %+v, want %+v", demoApp.Versions, wantVersions)
+	}
+	if len(demoApp.FuseConfigs) != 2 || demoApp.FuseConfigs[0].AppVersion != "2.0.0" || demoApp.FuseConfigs[0].Fuses["RunAsNode"] != electronscan.FuseDisabled {
+		t.Errorf("fuse configurations = %+v", demoApp.FuseConfigs)
+	}
+
+	chat := fleet.Apps[1]
+	if chat.Hosts != 1 || chat.PolicyFailures != 1 {
+		t.Errorf("chat on %d hosts with %d policy failures, want the first copy on carol only", chat.Hosts, chat.PolicyFailures)
+	}
+	if len(chat.TamperedHosts) != 1 || chat.TamperedHosts[0].Host != "carol" {
+		t.Errorf("tampered hosts = %+v", chat.TamperedHosts)
+	}
+	wantRules := []string{electronscan.RuleWritableNativeModule, electronscan.RuleHijackableDependency}
+	if len(chat.WritableHosts) != 1 || !slices.Equal(chat.WritableHosts[0].Rules, wantRules) {
+		t.Errorf("writable hosts = %+v, want one with %q", chat.WritableHosts, wantRules)
+	}
+}
+
+func TestAggregateNoReports(t *testing.T) {
+	fleet := electronscan.Aggregate(nil)
+	if fleet.Hosts != 0 || fleet.Apps == nil || len(fleet.Apps) != 0 {
+		t.Errorf("fleet = %+v, want an empty app list", fleet)
+	}
+}
@@ -0,0 +1,201 @@
+package electronscan
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AsarEntry is a file or directory node in an ASAR archive header
+type AsarEntry struct {
+	Files      map[string]*AsarEntry `json:"files,omitempty"`
+	Size       int64                 `json:"size,omitempty"`
+	Offset     string                `json:"offset,omitempty"`
+	Unpacked   bool                  `json:"unpacked,omitempty"`
+	Executable bool                  `json:"executable,omitempty"`
+	Link       string                `json:"link,omitempty"`
+}
+
+// AsarArchive is a parsed ASAR archive header
+type AsarArchive struct {
+	Path       string
+	Header     *AsarEntry
+	HeaderJSON []byte
+	dataOffset int64
+	scanner    *Scanner
+}
+
+// ReadAsarArchive reads and parses the header of an ASAR archive.
+// Only the header is read; file contents stay on disk.
+func (s *Scanner) ReadAsarArchive(path string) (*AsarArchive, error) {
+	f, err := s.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// The archive starts with two pickles: the first holds the size of the
+	// header pickle, the second holds the header JSON string itself.
+	prefix := make([]byte, 16)
+	if _, err := io.ReadFull(f, prefix); err != nil {
+		return nil, fmt.Errorf("error reading ASAR header: %v", err)
+	}
+
+	headerPickleSize := binary.LittleEndian.Uint32(prefix[4:8])
+	headerStringSize := binary.LittleEndian.Uint32(prefix[12:16])
+	if headerStringSize == 0 || headerStringSize > headerPickleSize {
+		return nil, fmt.Errorf("invalid ASAR header size: %d", headerStringSize)
+	}
+	// Check the sizes against the file before allocating, a crafted header can claim 4 GiB
+	if 8+int64(headerPickleSize) > info.Size() {
+		return nil, fmt.Errorf("ASAR header size %d exceeds file size %d", headerPickleSize, info.Size())
+	}
+
+	headerJSON := make([]byte, headerStringSize)
+	if _, err := io.ReadFull(f, headerJSON); err != nil {
+		return nil, fmt.Errorf("error reading ASAR header: %v", err)
+	}
+
+	var header AsarEntry
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("error parsing ASAR header: %v", err)
+	}
+	if err := validateAsarEntry(&header, ""); err != nil {
+		return nil, fmt.Errorf("malformed ASAR header: %v", err)
+	}
+
+	return &AsarArchive{
+		Path:       path,
+		Header:     &header,
+		HeaderJSON: headerJSON,
+		dataOffset: 8 + int64(headerPickleSize),
+		scanner:    s,
+	}, nil
+}
+
+// validateAsarEntry rejects names and links that would resolve outside the
+// archive, or outside app.asar.unpacked once joined to it
+func validateAsarEntry(entry *AsarEntry, dir string) error {
+	if entry.Link != "" {
+		if _, ok := cleanAsarPath(entry.Link); !ok {
+			return fmt.Errorf("%s links outside the archive to %q", dir, entry.Link)
+		}
+	}
+	for name, child := range entry.Files {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid entry name %q in %q", name, dir)
+		}
+		if child == nil {
+			return fmt.Errorf("empty entry %q in %q", name, dir)
+		}
+		if err := validateAsarEntry(child, path.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanAsarPath cleans a slash-separated path inside the archive, reporting
+// false if it is absolute or climbs out with ".."
+func cleanAsarPath(name string) (string, bool) {
+	// Reject Windows drive paths too, whatever the scanning OS
+	if strings.HasPrefix(name, "/") || strings.Contains(name, `\`) || (len(name) >= 2 && name[1] == ':') {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", false
+		}
+	}
+	return path.Clean(name), true
+}
+
+// Lookup returns the entry for a slash-separated path inside the archive, or
+// nil if there is none or the path is absolute or contains ".."
+func (a *AsarArchive) Lookup(name string) *AsarEntry {
+	name, ok := cleanAsarPath(name)
+	if !ok {
+		return nil
+	}
+	entry := a.Header
+	for _, part := range strings.Split(name, "/") {
+		if part == "." {
+			continue
+		}
+		if entry.Files == nil {
+			return nil
+		}
+		entry = entry.Files[part]
+		if entry == nil {
+			return nil
+		}
+	}
+	return entry
+}
+
+// maxAsarLinks bounds the chain of symlinks followed inside an archive
+const maxAsarLinks = 40
+
+// ReadFile returns the contents of a file stored inside the archive.
+// Unpacked files are read from the app.asar.unpacked directory.
+func (a *AsarArchive) ReadFile(name string) ([]byte, error) {
+	entry := a.Lookup(name)
+	visited := make(map[string]bool)
+	for entry != nil && entry.Link != "" {
+		if visited[name] || len(visited) >= maxAsarLinks {
+			return nil, fmt.Errorf("%s: too many links", name)
+		}
+		visited[name] = true
+		name = entry.Link
+		entry = a.Lookup(name)
+	}
+	if entry == nil || entry.Files != nil {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+
+	if entry.Unpacked {
+		return a.scanner.readFile(a.UnpackedPath(name))
+	}
+
+	offset, err := strconv.ParseInt(entry.Offset, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid offset for %s: %v", name, err)
+	}
+
+	f, size, err := a.scanner.openReaderAt(a.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// A crafted header can give any size and offset; only allocate what the file holds
+	if entry.Size < 0 || offset < 0 || a.dataOffset+offset > size || entry.Size > size-a.dataOffset-offset {
+		return nil, fmt.Errorf("%s lies outside the archive (offset %d, size %d)", name, offset, entry.Size)
+	}
+
+	content := make([]byte, entry.Size)
+	if _, err := f.ReadAt(content, a.dataOffset+offset); err != nil {
+		return nil, fmt.Errorf("error reading %s from archive: %v", name, err)
+	}
+	return content, nil
+}
+
+// UnpackedPath returns the on-disk location of a file stored in
+// app.asar.unpacked, or "" if the path is absolute or contains ".."
+func (a *AsarArchive) UnpackedPath(name string) string {
+	name, ok := cleanAsarPath(name)
+	if !ok {
+		return ""
+	}
+	return filepath.Join(a.Path+".unpacked", filepath.FromSlash(name))
+}
@@ -0,0 +1,349 @@
+package electronscan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AppResult contains the result of checking an application
+type AppResult struct {
+	Path               string              `json:"path"`
+	BundleID           string              `json:"bundle_id,omitempty"`
+	IsElectron         bool                `json:"is_electron"`
+	Framework          string              `json:"framework,omitempty"`
+	FrameworkEvidence  string              `json:"framework_evidence,omitempty"`
+	FrameworkFindings  []string            `json:"framework_findings,omitempty"`
+	Version            string              `json:"electron_version,omitempty"`
+	AppVersion         string              `json:"app_version,omitempty"`
+	Executable         string              `json:"executable,omitempty"`
+	ResourcesDir       string              `json:"resources_dir,omitempty"`
+	HasAsarFile        bool                `json:"has_asar_file"`
+	AsarIntegrity      bool                `json:"asar_integrity_enabled"`
+	OnlyLoadFromAsar   bool                `json:"only_load_from_asar"`
+	Fuses              map[string]string   `json:"fuses,omitempty"`
+	NodeFiles          []NativeModule      `json:"node_files,omitempty"`
+	EntryPoint         *EntryPoint         `json:"entry_point,omitempty"`
+	Updater            *UpdaterInfo        `json:"updater,omitempty"`
+	AutoStart          bool                `json:"autostart"`
+	AutoStartEntries   []AutoStartEntry    `json:"autostart_entries,omitempty"`
+	IntegrityHash      string              `json:"asar_integrity_hash,omitempty"`
+	Signature          string              `json:"code_signature,omitempty"`
+	Risk               *RiskScore          `json:"risk,omitempty"`
+	PolicyViolations   []PolicyViolation   `json:"policy_violations,omitempty"`
+	SuppressedFindings []SuppressedFinding `json:"suppressed_findings,omitempty"`
+	IntegrityError     string              `json:"integrity_error,omitempty"`
+}
+
+// Outcomes of comparing the embedded ASAR integrity hash with app.asar
+const (
+	IntegrityHashMatch    = "match"
+	IntegrityHashMismatch = "mismatch"
+)
+
+var (
+	// The Info.plist entry for app.asar, e.g.
+	// <key>Resources/app.asar</key><dict><key>algorithm</key><string>SHA256</string><key>hash</key><string>...</string></dict>
+	plistIntegrityHashRegex = regexp.MustCompile(`(?s)<key>Resources/app\.asar</key>\s*<dict>.*?<key>hash</key>\s*<string>([0-9a-fA-F]{64})</string>`)
+
+	// The INTEGRITY resource of Windows executables, e.g.
+	// [{"file":"resources\\app.asar","alg":"sha256","value":"..."}]
+	resourceIntegrityHashRegex = regexp.MustCompile(`"file"\s*:\s*"resources\\\\app\.asar"\s*,\s*"alg"\s*:\s*"sha256"\s*,\s*"value"\s*:\s*"([0-9a-fA-F]{64})"`)
+)
+
+// CheckAsarIntegrityForApp checks if ASAR integrity is enabled for a specific app
+func (s *Scanner) CheckAsarIntegrityForApp(appPath string) AppResult {
+	result := AppResult{
+		Path:     appPath,
+		BundleID: s.GetBundleIdentifier(appPath),
+	}
+
+	// Check if it's an Electron app
+	isElectron, version, err := s.IsElectronApp(appPath)
+	if err != nil {
+		result.IntegrityError = err.Error()
+		return result
+	}
+	result.IsElectron = isElectron
+	result.Version = version
+
+	if !isElectron {
+		s.logger.Debug("App is not an Electron app", "app", appPath)
+
+		// Other frameworks embedding Chromium are just as injectable
+		result.Framework, result.FrameworkEvidence = s.DetectFramework(appPath)
+		if result.Framework != "" {
+			result.FrameworkFindings = s.CheckFramework(appPath, result.Framework, result.FrameworkEvidence)
+		}
+		return result
+	}
+	result.Framework = ElectronFrameworkVariant(version)
+	result.Executable = s.GetExecutablePath(appPath)
+
+	// The Electron binary carries the full version string and the fuse wire
+	electronBinary, err := s.readFile(s.ElectronBinaryPath(appPath))
+	if err != nil {
+		s.logger.Debug("Could not read Electron binary", "app", appPath, "error", err)
+	} else if result.Framework == FrameworkElectron {
+		result.Framework = electronBinaryVariant(electronBinary)
+	}
+	result.ResourcesDir = s.GetResourcesPath(appPath)
+	result.Signature = s.CodeSignatureStatus(result.Executable)
+
+	// Resolve the main script and preloads, which may live outside the asar
+	result.EntryPoint = s.ResolveEntryPoint(appPath)
+	result.AppVersion = s.appVersion(appPath)
+	result.Updater = s.AnalyzeUpdater(appPath)
+
+	// The fuse wire is the authoritative source for RunAsNode, OnlyLoadAppFromAsar and friends
+	if electronBinary != nil {
+		if fuses, err := parseFuseWire(electronBinary); err != nil {
+			s.logger.Debug("Could not read fuses", "app", appPath, "error", err)
+		} else {
+			result.Fuses = fuses
+		}
+	}
+
+	// Check if it has app.asar file
+	result.HasAsarFile = s.HasAsarFile(appPath)
+	if !result.HasAsarFile {
+		s.logger.Debug("App has no app.asar file", "app", appPath)
+		return result
+	}
+
+	// Check for ASAR integrity and OnlyLoadFromAsar
+	switch s.goos {
+	case "darwin":
+		hasIntegrity, onlyLoadFromAsar, err := s.checkAsarIntegrityMacos(appPath)
+		result.AsarIntegrity = hasIntegrity
+		result.OnlyLoadFromAsar = onlyLoadFromAsar
+		if err != nil {
+			result.IntegrityError = err.Error()
+		}
+	case "windows":
+		hasIntegrity, onlyLoadFromAsar, err := s.checkAsarIntegrityWindows(appPath)
+		result.AsarIntegrity = hasIntegrity
+		result.OnlyLoadFromAsar = onlyLoadFromAsar
+		if err != nil {
+			result.IntegrityError = err.Error()
+		}
+	case "linux":
+		// Electron has no embedded ASAR integrity on Linux; only the fuses apply
+	default:
+		result.IntegrityError = "unsupported operating system"
+	}
+
+	// The fuse name is present in every modern Electron binary, so prefer its actual state
+	if state, ok := result.Fuses["OnlyLoadAppFromAsar"]; ok {
+		result.OnlyLoadFromAsar = state == FuseEnabled
+	}
+
+	if result.AsarIntegrity {
+		result.IntegrityHash = s.verifyAsarIntegrityHash(appPath)
+	}
+
+	return result
+}
+
+// verifyAsarIntegrityHash compares the hash embedded at build time with the
+// SHA-256 of the app.asar header, which is what Electron validates at startup.
+// It returns "" if no embedded hash could be found.
+func (s *Scanner) verifyAsarIntegrityHash(appPath string) string {
+	var content []byte
+	var re *regexp.Regexp
+	switch s.goos {
+	case "darwin":
+		content, _ = s.readPlist(filepath.Join(appPath, "Contents", "Info.plist"))
+		re = plistIntegrityHashRegex
+	case "windows":
+		content, _ = s.readFile(s.GetExecutablePath(appPath))
+		re = resourceIntegrityHashRegex
+	}
+
+	matches := re.FindSubmatch(content)
+	if len(matches) < 2 {
+		s.logger.Debug("No embedded ASAR integrity hash found", "app", appPath)
+		return ""
+	}
+
+	archive, err := s.ReadAsarArchive(s.GetAsarPath(appPath))
+	if err != nil {
+		s.logger.Warn("Error reading asar header", "app", appPath, "error", err)
+		return ""
+	}
+	sum := sha256.Sum256(archive.HeaderJSON)
+
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), string(matches[1])) {
+		s.logger.Warn("ASAR integrity hash does not match app.asar", "app", appPath, "expected", string(matches[1]))
+		return IntegrityHashMismatch
+	}
+	return IntegrityHashMatch
+}
+
+// checkAsarIntegrityMacos checks if ASAR integrity is enabled on macOS
+func (s *Scanner) checkAsarIntegrityMacos(appPath string) (bool, bool, error) {
+	// Check for 'ElectronAsarIntegrity' key in Info.plist
+	plistPath := filepath.Join(appPath, "Contents", "Info.plist")
+
+	s.logger.Debug("Checking Info.plist for ElectronAsarIntegrity", "path", plistPath)
+
+	// Read the Info.plist file
+	plistContent, err := s.readPlist(plistPath)
+	if err != nil {
+		return false, false, fmt.Errorf("error reading Info.plist: %v", err)
+	}
+
+	// Initialize result flags
+	hasAsarIntegrity := false
+	hasOnlyLoadFromAsar := false
+
+	// Check for ElectronAsarIntegrity key in the contents
+	if bytes.Contains(plistContent, []byte("<key>ElectronAsarIntegrity</key>")) {
+		s.logger.Debug("Found ElectronAsarIntegrity key in Info.plist")
+
+		// Check if there's a hash value in the integrity dictionary
+		if bytes.Contains(plistContent, []byte("<key>hash</key>")) &&
+			bytes.Contains(plistContent, []byte("<key>algorithm</key>")) {
+			s.logger.Debug("Found hash and algorithm keys - ASAR integrity appears properly configured")
+			hasAsarIntegrity = true
+		} else {
+			s.logger.Debug("ElectronAsarIntegrity key exists but hash/algorithm missing - may be misconfigured")
+			// Still return true since the integrity key exists
+			hasAsarIntegrity = true
+		}
+	}
+
+	// Check for OnlyLoadAppFromAsar fuse
+	// There are multiple places this could be indicated in the app
+
+	// Binary signature for OnlyLoadAppFromAsar
+	executablePath := filepath.Join(appPath, "Contents", "MacOS", filepath.Base(strings.TrimSuffix(appPath, ".app")))
+	if _, err := s.stat(executablePath); err == nil {
+		s.logger.Debug("Checking executable for OnlyLoadAppFromAsar fuse", "path", executablePath)
+
+		execContent, err := s.readFile(executablePath)
+		if err == nil {
+			// Look for OnlyLoadAppFromAsar signature
+			if bytes.Contains(execContent, []byte("OnlyLoadAppFromAsar")) {
+				s.logger.Debug("Found OnlyLoadAppFromAsar fuse signature in executable")
+				hasOnlyLoadFromAsar = true
+			}
+		}
+	}
+
+	// Also check Info.plist for any indicators
+	if !hasOnlyLoadFromAsar {
+		signatures := []string{
+			"OnlyLoadAppFromAsar",
+			"OnlyLoadFromAsar",
+			"FuseV1Options.OnlyLoadAppFromAsar",
+		}
+
+		for _, sig := range signatures {
+			if bytes.Contains(plistContent, []byte(sig)) {
+				s.logger.Debug("Found OnlyLoadAppFromAsar reference in Info.plist", "signature", sig)
+				hasOnlyLoadFromAsar = true
+				break
+			}
+		}
+	}
+
+	if !hasAsarIntegrity {
+		s.logger.Debug("No ElectronAsarIntegrity key found in Info.plist")
+	}
+	if !hasOnlyLoadFromAsar {
+		s.logger.Debug("No OnlyLoadAppFromAsar fuse detected")
+	}
+
+	return hasAsarIntegrity, hasOnlyLoadFromAsar, nil
+}
+
+// checkAsarIntegrityWindows checks if ASAR integrity is enabled on Windows
+func (s *Scanner) checkAsarIntegrityWindows(appPath string) (bool, bool, error) {
+	// On Windows, we need to check resource entries for ElectronAsar
+	exePath := appPath
+	if !strings.HasSuffix(exePath, ".exe") {
+		exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+	}
+
+	s.logger.Debug("Checking for ASAR integrity in Windows executable", "path", exePath)
+
+	// Since we can't directly read resource entries in Go without C bindings or external tools,
+	// we use basic binary content checking.
+	// For a production tool, using a proper Windows resource parser would be better.
+	exeContent, err := s.readFile(exePath)
+	if err != nil {
+		return false, false, fmt.Errorf("error reading executable: %v", err)
+	}
+
+	// Initialize result flags
+	hasAsarIntegrity := false
+	hasOnlyLoadFromAsar := false
+
+	// Look for more specific signatures of ASAR integrity
+	asarIntegritySignatures := [][]byte{
+		[]byte("ElectronAsar"),
+		[]byte("Integrity"),
+		[]byte("sha256"), // Common hash algorithm used
+	}
+
+	// Count how many signatures we find - more matches increases confidence
+	matchCount := 0
+	for _, sig := range asarIntegritySignatures {
+		if bytes.Contains(exeContent, sig) {
+			matchCount++
+			s.logger.Debug("Found integrity signature", "signature", string(sig))
+		}
+	}
+
+	// Look for EnableEmbeddedAsarIntegrityValidation which is specific to ASAR integrity
+	if bytes.Contains(exeContent, []byte("EnableEmbeddedAsarIntegrityValidation")) {
+		matchCount += 2 // This is a very strong indicator
+		s.logger.Debug("Found EnableEmbeddedAsarIntegrityValidation signature")
+	}
+
+	// The INTEGRITY/ELECTRONASAR resource holds the hash entry itself
+	if resourceIntegrityHashRegex.Match(exeContent) {
+		matchCount += 2
+		s.logger.Debug("Found ELECTRONASAR integrity resource")
+	}
+
+	// Check for OnlyLoadAppFromAsar fuse
+	if bytes.Contains(exeContent, []byte("OnlyLoadAppFromAsar")) {
+		s.logger.Debug("Found OnlyLoadAppFromAsar fuse signature")
+		hasOnlyLoadFromAsar = true
+	} else {
+		// Check for alternative spellings or implementations
+		onlyLoadSignatures := [][]byte{
+			[]byte("OnlyLoadFromAsar"),
+			[]byte("FuseV1Options.OnlyLoadAppFromAsar"),
+		}
+
+		for _, sig := range onlyLoadSignatures {
+			if bytes.Contains(exeContent, sig) {
+				s.logger.Debug("Found alternative OnlyLoadFromAsar signature", "signature", string(sig))
+				hasOnlyLoadFromAsar = true
+				break
+			}
+		}
+	}
+
+	// If we found at least 2 signatures, consider it likely to have ASAR integrity
+	if matchCount >= 2 {
+		s.logger.Debug("Multiple ASAR integrity indicators found - likely enabled")
+		hasAsarIntegrity = true
+	}
+
+	if !hasAsarIntegrity {
+		s.logger.Debug("No strong ASAR integrity indicators found")
+	}
+	if !hasOnlyLoadFromAsar {
+		s.logger.Debug("No OnlyLoadAppFromAsar fuse detected")
+	}
+
+	return hasAsarIntegrity, hasOnlyLoadFromAsar, nil
+}
@@ -0,0 +1,120 @@
+package electronscan_test
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestCheckAsarIntegrity(t *testing.T) {
+	// edit replaces a file of the application through fn
+	edit := func(fsys fstest.MapFS, name string, fn func([]byte) []byte) {
+		file := fsys[name]
+		file.Data = fn(append([]byte(nil), file.Data...))
+	}
+	macosAsar := func(appPath string) string { return path.Join(appPath, "Contents", "Resources", "app.asar") }
+
+	tests := []struct {
+		name      string
+		app       fixture.App
+		change    func(fsys fstest.MapFS, appPath string)
+		integrity bool
+		hash      string
+		onlyLoad  bool
+	}{
+		{
+			name:      "tampered header in a binary Info.plist app",
+			app:       fixture.App{BinaryPlist: true, Integrity: true, TamperedAsar: true},
+			integrity: true,
+			hash:      electronscan.IntegrityHashMismatch,
+		},
+		{
+			name:      "tampered header on Windows",
+			app:       fixture.App{GOOS: "windows", Integrity: true, TamperedAsar: true},
+			integrity: true,
+			hash:      electronscan.IntegrityHashMismatch,
+		},
+		{
+			// Electron hashes the header, which holds per-file hashes it
+			// checks on read; the scanner does not validate file data
+			name: "file data edited after the header",
+			app:  fixture.App{Integrity: true},
+			change: func(fsys fstest.MapFS, appPath string) {
+				edit(fsys, macosAsar(appPath), func(data []byte) []byte {
+					data[len(data)-1] ^= 0xff
+					return data
+				})
+			},
+			integrity: true,
+			hash:      electronscan.IntegrityHashMatch,
+		},
+		{
+			name: "embedded hash in upper case",
+			app:  fixture.App{Integrity: true},
+			change: func(fsys fstest.MapFS, appPath string) {
+				edit(fsys, path.Join(appPath, "Contents", "Info.plist"), func(data []byte) []byte {
+					return regexp.MustCompile(`[0-9a-f]{64}`).ReplaceAllFunc(data, bytes.ToUpper)
+				})
+			},
+			integrity: true,
+			hash:      electronscan.IntegrityHashMatch,
+		},
+		{
+			name: "unreadable archive",
+			app:  fixture.App{Integrity: true},
+			change: func(fsys fstest.MapFS, appPath string) {
+				fsys[macosAsar(appPath)] = &fstest.MapFile{Data: []byte("not an archive")}
+			},
+			integrity: true,
+		},
+		{
+			name:      "Linux has no embedded integrity",
+			app:       fixture.App{GOOS: "linux", Integrity: true},
+			integrity: false,
+		},
+		{
+			name:     "OnlyLoadAppFromAsar read from the fuse wire",
+			app:      fixture.App{GOOS: "windows", Fuses: map[string]string{"OnlyLoadAppFromAsar": electronscan.FuseEnabled}},
+			onlyLoad: true,
+		},
+		{
+			name:     "removed OnlyLoadAppFromAsar fuse",
+			app:      fixture.App{Fuses: map[string]string{"OnlyLoadAppFromAsar": electronscan.FuseRemoved}},
+			onlyLoad: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goos := tt.app.GOOS
+			if goos == "" {
+				goos = "darwin"
+			}
+			fsys := fstest.MapFS{}
+			appPath, err := tt.app.Build(fsys, installDirs[goos])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.change != nil {
+				tt.change(fsys, appPath)
+			}
+
+			s := electronscan.New(electronscan.Options{GOOS: goos, FS: fsys})
+			result := s.CheckAsarIntegrityForApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+			if result.AsarIntegrity != tt.integrity {
+				t.Errorf("asar integrity = %t, want %t", result.AsarIntegrity, tt.integrity)
+			}
+			if result.IntegrityHash != tt.hash {
+				t.Errorf("integrity hash = %q, want %q", result.IntegrityHash, tt.hash)
+			}
+			if result.OnlyLoadFromAsar != tt.onlyLoad {
+				t.Errorf("only load from asar = %t, want %t", result.OnlyLoadFromAsar, tt.onlyLoad)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package electronscan
+
+import (
+	"encoding/binary"
+	"testing"
+	"testing/fstest"
+)
+
+// rawAsar lays out an archive around a hand-written header
+func rawAsar(header string, data string) []byte {
+	padded := (len(header) + 3) &^ 3
+	archive := make([]byte, 16)
+	binary.LittleEndian.PutUint32(archive[0:], 4)
+	binary.LittleEndian.PutUint32(archive[4:], uint32(8+padded))
+	binary.LittleEndian.PutUint32(archive[8:], uint32(4+padded))
+	binary.LittleEndian.PutUint32(archive[12:], uint32(len(header)))
+	archive = append(archive, header...)
+	archive = append(archive, make([]byte, padded-len(header))...)
+	return append(archive, data...)
+}
+
+func TestReadAsarArchiveRejectsOversizedHeader(t *testing.T) {
+	archive := rawAsar(`{"files":{}}`, "")
+	binary.LittleEndian.PutUint32(archive[4:], 0xFFFFFFF0)
+	binary.LittleEndian.PutUint32(archive[12:], 0xFFFFFF00)
+	s := New(Options{GOOS: "darwin", FS: fstest.MapFS{"app.asar": {Data: archive}}})
+	if _, err := s.ReadAsarArchive("/app.asar"); err == nil {
+		t.Fatal("header larger than the file was accepted")
+	}
+}
+
+func TestAsarReadFile(t *testing.T) {
+	header := `{"files":{
+		"main.js":{"size":5,"offset":"0"},
+		"negative.js":{"size":-1,"offset":"0"},
+		"huge.js":{"size":1099511627776,"offset":"0"},
+		"past-end.js":{"size":2,"offset":"4"},
+		"alias.js":{"link":"main.js"},
+		"loop-a.js":{"link":"loop-b.js"},
+		"loop-b.js":{"link":"loop-a.js"},
+		"self.js":{"link":"self.js"}
+	}}`
+	s := New(Options{GOOS: "darwin", FS: fstest.MapFS{"app.asar": {Data: rawAsar(header, "hello")}}})
+	archive, err := s.ReadAsarArchive("/app.asar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"main.js", "hello", false},
+		{"alias.js", "hello", false},
+		{"negative.js", "", true},
+		{"huge.js", "", true},
+		{"past-end.js", "", true},
+		{"loop-a.js", "", true},
+		{"self.js", "", true},
+		{"missing.js", "", true},
+	}
+	for _, tt := range tests {
+		content, err := archive.ReadFile(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ReadFile(%s) error = %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if string(content) != tt.want {
+			t.Errorf("ReadFile(%s) = %q, want %q", tt.name, content, tt.want)
+		}
+	}
+}
+
+func TestReadAsarArchiveRejectsEscapingNames(t *testing.T) {
+	headers := map[string]string{
+		"parent":        `{"files":{"..":{"files":{"evil.node":{"size":0,"offset":"0","unpacked":true}}}}}`,
+		"nested parent": `{"files":{"node_modules":{"files":{"..":{"size":0,"offset":"0"}}}}}`,
+		"slash in name": `{"files":{"../../evil.node":{"size":0,"offset":"0","unpacked":true}}}`,
+		"backslash":     `{"files":{"..\\evil.node":{"size":0,"offset":"0","unpacked":true}}}`,
+		"empty name":    `{"files":{"":{"size":0,"offset":"0"}}}`,
+		"absolute link": `{"files":{"main.js":{"link":"/etc/passwd"}}}`,
+		"escaping link": `{"files":{"main.js":{"link":"lib/../../secret.js"}}}`,
+		"null entry":    `{"files":{"main.js":null}}`,
+		"drive name":    `{"files":{"main.js":{"link":"C:/Windows/win.ini"}}}`,
+	}
+	for name, header := range headers {
+		t.Run(name, func(t *testing.T) {
+			s := New(Options{GOOS: "darwin", FS: fstest.MapFS{"app.asar": {Data: rawAsar(header, "")}}})
+			if _, err := s.ReadAsarArchive("/app.asar"); err == nil {
+				t.Error("header was accepted")
+			}
+		})
+	}
+}
+
+func TestAsarLookupRejectsEscapingPaths(t *testing.T) {
+	header := `{"files":{"lib":{"files":{"main.js":{"size":0,"offset":"0","unpacked":true}}}}}`
+	s := New(Options{GOOS: "darwin", FS: fstest.MapFS{"app.asar": {Data: rawAsar(header, "")}}})
+	archive, err := s.ReadAsarArchive("/app.asar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"lib/main.js", true},
+		{"./lib//main.js", true},
+		{"lib/../lib/main.js", false},
+		{"../app.asar.unpacked/lib/main.js", false},
+		{"/lib/main.js", false},
+	}
+	for _, tt := range tests {
+		if found := archive.Lookup(tt.name) != nil; found != tt.ok {
+			t.Errorf("Lookup(%q) found = %t, want %t", tt.name, found, tt.ok)
+		}
+		if unpacked := archive.UnpackedPath(tt.name) != ""; unpacked != tt.ok {
+			t.Errorf("UnpackedPath(%q) = %q", tt.name, archive.UnpackedPath(tt.name))
+		}
+	}
+}
@@ -0,0 +1,759 @@
+package electronscan
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/plist"
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+	"github.com/adversis/electron-integrity/electronscan/internal/shelllink"
+)
+
+// Launch mechanisms reported in AutoStartEntry.Mechanism
+const (
+	MechanismLaunchAgent   = "launch-agent"
+	MechanismLaunchDaemon  = "launch-daemon"
+	MechanismLoginItem     = "login-item"
+	MechanismRunKey        = "run-key"
+	MechanismStartupFolder = "startup-folder"
+	MechanismXDGAutostart  = "xdg-autostart"
+)
+
+// AutoStartEntry is something that starts a program at boot or login
+type AutoStartEntry struct {
+	Mechanism string `json:"mechanism"`
+	// Location is the file or registry key holding the entry
+	Location string `json:"location"`
+	// Name is the launchd label, login item, registry value or shortcut name
+	Name string `json:"name,omitempty"`
+	// Command is the program started and its arguments
+	Command string `json:"command,omitempty"`
+	// User is the account the entry belongs to; empty for all users
+	User string `json:"user,omitempty"`
+
+	// program is the executable or bundle started, when known separately from Command
+	program string
+	// bundleIDs are the bundles a macOS entry is attributed to
+	bundleIDs []string
+}
+
+// autoStartCache holds the entries read for one Discover
+type autoStartCache struct {
+	once    sync.Once
+	entries []AutoStartEntry
+}
+
+// Directories holding auto-start entries
+const (
+	btmDir             = "/private/var/db/com.apple.backgroundtaskmanagement"
+	windowsStartupDir  = "Microsoft/Windows/Start Menu/Programs/Startup"
+	windowsCurrentKey  = `Microsoft\Windows\CurrentVersion`
+	startupApprovedKey = windowsCurrentKey + `\Explorer\StartupApproved`
+)
+
+// Background task management item types and dispositions, as sfltool dumpbtm names them
+const (
+	btmTypeLoginItem       = 0x4
+	btmTypeAgent           = 0x8
+	btmTypeDaemon          = 0x10
+	btmTypeLegacy          = 0x10000
+	btmDispositionEnabled  = 0x1
+	btmDispositionAllowed  = 0x2
+	btmDispositionLaunches = btmDispositionEnabled | btmDispositionAllowed
+)
+
+// PATH directories Linux packages link application launchers into
+var linuxBinDirs = []string{"/usr/bin", "/usr/local/bin", "/bin", "/snap/bin"}
+
+var (
+	// Field codes of a desktop entry Exec key, such as %U for the URLs to open
+	desktopFieldCodeRegex = regexp.MustCompile(`%[a-zA-Z%]`)
+
+	// %NAME% references in registry values
+	windowsEnvRegex = regexp.MustCompile(`%([^%]+)%`)
+
+	// Drive letters anywhere in a lowercased, slash-separated command
+	windowsDriveRegex = regexp.MustCompile(`\b[a-z]:/`)
+)
+
+// AutoStartEntries lists the launch agents and daemons, login items, Run keys,
+// Startup folder shortcuts and XDG autostart files of the target system. The
+// entries are read once and reused until the next Discover.
+func (s *Scanner) AutoStartEntries() []AutoStartEntry {
+	s.mu.Lock()
+	if s.autoStart == nil {
+		s.autoStart = &autoStartCache{}
+	}
+	cache := s.autoStart
+	s.mu.Unlock()
+
+	cache.once.Do(func() {
+		switch s.goos {
+		case "darwin":
+			cache.entries = append(s.launchdEntries(), s.loginItemEntries()...)
+		case "windows":
+			cache.entries = s.windowsAutoStartEntries()
+		case "linux":
+			cache.entries = s.xdgAutostartEntries()
+		}
+		s.logger.Debug("Read auto-start entries", "count", len(cache.entries))
+	})
+	return cache.entries
+}
+
+// AutoStartFor returns the auto-start entries that launch an application
+func (s *Scanner) AutoStartFor(result AppResult) []AutoStartEntry {
+	var entries []AutoStartEntry
+	for _, entry := range s.AutoStartEntries() {
+		if s.launches(entry, result) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// launches reports whether an entry starts the application or something inside it
+func (s *Scanner) launches(entry AutoStartEntry, result AppResult) bool {
+	if s.goos == "darwin" {
+		if result.BundleID != "" && slices.Contains(entry.bundleIDs, result.BundleID) {
+			return true
+		}
+		return entry.program != "" && (entry.program == result.Path || strings.HasPrefix(entry.program, result.Path+"/"))
+	}
+
+	exePath := result.Path
+	if s.goos == "windows" && !strings.HasSuffix(strings.ToLower(exePath), ".exe") {
+		exePath = filepath.Join(exePath, filepath.Base(exePath)+".exe")
+	}
+	if s.goos == "linux" {
+		exePath = s.linuxExecutable(exePath)
+	}
+	command := s.commandKey(entry.Command)
+	exe := s.commandKey(exePath)
+	dir := path.Dir(exe)
+	if dir != "/" && dir != "." && strings.Contains(command, dir+"/") {
+		return true
+	}
+
+	// A bare program name found through PATH, or on Linux the launcher that
+	// packages link into a PATH directory, such as /usr/bin/code
+	if fields := strings.Fields(command); len(fields) > 0 {
+		program := strings.Trim(fields[0], `"'`)
+		if program == path.Base(exe) {
+			return true
+		}
+		if s.goos == "linux" && slices.Contains(linuxBinDirs, path.Dir(program)) && path.Base(program) == path.Base(exe) {
+			return true
+		}
+	}
+
+	// Squirrel.Windows starts <root>\app-<version>\<name>.exe through <root>\Update.exe
+	// or a stub <root>\<name>.exe
+	if s.goos == "windows" && strings.HasPrefix(path.Base(dir), "app-") {
+		root := path.Dir(dir)
+		if strings.Contains(command, root+"/update.exe") && strings.Contains(command, path.Base(exe)) {
+			return true
+		}
+		return strings.Contains(command, root+"/"+path.Base(exe))
+	}
+	return false
+}
+
+// commandKey normalizes a command or path for substring matching: slash-separated,
+// and on Windows lowercased with drive letters dropped
+func (s *Scanner) commandKey(command string) string {
+	if s.goos != "windows" {
+		return filepath.ToSlash(command)
+	}
+	command = strings.ToLower(strings.ReplaceAll(command, `\`, "/"))
+	return windowsDriveRegex.ReplaceAllString(command, "/")
+}
+
+// userHomes returns the home directories under a directory by user name
+func (s *Scanner) userHomes(dir string, skip ...string) map[string]string {
+	homes := make(map[string]string)
+	entries, err := s.readDir(dir)
+	if err != nil {
+		return homes
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || slices.ContainsFunc(skip, func(s string) bool { return strings.EqualFold(s, name) }) {
+			continue
+		}
+		homes[name] = filepath.Join(dir, name)
+	}
+	return homes
+}
+
+// sortedUsers returns the user names of homes in order
+func sortedUsers(homes map[string]string) []string {
+	users := make([]string, 0, len(homes))
+	for user := range homes {
+		users = append(users, user)
+	}
+	slices.Sort(users)
+	return users
+}
+
+// launchdEntries reads the launch agents and daemons that start at load or
+// are kept alive, system-wide and for every user
+func (s *Scanner) launchdEntries() []AutoStartEntry {
+	type launchdDir struct {
+		dir, mechanism, user string
+	}
+	dirs := []launchdDir{
+		{"/Library/LaunchAgents", MechanismLaunchAgent, ""},
+		{"/Library/LaunchDaemons", MechanismLaunchDaemon, ""},
+	}
+	homes := s.userHomes("/Users", "Shared")
+	homes["root"] = "/var/root"
+	for _, user := range sortedUsers(homes) {
+		dirs = append(dirs, launchdDir{filepath.Join(homes[user], "Library", "LaunchAgents"), MechanismLaunchAgent, user})
+	}
+
+	var entries []AutoStartEntry
+	for _, d := range dirs {
+		files, err := s.readDir(d.dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".plist") {
+				continue
+			}
+			plistPath := filepath.Join(d.dir, file.Name())
+			if entry, ok := s.launchdEntry(plistPath); ok {
+				entry.Mechanism = d.mechanism
+				entry.User = d.user
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// launchdEntry reads a launchd job, returning false if it is disabled or only
+// starts on demand. Overrides from launchctl disable are not consulted.
+func (s *Scanner) launchdEntry(plistPath string) (AutoStartEntry, bool) {
+	content, err := s.readFile(plistPath)
+	if err != nil {
+		return AutoStartEntry{}, false
+	}
+	v, err := plist.Decode(content)
+	if err != nil {
+		s.logger.Debug("Error parsing launchd job", "path", plistPath, "error", err)
+		return AutoStartEntry{}, false
+	}
+	job, ok := v.(map[string]any)
+	if !ok {
+		return AutoStartEntry{}, false
+	}
+
+	if disabled, _ := job["Disabled"].(bool); disabled {
+		return AutoStartEntry{}, false
+	}
+	runAtLoad, _ := job["RunAtLoad"].(bool)
+	keepAlive := false
+	switch v := job["KeepAlive"].(type) {
+	case bool:
+		keepAlive = v
+	case map[string]any:
+		// Conditions such as SuccessfulExit still start the job at load
+		keepAlive = true
+	}
+	if !runAtLoad && !keepAlive {
+		return AutoStartEntry{}, false
+	}
+
+	entry := AutoStartEntry{Location: plistPath}
+	entry.Name, _ = job["Label"].(string)
+	var args []string
+	if list, ok := job["ProgramArguments"].([]any); ok {
+		for _, arg := range list {
+			if arg, ok := arg.(string); ok {
+				args = append(args, arg)
+			}
+		}
+	}
+	entry.program, _ = job["Program"].(string)
+	if entry.program == "" && len(args) > 0 {
+		entry.program = args[0]
+	}
+	entry.Command = strings.Join(args, " ")
+	if entry.Command == "" {
+		entry.Command = entry.program
+	}
+
+	switch ids := job["AssociatedBundleIdentifiers"].(type) {
+	case string:
+		entry.bundleIDs = []string{ids}
+	case []any:
+		for _, id := range ids {
+			if id, ok := id.(string); ok {
+				entry.bundleIDs = append(entry.bundleIDs, id)
+			}
+		}
+	}
+	return entry, true
+}
+
+// loginItemEntries reads the enabled login items, and the launch agents and
+// daemons registered by apps, from the background task management database
+// of macOS 13 and later
+func (s *Scanner) loginItemEntries() []AutoStartEntry {
+	files, err := s.readDir(btmDir)
+	if err != nil {
+		return nil
+	}
+
+	// BackgroundItems-v<n>.btm, the highest version being current
+	var btmPath string
+	latest := -1
+	for _, file := range files {
+		version, ok := strings.CutPrefix(file.Name(), "BackgroundItems-v")
+		if !ok || !strings.HasSuffix(version, ".btm") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(version, ".btm")); err == nil && n > latest {
+			latest = n
+			btmPath = filepath.Join(btmDir, file.Name())
+		}
+	}
+	if btmPath == "" {
+		return nil
+	}
+
+	content, err := s.readFile(btmPath)
+	if err != nil {
+		s.logger.Debug("Error reading login items", "path", btmPath, "error", err)
+		return nil
+	}
+	v, err := plist.Decode(content)
+	if err != nil {
+		s.logger.Debug("Error parsing login items", "path", btmPath, "error", err)
+		return nil
+	}
+	archive, _ := v.(map[string]any)
+	objects, _ := archive["$objects"].([]any)
+	a := keyedArchive(objects)
+
+	var entries []AutoStartEntry
+	for _, object := range objects {
+		item, ok := object.(map[string]any)
+		if !ok {
+			continue
+		}
+		itemType, ok := plistInt(item["type"])
+		if !ok {
+			continue
+		}
+		disposition, ok := plistInt(item["disposition"])
+		if !ok || disposition&btmDispositionLaunches != btmDispositionLaunches {
+			continue
+		}
+		// Legacy agents and daemons are read from their plists instead
+		if itemType&btmTypeLegacy != 0 || itemType&(btmTypeLoginItem|btmTypeAgent|btmTypeDaemon) == 0 {
+			continue
+		}
+
+		entry := AutoStartEntry{Mechanism: MechanismLoginItem, Location: btmPath, Name: a.string(item["name"])}
+		switch {
+		case itemType&btmTypeAgent != 0:
+			entry.Mechanism = MechanismLaunchAgent
+		case itemType&btmTypeDaemon != 0:
+			entry.Mechanism = MechanismLaunchDaemon
+		}
+		entry.program = a.string(item["executablePath"])
+		if entry.program == "" {
+			entry.program = fileURLPath(a.string(item["url"]))
+		}
+		entry.Command = entry.program
+		if id := a.string(item["bundleIdentifier"]); id != "" {
+			entry.bundleIDs = append(entry.bundleIDs, id)
+		}
+		entry.bundleIDs = append(entry.bundleIDs, a.strings(item["associatedBundleIdentifiers"])...)
+		if entry.program == "" && len(entry.bundleIDs) == 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// keyedArchive is the $objects table of an NSKeyedArchiver property list
+type keyedArchive []any
+
+// resolve follows object references
+func (a keyedArchive) resolve(v any) any {
+	for range maxPlistRefs {
+		uid, ok := v.(plist.UID)
+		if !ok {
+			return v
+		}
+		if uint64(uid) >= uint64(len(a)) {
+			return nil
+		}
+		v = a[uid]
+	}
+	return nil
+}
+
+// maxPlistRefs bounds chains of object references
+const maxPlistRefs = 8
+
+// string returns a string, or the text of an NSString or NSURL object
+func (a keyedArchive) string(v any) string {
+	switch v := a.resolve(v).(type) {
+	case string:
+		if v == "$null" {
+			return ""
+		}
+		return v
+	case map[string]any:
+		for _, key := range []string{"NS.string", "NS.relative"} {
+			if text, ok := a.resolve(v[key]).(string); ok && text != "$null" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// strings returns the strings of an NSArray or NSSet object
+func (a keyedArchive) strings(v any) []string {
+	var list []any
+	switch v := a.resolve(v).(type) {
+	case []any:
+		list = v
+	case map[string]any:
+		list, _ = v["NS.objects"].([]any)
+	}
+	var texts []string
+	for _, item := range list {
+		if text := a.string(item); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// plistInt returns a property list integer
+func plistInt(v any) (int64, bool) {
+	n, ok := v.(int64)
+	return n, ok
+}
+
+// fileURLPath converts a file:// URL into a path without a trailing slash
+func fileURLPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// windowsHive is a registry hive with the auto-start entries tied to it: the
+// SOFTWARE hive with the common Startup folder, or a user's NTUSER.DAT with
+// theirs
+type windowsHive struct {
+	user string
+	// location prefixes the key paths reported, such as `HKLM\SOFTWARE\`
+	location string
+	// keyPrefix leads from the hive root to the Software key
+	keyPrefix  string
+	values     func(key string) ([]registry.Value, error)
+	startupDir string
+	env        map[string]string
+}
+
+// windowsAutoStartEntries reads the Run keys and Startup folders of the
+// machine and of every user profile
+func (s *Scanner) windowsAutoStartEntries() []AutoStartEntry {
+	root, drive := s.windowsSystemDrive()
+
+	hives := []windowsHive{{
+		location:   `HKLM\SOFTWARE\`,
+		values:     s.hiveValues(filepath.Join(root, "Windows", "System32", "config", "SOFTWARE"), "HKLM"),
+		startupDir: filepath.Join(root, "ProgramData", filepath.FromSlash(windowsStartupDir)),
+		env:        windowsEnv(drive, ""),
+	}}
+
+	profiles := s.userHomes(filepath.Join(root, "Users"), "Public", "Default", "Default User", "All Users")
+	for _, user := range sortedUsers(profiles) {
+		profile := profiles[user]
+		live := ""
+		if s.isHost() && strings.EqualFold(filepath.Clean(os.Getenv("USERPROFILE")), profile) {
+			live = "HKCU"
+		}
+		hives = append(hives, windowsHive{
+			user:       user,
+			location:   `HKCU\`,
+			keyPrefix:  `Software\`,
+			values:     s.hiveValues(filepath.Join(profile, "NTUSER.DAT"), live),
+			startupDir: filepath.Join(profile, "AppData", "Roaming", filepath.FromSlash(windowsStartupDir)),
+			env:        windowsEnv(drive, user),
+		})
+	}
+
+	var entries []AutoStartEntry
+	for _, hive := range hives {
+		entries = append(entries, s.runKeyEntries(hive)...)
+		entries = append(entries, s.startupFolderEntries(hive)...)
+	}
+	return entries
+}
+
+// windowsSystemDrive returns the root directory of the system drive and its
+// name for environment variables: C:\ and C: on the host, the root of
+// Options.FS and C: otherwise
+func (s *Scanner) windowsSystemDrive() (string, string) {
+	if s.isHost() && runtime.GOOS == "windows" {
+		drive := os.Getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		return drive + `\`, drive
+	}
+	return string(filepath.Separator), "C:"
+}
+
+// hiveValues returns a reader for the keys of a hive file. Windows keeps loaded
+// hives locked, so on the host the live registry is read instead from liveRoot,
+// HKLM or HKCU, when one is given. It returns nil if neither can be read.
+func (s *Scanner) hiveValues(hivePath string, liveRoot string) func(key string) ([]registry.Value, error) {
+	content, err := s.readFile(hivePath)
+	if err == nil {
+		hive, err := registry.Open(content)
+		if err != nil {
+			s.logger.Debug("Error parsing registry hive", "path", hivePath, "error", err)
+			return nil
+		}
+		return hive.Values
+	}
+	if s.isHost() && liveRoot != "" {
+		s.logger.Debug("Reading live registry instead of hive", "path", hivePath, "root", liveRoot)
+		return func(key string) ([]registry.Value, error) {
+			return liveRegistryValues(liveRoot, key)
+		}
+	}
+	s.logger.Debug("Error reading registry hive", "path", hivePath, "error", err)
+	return nil
+}
+
+// windowsEnv returns the environment variables used in auto-start commands,
+// upper-cased, for the system and optionally one user
+func windowsEnv(drive, user string) map[string]string {
+	env := map[string]string{
+		"SYSTEMDRIVE":       drive,
+		"SYSTEMROOT":        drive + `\Windows`,
+		"WINDIR":            drive + `\Windows`,
+		"PROGRAMFILES":      drive + `\Program Files`,
+		"PROGRAMW6432":      drive + `\Program Files`,
+		"PROGRAMFILES(X86)": drive + `\Program Files (x86)`,
+		"PROGRAMDATA":       drive + `\ProgramData`,
+		"ALLUSERSPROFILE":   drive + `\ProgramData`,
+		"PUBLIC":            drive + `\Users\Public`,
+	}
+	if user != "" {
+		profile := drive + `\Users\` + user
+		env["USERNAME"] = user
+		env["USERPROFILE"] = profile
+		env["APPDATA"] = profile + `\AppData\Roaming`
+		env["LOCALAPPDATA"] = profile + `\AppData\Local`
+	}
+	return env
+}
+
+// expandWindowsEnv replaces the %NAME% references that env defines
+func expandWindowsEnv(command string, env map[string]string) string {
+	return windowsEnvRegex.ReplaceAllStringFunc(command, func(ref string) string {
+		if value, ok := env[strings.ToUpper(strings.Trim(ref, "%"))]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// disabledStartupItems returns the lowercased names that Task Manager or
+// Settings turned off under a StartupApproved key: the first byte of their
+// value is odd
+func (hive windowsHive) disabledStartupItems(approvedKey string) map[string]bool {
+	disabled := make(map[string]bool)
+	if hive.values == nil {
+		return disabled
+	}
+	values, err := hive.values(hive.keyPrefix + startupApprovedKey + `\` + approvedKey)
+	if err != nil {
+		return disabled
+	}
+	for _, v := range values {
+		if len(v.Data) > 0 && v.Data[0]&1 != 0 {
+			disabled[strings.ToLower(v.Name)] = true
+		}
+	}
+	return disabled
+}
+
+// runKeyEntries reads the Run and RunOnce values of a hive, native and 32-bit,
+// leaving out those turned off
+func (s *Scanner) runKeyEntries(hive windowsHive) []AutoStartEntry {
+	if hive.values == nil {
+		return nil
+	}
+
+	var entries []AutoStartEntry
+	for _, key := range []struct {
+		path, approved string
+	}{
+		{windowsCurrentKey + `\Run`, "Run"},
+		{windowsCurrentKey + `\RunOnce`, ""},
+		{`WOW6432Node\` + windowsCurrentKey + `\Run`, "Run32"},
+		{`WOW6432Node\` + windowsCurrentKey + `\RunOnce`, ""},
+	} {
+		values, err := hive.values(hive.keyPrefix + key.path)
+		if err != nil {
+			continue
+		}
+		disabled := map[string]bool{}
+		if key.approved != "" {
+			disabled = hive.disabledStartupItems(key.approved)
+		}
+		for _, v := range values {
+			command := v.String()
+			if command == "" || disabled[strings.ToLower(v.Name)] {
+				continue
+			}
+			entries = append(entries, AutoStartEntry{
+				Mechanism: MechanismRunKey,
+				Location:  hive.location + hive.keyPrefix + key.path,
+				Name:      v.Name,
+				Command:   expandWindowsEnv(command, hive.env),
+				User:      hive.user,
+			})
+		}
+	}
+	return entries
+}
+
+// startupFolderEntries reads the shortcuts and programs in a Startup folder,
+// leaving out those turned off
+func (s *Scanner) startupFolderEntries(hive windowsHive) []AutoStartEntry {
+	files, err := s.readDir(hive.startupDir)
+	if err != nil {
+		return nil
+	}
+	disabled := hive.disabledStartupItems("StartupFolder")
+
+	var entries []AutoStartEntry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.EqualFold(name, "desktop.ini") || disabled[strings.ToLower(name)] {
+			continue
+		}
+		filePath := filepath.Join(hive.startupDir, name)
+		entry := AutoStartEntry{Mechanism: MechanismStartupFolder, Location: filePath, Name: name, Command: filePath, User: hive.user}
+
+		if strings.EqualFold(filepath.Ext(name), ".lnk") {
+			entry.Name = strings.TrimSuffix(name, filepath.Ext(name))
+			content, err := s.readFile(filePath)
+			if err != nil {
+				continue
+			}
+			link, err := shelllink.Parse(content)
+			if err != nil {
+				s.logger.Debug("Error parsing shortcut", "path", filePath, "error", err)
+				continue
+			}
+			entry.Command = expandWindowsEnv(link.Target, hive.env)
+			if link.Arguments != "" {
+				entry.Command = `"` + entry.Command + `" ` + link.Arguments
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// xdgAutostartEntries reads the desktop entries that freedesktop.org sessions
+// start at login, system-wide and for every user
+func (s *Scanner) xdgAutostartEntries() []AutoStartEntry {
+	type autostartDir struct {
+		dir, user string
+	}
+	dirs := []autostartDir{{"/etc/xdg/autostart", ""}}
+	homes := s.userHomes("/home")
+	homes["root"] = "/root"
+	for _, user := range sortedUsers(homes) {
+		dirs = append(dirs, autostartDir{filepath.Join(homes[user], ".config", "autostart"), user})
+	}
+
+	var entries []AutoStartEntry
+	for _, d := range dirs {
+		files, err := s.readDir(d.dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".desktop") {
+				continue
+			}
+			filePath := filepath.Join(d.dir, file.Name())
+			content, err := s.readFile(filePath)
+			if err != nil {
+				continue
+			}
+			keys := desktopEntry(content)
+			if keys["Hidden"] == "true" || keys["X-GNOME-Autostart-enabled"] == "false" || keys["Exec"] == "" {
+				continue
+			}
+			command := desktopFieldCodeRegex.ReplaceAllStringFunc(keys["Exec"], func(code string) string {
+				if code == "%%" {
+					return "%"
+				}
+				return ""
+			})
+			entries = append(entries, AutoStartEntry{
+				Mechanism: MechanismXDGAutostart,
+				Location:  filePath,
+				Name:      keys["Name"],
+				Command:   strings.Join(strings.Fields(command), " "),
+				User:      d.user,
+			})
+		}
+	}
+	return entries
+}
+
+// desktopEntry returns the unlocalized keys of the [Desktop Entry] group of a .desktop file
+func desktopEntry(content []byte) map[string]string {
+	keys := make(map[string]string)
+	inGroup := false
+	lines := bufio.NewScanner(bytes.NewReader(content))
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Desktop Entry]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inGroup || !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if !strings.Contains(key, "[") {
+			keys[key] = strings.TrimSpace(value)
+		}
+	}
+	return keys
+}
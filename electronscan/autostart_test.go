@@ -0,0 +1,194 @@
+package electronscan_test
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+	"github.com/adversis/electron-integrity/electronscan/internal/plist"
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+	"github.com/adversis/electron-integrity/electronscan/internal/shelllink"
+)
+
+// autoStartNames returns the mechanism, user and name of entries
+func autoStartNames(entries []electronscan.AutoStartEntry) []string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Mechanism+"|"+entry.User+"|"+entry.Name)
+	}
+	return names
+}
+
+func TestAutoStartForLaunchd(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "darwin"}.Build(fsys, "Applications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := func(file string, v map[string]any) {
+		t.Helper()
+		data, err := plist.MarshalXML(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[file] = &fstest.MapFile{Data: data}
+	}
+	helper := "/Applications/Demo.app/Contents/Library/LoginItems/Demo Helper.app/Contents/MacOS/Demo Helper"
+	job("Library/LaunchAgents/com.example.demo.helper.plist", map[string]any{
+		"Label":            "com.example.demo.helper",
+		"ProgramArguments": []any{helper, "--tray"},
+		"KeepAlive":        map[string]any{"SuccessfulExit": false},
+	})
+	job("Users/alice/Library/LaunchAgents/com.example.updater.plist", map[string]any{
+		"Label":                       "com.example.updater",
+		"Program":                     "/usr/local/bin/demo-updater",
+		"RunAtLoad":                   true,
+		"AssociatedBundleIdentifiers": "com.example.demo",
+	})
+	job("Library/LaunchAgents/com.example.backup.plist", map[string]any{
+		"Label":            "com.example.backup",
+		"ProgramArguments": []any{"/Applications/Demo.app.bak/Contents/MacOS/Demo"},
+		"RunAtLoad":        true,
+	})
+	job("Library/LaunchAgents/com.example.demo.disabled.plist", map[string]any{
+		"Label":            "com.example.demo.disabled",
+		"ProgramArguments": []any{"/Applications/Demo.app/Contents/MacOS/Demo"},
+		"RunAtLoad":        true,
+		"Disabled":         true,
+	})
+	job("Library/LaunchDaemons/com.example.demo.ondemand.plist", map[string]any{
+		"Label":   "com.example.demo.ondemand",
+		"Program": "/Applications/Demo.app/Contents/MacOS/Demo",
+	})
+	fsys["Library/LaunchAgents/com.example.demo.broken.plist"] = &fstest.MapFile{Data: []byte("<plist>")}
+	s := electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys})
+
+	result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+	want := []string{
+		"launch-agent||com.example.demo.helper",
+		"launch-agent|alice|com.example.updater",
+	}
+	if got := autoStartNames(result.AutoStartEntries); !slices.Equal(got, want) || !result.AutoStart {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+	if command := result.AutoStartEntries[0].Command; command != helper+" --tray" {
+		t.Errorf("command = %q", command)
+	}
+}
+
+func TestAutoStartForWindows(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "windows"}.Build(fsys, "Program Files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const run = `Microsoft\Windows\CurrentVersion\Run`
+	const approved = `Microsoft\Windows\CurrentVersion\Explorer\StartupApproved\`
+	fsys["Windows/System32/config/SOFTWARE"] = &fstest.MapFile{Data: registry.Marshal(map[string][]registry.Value{
+		run: {
+			registry.StringValue("Demo", `"C:\PROGRAM FILES\DEMO\demo.EXE" --startup`),
+			registry.StringValue("Demo Disabled", `C:\Program Files\Demo\Demo.exe`),
+			registry.StringValue("Other", `C:\Program Files\Other\Other.exe`),
+		},
+		`WOW6432Node\` + run + `Once`: {registry.StringValue("Demo Update", `%ProgramFiles%\Demo\Demo.exe --update`)},
+		approved + "Run":              {{Name: "Demo Disabled", Type: registry.TypeBinary, Data: []byte{3, 0, 0, 0}}},
+	})}
+	fsys["Users/alice/NTUSER.DAT"] = &fstest.MapFile{Data: registry.Marshal(map[string][]registry.Value{
+		`Software\` + run: {registry.StringValue("Demo", "Demo.exe --hidden")},
+	})}
+	startup := "Users/alice/AppData/Roaming/Microsoft/Windows/Start Menu/Programs/Startup/"
+	fsys[startup+"Demo.lnk"] = &fstest.MapFile{Data: shelllink.Marshal(shelllink.Link{Target: `%ProgramFiles%\Demo\Demo.exe`, Arguments: "--minimized"})}
+	fsys[startup+"desktop.ini"] = &fstest.MapFile{Data: []byte("[.ShellClassInfo]")}
+	s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+
+	result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+	want := []string{
+		"run-key||Demo",
+		"run-key||Demo Update",
+		"run-key|alice|Demo",
+		"startup-folder|alice|Demo",
+	}
+	if got := autoStartNames(result.AutoStartEntries); !slices.Equal(got, want) {
+		t.Fatalf("entries = %q, want %q", got, want)
+	}
+	if command := result.AutoStartEntries[1].Command; command != `C:\Program Files\Demo\Demo.exe --update` {
+		t.Errorf("Run key command = %q, want %%ProgramFiles%% expanded", command)
+	}
+	if command := result.AutoStartEntries[3].Command; command != `"C:\Program Files\Demo\Demo.exe" --minimized` {
+		t.Errorf("shortcut command = %q", command)
+	}
+}
+
+func TestAutoStartEntriesCachedUntilDiscover(t *testing.T) {
+	fsys := fstest.MapFS{}
+	if _, err := (fixture.App{GOOS: "linux", AutoStart: true}).Build(fsys, "opt"); err != nil {
+		t.Fatal(err)
+	}
+	s := electronscan.New(electronscan.Options{GOOS: "linux", FS: fsys})
+	if n := len(s.AutoStartEntries()); n != 1 {
+		t.Fatalf("entries = %d, want 1", n)
+	}
+
+	fsys["home/alice/.config/autostart/demo.desktop"] = &fstest.MapFile{Data: []byte("[Desktop Entry]\nExec=/opt/Demo/demo\n")}
+	if n := len(s.AutoStartEntries()); n != 1 {
+		t.Errorf("entries before Discover = %d, want the cached 1", n)
+	}
+	if _, err := s.Discover(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.AutoStartEntries()); n != 2 {
+		t.Errorf("entries after Discover = %d, want 2", n)
+	}
+}
+
+func TestXDGAutostart(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "linux"}.Build(fsys, "opt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desktop := func(lines ...string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(strings.Join(lines, "\n") + "\n")}
+	}
+	fsys["etc/xdg/autostart/demo.desktop"] = desktop(
+		"# Started for every user",
+		"[Desktop Entry]",
+		"Type=Application",
+		"Name=Demo",
+		"Name[de]=Demo-Anwendung",
+		"Exec=/opt/Demo/demo --hidden %U",
+		"[Desktop Action quit]",
+		"Exec=/usr/bin/other-app --quit",
+	)
+	fsys["home/alice/.config/autostart/demo-tray.desktop"] = desktop("[Desktop Entry]", "Name=Demo tray", "Exec=/usr/bin/demo --tray")
+	fsys["home/alice/.config/autostart/hidden.desktop"] = desktop("[Desktop Entry]", "Exec=/opt/Demo/demo", "Hidden=true")
+	fsys["home/bob/.config/autostart/disabled.desktop"] = desktop("[Desktop Entry]", "Exec=/opt/Demo/demo", "X-GNOME-Autostart-enabled=false")
+	fsys["home/bob/.config/autostart/other.desktop"] = desktop("[Desktop Entry]", "Exec=/usr/bin/other-app %%f")
+	fsys["home/bob/.config/autostart/notes.txt"] = desktop("[Desktop Entry]", "Exec=/opt/Demo/demo")
+	s := electronscan.New(electronscan.Options{GOOS: "linux", FS: fsys})
+
+	var got []string
+	for _, entry := range s.AutoStartEntries() {
+		if entry.Mechanism != electronscan.MechanismXDGAutostart {
+			t.Errorf("mechanism = %q", entry.Mechanism)
+		}
+		got = append(got, entry.User+"|"+entry.Name+"|"+entry.Command)
+	}
+	want := []string{
+		"|Demo|/opt/Demo/demo --hidden",
+		"alice|Demo tray|/usr/bin/demo --tray",
+		"bob||/usr/bin/other-app %f",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+
+	result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+	if !result.AutoStart || len(result.AutoStartEntries) != 2 {
+		t.Errorf("autostart = %t, entries = %+v, want the system entry and the /usr/bin launcher", result.AutoStart, result.AutoStartEntries)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package electronscan
+
+import (
+	"errors"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+)
+
+// liveRegistryValues is only available on Windows
+func liveRegistryValues(root string, path string) ([]registry.Value, error) {
+	return nil, errors.New("the registry is only available on Windows")
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package electronscan
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+)
+
+// errorNoMoreItems ends a registry enumeration
+const errorNoMoreItems syscall.Errno = 259
+
+// Value names are at most 16383 characters; larger data is skipped
+const (
+	maxValueName = 16384
+	maxValueData = 64 * 1024
+)
+
+var procRegEnumValueW = advapi32.NewProc("RegEnumValueW")
+
+// liveRegistryValues reads the values of a key under HKLM or HKCU from the
+// registry of this system
+func liveRegistryValues(root string, path string) ([]registry.Value, error) {
+	rootKey := syscall.Handle(syscall.HKEY_LOCAL_MACHINE)
+	if root == "HKCU" {
+		rootKey = syscall.HKEY_CURRENT_USER
+	}
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(rootKey, pathPtr, 0, syscall.KEY_READ, &key); err != nil {
+		if err == syscall.ERROR_FILE_NOT_FOUND {
+			return nil, registry.ErrNotFound
+		}
+		return nil, err
+	}
+	defer syscall.RegCloseKey(key)
+
+	var values []registry.Value
+	name := make([]uint16, maxValueName)
+	data := make([]byte, maxValueData)
+	for i := uint32(0); ; i++ {
+		nameLen := uint32(len(name))
+		dataLen := uint32(len(data))
+		var valueType uint32
+		r, _, _ := procRegEnumValueW.Call(uintptr(key), uintptr(i),
+			uintptr(unsafe.Pointer(&name[0])), uintptr(unsafe.Pointer(&nameLen)), 0,
+			uintptr(unsafe.Pointer(&valueType)), uintptr(unsafe.Pointer(&data[0])), uintptr(unsafe.Pointer(&dataLen)))
+		switch syscall.Errno(r) {
+		case 0:
+		case syscall.ERROR_MORE_DATA:
+			continue
+		case errorNoMoreItems:
+			return values, nil
+		default:
+			return values, syscall.Errno(r)
+		}
+		values = append(values, registry.Value{
+			Name: syscall.UTF16ToString(name[:nameLen]),
+			Type: valueType,
+			Data: append([]byte(nil), data[:dataLen]...),
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package electronscan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Snapshot is a baseline of the files an attacker would touch to backdoor an
+// Electron application, recorded so later scans can detect tampering
+type Snapshot struct {
+	ScannerVersion string        `json:"scanner_version"`
+	CreatedAt      time.Time     `json:"created_at"`
+	OS             string        `json:"os"`
+	Apps           []AppSnapshot `json:"apps"`
+}
+
+// AppSnapshot holds the hashes and fuse state of a single application
+type AppSnapshot struct {
+	Path             string            `json:"path"`
+	Framework        string            `json:"framework"`
+	Version          string            `json:"electron_version,omitempty"`
+	Executable       string            `json:"executable,omitempty"`
+	ExecutableSHA256 string            `json:"executable_sha256,omitempty"`
+	AsarSHA256       string            `json:"asar_sha256,omitempty"`
+	UnpackedFiles    map[string]string `json:"unpacked_files,omitempty"`
+	NodeFiles        map[string]string `json:"node_files,omitempty"`
+	Fuses            map[string]string `json:"fuses,omitempty"`
+	HasAppFolder     bool              `json:"has_app_folder"`
+}
+
+// Kinds of change reported by DiffSnapshots
+const (
+	ChangeAppAdded         = "app-added"
+	ChangeAppRemoved       = "app-removed"
+	ChangeVersion          = "version-changed"
+	ChangeExecutable       = "executable-changed"
+	ChangeAsar             = "asar-changed"
+	ChangeUnpackedAdded    = "unpacked-file-added"
+	ChangeUnpackedRemoved  = "unpacked-file-removed"
+	ChangeUnpackedModified = "unpacked-file-changed"
+	ChangeNodeFileAdded    = "node-file-added"
+	ChangeNodeFileRemoved  = "node-file-removed"
+	ChangeNodeFileModified = "node-file-changed"
+	ChangeFuseFlipped      = "fuse-flipped"
+	ChangeAppFolderAdded   = "app-folder-added"
+	ChangeAppFolderRemoved = "app-folder-removed"
+)
+
+// SnapshotChange is one difference between a baseline and the current state
+type SnapshotChange struct {
+	Kind string `json:"kind"`
+	App  string `json:"app"`
+	Path string `json:"path,omitempty"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// NewSnapshot records a baseline for every Chromium-based application in results.
+// Node files are taken from the results, so they should be inventoried without a limit.
+func (s *Scanner) NewSnapshot(results []AppResult, scannerVersion string) *Snapshot {
+	snapshot := &Snapshot{
+		ScannerVersion: scannerVersion,
+		CreatedAt:      time.Now().UTC(),
+		OS:             s.goos,
+		Apps:           []AppSnapshot{},
+	}
+	for _, result := range results {
+		if result.Framework == "" {
+			continue
+		}
+		snapshot.Apps = append(snapshot.Apps, s.SnapshotApp(result))
+	}
+	sort.Slice(snapshot.Apps, func(i, j int) bool {
+		return snapshot.Apps[i].Path < snapshot.Apps[j].Path
+	})
+	return snapshot
+}
+
+// SnapshotApp hashes the executable, app.asar, unpacked files and .node files of an application
+func (s *Scanner) SnapshotApp(result AppResult) AppSnapshot {
+	app := AppSnapshot{
+		Path:       result.Path,
+		Framework:  result.Framework,
+		Version:    result.Version,
+		Executable: s.GetExecutablePath(result.Path),
+		Fuses:      result.Fuses,
+	}
+
+	if app.Executable != "" {
+		app.ExecutableSHA256 = s.hashFile(app.Executable)
+	}
+
+	resourcesDir := s.GetResourcesPath(result.Path)
+	if resourcesDir == "" {
+		return app
+	}
+
+	// The header only carries per-file hashes when the app was built with
+	// integrity enabled, so the whole archive is hashed to catch edits to file data
+	app.AsarSHA256 = s.hashFile(filepath.Join(resourcesDir, "app.asar"))
+
+	unpackedDir := filepath.Join(resourcesDir, "app.asar.unpacked")
+	s.walk(unpackedDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if !os.IsNotExist(err) {
+				s.logger.Debug("Error accessing path", "path", path, "error", err)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(unpackedDir, path)
+		if err != nil {
+			return nil
+		}
+		if app.UnpackedFiles == nil {
+			app.UnpackedFiles = make(map[string]string)
+		}
+		app.UnpackedFiles[filepath.ToSlash(rel)] = s.hashFile(path)
+		return nil
+	})
+
+	for _, module := range result.NodeFiles {
+		if app.NodeFiles == nil {
+			app.NodeFiles = make(map[string]string)
+		}
+		app.NodeFiles[module.Path] = module.SHA256
+	}
+
+	// A resources/app folder takes precedence over app.asar and bypasses integrity checks
+	if info, err := s.stat(filepath.Join(resourcesDir, "app")); err == nil && info.IsDir() {
+		app.HasAppFolder = true
+	}
+
+	return app
+}
+
+// hashFile returns the hex SHA-256 of a file, or "" if it cannot be read
+func (s *Scanner) hashFile(path string) string {
+	f, err := s.open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ReadSnapshot loads a baseline written by a previous snapshot
+func ReadSnapshot(path string) (*Snapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("error parsing baseline %s: %v", path, err)
+	}
+	return &snapshot, nil
+}
+
+// DiffSnapshots compares the current state against a baseline.
+// Changes are ordered by application path, then by kind of check.
+func DiffSnapshots(baseline *Snapshot, current *Snapshot) []SnapshotChange {
+	before := make(map[string]AppSnapshot, len(baseline.Apps))
+	for _, app := range baseline.Apps {
+		before[app.Path] = app
+	}
+	after := make(map[string]AppSnapshot, len(current.Apps))
+	for _, app := range current.Apps {
+		after[app.Path] = app
+	}
+
+	var changes []SnapshotChange
+	for _, path := range sortedKeys(before, after) {
+		old, hadOld := before[path]
+		now, hasNow := after[path]
+		switch {
+		case !hadOld:
+			changes = append(changes, SnapshotChange{Kind: ChangeAppAdded, App: path, New: now.Version})
+		case !hasNow:
+			changes = append(changes, SnapshotChange{Kind: ChangeAppRemoved, App: path, Old: old.Version})
+		default:
+			changes = append(changes, diffApp(old, now)...)
+		}
+	}
+	return changes
+}
+
+// diffApp compares two snapshots of the same application
+func diffApp(old AppSnapshot, now AppSnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	add := func(kind string, path string, before string, after string) {
+		changes = append(changes, SnapshotChange{Kind: kind, App: old.Path, Path: path, Old: before, New: after})
+	}
+
+	if old.Version != now.Version {
+		add(ChangeVersion, "", old.Version, now.Version)
+	}
+	if old.ExecutableSHA256 != now.ExecutableSHA256 {
+		add(ChangeExecutable, now.Executable, old.ExecutableSHA256, now.ExecutableSHA256)
+	}
+	if old.AsarSHA256 != now.AsarSHA256 {
+		add(ChangeAsar, "app.asar", old.AsarSHA256, now.AsarSHA256)
+	}
+
+	for _, name := range sortedKeys(old.UnpackedFiles, now.UnpackedFiles) {
+		before, hadBefore := old.UnpackedFiles[name]
+		after, hasAfter := now.UnpackedFiles[name]
+		switch {
+		case !hadBefore:
+			add(ChangeUnpackedAdded, name, "", after)
+		case !hasAfter:
+			add(ChangeUnpackedRemoved, name, before, "")
+		case before != after:
+			add(ChangeUnpackedModified, name, before, after)
+		}
+	}
+
+	for _, name := range sortedKeys(old.NodeFiles, now.NodeFiles) {
+		before, hadBefore := old.NodeFiles[name]
+		after, hasAfter := now.NodeFiles[name]
+		switch {
+		case !hadBefore:
+			add(ChangeNodeFileAdded, name, "", after)
+		case !hasAfter:
+			add(ChangeNodeFileRemoved, name, before, "")
+		case before != after:
+			add(ChangeNodeFileModified, name, before, after)
+		}
+	}
+
+	for _, name := range sortedKeys(old.Fuses, now.Fuses) {
+		if old.Fuses[name] != now.Fuses[name] {
+			add(ChangeFuseFlipped, name, old.Fuses[name], now.Fuses[name])
+		}
+	}
+
+	if !old.HasAppFolder && now.HasAppFolder {
+		add(ChangeAppFolderAdded, "app", "", "")
+	} else if old.HasAppFolder && !now.HasAppFolder {
+		add(ChangeAppFolderRemoved, "app", "", "")
+	}
+
+	return changes
+}
+
+// sortedKeys returns the union of the keys of two maps in sorted order
+func sortedKeys[V any](a map[string]V, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
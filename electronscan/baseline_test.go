@@ -0,0 +1,154 @@
+package electronscan_test
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestDiffSnapshotsPackedFileEdit(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "windows"}.Build(fsys, "Program Files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+	results := []electronscan.AppResult{s.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))}
+	baseline := s.NewSnapshot(results, "test")
+	if len(baseline.Apps) != 1 || baseline.Apps[0].AsarSHA256 == "" {
+		t.Fatalf("app.asar not hashed: %+v", baseline.Apps)
+	}
+	if changes := electronscan.DiffSnapshots(baseline, s.NewSnapshot(results, "test")); len(changes) != 0 {
+		t.Fatalf("unchanged app reported %+v", changes)
+	}
+
+	// Rewrite the last byte of packed file data, which leaves the header untouched
+	asar := fsys[path.Join(path.Dir(appPath), "resources", "app.asar")]
+	asar.Data = append([]byte(nil), asar.Data...)
+	asar.Data[len(asar.Data)-1] ^= 0xff
+
+	changes := electronscan.DiffSnapshots(baseline, s.NewSnapshot(results, "test"))
+	if len(changes) != 1 || changes[0].Kind != electronscan.ChangeAsar {
+		t.Errorf("changes = %+v, want one %s", changes, electronscan.ChangeAsar)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	baseline := &electronscan.Snapshot{Apps: []electronscan.AppSnapshot{
+		{Path: "/Applications/Gone.app", Version: "28.0.0"},
+		{
+			Path:             "/Applications/Demo.app",
+			Version:          "30.1.0",
+			ExecutableSHA256: "exe1",
+			AsarSHA256:       "asar1",
+			UnpackedFiles:    map[string]string{"a.node": "a1", "b.js": "b1", "c.js": "c1"},
+			NodeFiles:        map[string]string{"/x.node": "x1", "/y.node": "y1"},
+			Fuses:            map[string]string{"RunAsNode": electronscan.FuseDisabled, "OnlyLoadAppFromAsar": electronscan.FuseEnabled},
+		},
+		{Path: "/Applications/Same.app", Version: "31.0.0", HasAppFolder: true},
+	}}
+	current := &electronscan.Snapshot{Apps: []electronscan.AppSnapshot{
+		{Path: "/Applications/Same.app", Version: "31.0.0", HasAppFolder: true},
+		{Path: "/Applications/New.app", Version: "32.0.0"},
+		{
+			Path:             "/Applications/Demo.app",
+			Version:          "30.2.0",
+			Executable:       "/Applications/Demo.app/Contents/MacOS/Demo",
+			ExecutableSHA256: "exe2",
+			AsarSHA256:       "asar1",
+			UnpackedFiles:    map[string]string{"a.node": "a2", "c.js": "c1", "d.js": "d1"},
+			NodeFiles:        map[string]string{"/x.node": "x1", "/z.node": "z1"},
+			// A fuse missing from the new snapshot counts as flipped
+			Fuses:        map[string]string{"RunAsNode": electronscan.FuseEnabled},
+			HasAppFolder: true,
+		},
+	}}
+
+	got := electronscan.DiffSnapshots(baseline, current)
+	want := []electronscan.SnapshotChange{
+		{Kind: electronscan.ChangeVersion, App: "/Applications/Demo.app", Old: "30.1.0", New: "30.2.0"},
+		{Kind: electronscan.ChangeExecutable, App: "/Applications/Demo.app", Path: "/Applications/Demo.app/Contents/MacOS/Demo", Old: "exe1", New: "exe2"},
+		{Kind: electronscan.ChangeUnpackedModified, App: "/Applications/Demo.app", Path: "a.node", Old: "a1", New: "a2"},
+		{Kind: electronscan.ChangeUnpackedRemoved, App: "/Applications/Demo.app", Path: "b.js", Old: "b1"},
+		{Kind: electronscan.ChangeUnpackedAdded, App: "/Applications/Demo.app", Path: "d.js", New: "d1"},
+		{Kind: electronscan.ChangeNodeFileRemoved, App: "/Applications/Demo.app", Path: "/y.node", Old: "y1"},
+		{Kind: electronscan.ChangeNodeFileAdded, App: "/Applications/Demo.app", Path: "/z.node", New: "z1"},
+		{Kind: electronscan.ChangeFuseFlipped, App: "/Applications/Demo.app", Path: "OnlyLoadAppFromAsar", Old: electronscan.FuseEnabled},
+		{Kind: electronscan.ChangeFuseFlipped, App: "/Applications/Demo.app", Path: "RunAsNode", Old: electronscan.FuseDisabled, New: electronscan.FuseEnabled},
+		{Kind: electronscan.ChangeAppFolderAdded, App: "/Applications/Demo.app", Path: "app"},
+		{Kind: electronscan.ChangeAppRemoved, App: "/Applications/Gone.app", Old: "28.0.0"},
+		{Kind: electronscan.ChangeAppAdded, App: "/Applications/New.app", New: "32.0.0"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestSnapshotAppFiles(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "darwin", NativeModules: []string{"keytar"}}.Build(fsys, "Applications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys})
+	result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+	unrecognized := electronscan.AppResult{Path: string(filepath.Separator) + filepath.FromSlash("Applications/Notes.app")}
+	baseline := s.NewSnapshot([]electronscan.AppResult{unrecognized, result}, "test")
+	if len(baseline.Apps) != 1 {
+		t.Fatalf("snapshot has %d apps, want only the Electron one", len(baseline.Apps))
+	}
+	app := baseline.Apps[0]
+	if app.ExecutableSHA256 == "" || len(app.NodeFiles) != 1 || len(app.UnpackedFiles) == 0 {
+		t.Fatalf("snapshot = %+v", app)
+	}
+
+	// Planting a resources/app folder bypasses app.asar entirely
+	resources := path.Join(appPath, "Contents", "Resources")
+	fsys[path.Join(resources, "app", "main.js")] = &fstest.MapFile{Data: []byte("require('child_process')")}
+	fsys[path.Join(resources, "app.asar.unpacked", "evil.js")] = &fstest.MapFile{Data: []byte("x")}
+	var kinds []string
+	for _, change := range electronscan.DiffSnapshots(baseline, s.NewSnapshot([]electronscan.AppResult{result}, "test")) {
+		kinds = append(kinds, change.Kind+" "+change.Path)
+	}
+	if want := []string{electronscan.ChangeUnpackedAdded + " evil.js", electronscan.ChangeAppFolderAdded + " app"}; !slices.Equal(kinds, want) {
+		t.Errorf("changes = %q, want %q", kinds, want)
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := &electronscan.Snapshot{ScannerVersion: "test", OS: "darwin", Apps: []electronscan.AppSnapshot{{Path: "/Applications/Demo.app", AsarSHA256: "abc"}}}
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := filepath.Join(dir, "baseline.json")
+	if err := os.WriteFile(good, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	read, err := electronscan.ReadSnapshot(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := electronscan.DiffSnapshots(snapshot, read); len(changes) != 0 {
+		t.Errorf("round-tripped baseline differs: %+v", changes)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := electronscan.ReadSnapshot(bad); err == nil {
+		t.Error("truncated baseline was accepted")
+	}
+	if _, err := electronscan.ReadSnapshot(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing baseline was accepted")
+	}
+}
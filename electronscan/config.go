@@ -0,0 +1,239 @@
+package electronscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are tried in order in the config directory
+var configFileNames = []string{"config.json", "config.yaml", "config.yml"}
+
+// Config holds settings shared by a team. Command-line flags take precedence.
+type Config struct {
+	SearchRoots  []string      `json:"search_roots" yaml:"search_roots"`
+	Exclude      []Exclusion   `json:"exclude" yaml:"exclude"`
+	Format       string        `json:"format" yaml:"format"`
+	Workers      int           `json:"workers" yaml:"workers"`
+	MaxNodeFiles int           `json:"max_node_files" yaml:"max_node_files"`
+	MinSeverity  string        `json:"min_severity" yaml:"min_severity"`
+	Policy       string        `json:"policy" yaml:"policy"`
+	Suppressions []Suppression `json:"suppressions" yaml:"suppressions"`
+}
+
+// Exclusion skips applications by path glob (full path or bundle name) or bundle ID glob
+type Exclusion struct {
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	BundleID string `json:"bundle_id,omitempty" yaml:"bundle_id,omitempty"`
+}
+
+// Suppression accepts a known finding until it expires.
+// App and Path are globs against the full path or its base name; empty ones match everything.
+type Suppression struct {
+	Rule          string `json:"rule" yaml:"rule"`
+	App           string `json:"app,omitempty" yaml:"app,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"`
+	Expires       string `json:"expires" yaml:"expires"`
+	Justification string `json:"justification" yaml:"justification"`
+}
+
+// SuppressedFinding is a finding hidden by a suppression, kept in the output for auditing
+type SuppressedFinding struct {
+	Finding
+	Expires       string `json:"expires"`
+	Justification string `json:"justification"`
+}
+
+// DefaultConfigPath returns the first config file found in ~/.config/asarscan
+// (or $XDG_CONFIG_HOME/asarscan), or "" if there is none
+func DefaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	for _, name := range configFileNames {
+		candidate := filepath.Join(dir, "asarscan", name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads a .json, .yaml or .yml config file and validates it.
+// Relative paths in the file are resolved against its directory.
+func LoadConfig(configPath string) (*Config, error) {
+	var config Config
+	if err := decodeSettingsFile(configPath, &config); err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(configPath)
+	for i, root := range config.SearchRoots {
+		config.SearchRoots[i] = resolveConfigPath(baseDir, root)
+	}
+	if config.Policy != "" {
+		config.Policy = resolveConfigPath(baseDir, config.Policy)
+	}
+
+	for _, exclusion := range config.Exclude {
+		if exclusion.Path == "" && exclusion.BundleID == "" {
+			return nil, fmt.Errorf("config %s: exclusion needs a path or bundle_id", configPath)
+		}
+		for _, pattern := range []string{exclusion.Path, exclusion.BundleID} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("config %s: invalid pattern %q", configPath, pattern)
+			}
+		}
+	}
+
+	for _, suppression := range config.Suppressions {
+		if _, ok := RuleByID(suppression.Rule); !ok {
+			return nil, fmt.Errorf("config %s: suppression for unknown rule %q", configPath, suppression.Rule)
+		}
+		if strings.TrimSpace(suppression.Justification) == "" {
+			return nil, fmt.Errorf("config %s: suppression for %s needs a justification", configPath, suppression.Rule)
+		}
+		if _, err := time.Parse(time.DateOnly, suppression.Expires); err != nil {
+			return nil, fmt.Errorf("config %s: suppression for %s needs an expires date (YYYY-MM-DD)", configPath, suppression.Rule)
+		}
+	}
+
+	if config.Workers < 0 || config.MaxNodeFiles < 0 {
+		return nil, fmt.Errorf("config %s: workers and max_node_files cannot be negative", configPath)
+	}
+	if config.MinSeverity != "" && !ValidSeverity(config.MinSeverity) {
+		return nil, fmt.Errorf("config %s: unknown min_severity %q", configPath, config.MinSeverity)
+	}
+
+	return &config, nil
+}
+
+// decodeSettingsFile decodes JSON or YAML by file extension.
+// Unknown keys are rejected so a misspelled setting cannot silently be ignored.
+func decodeSettingsFile(filePath string, v any) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", filePath, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(v)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(v)
+	}
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("error parsing %s: %v", filePath, err)
+	}
+	return nil
+}
+
+// resolveConfigPath expands a leading ~ and makes relative paths relative to baseDir
+func resolveConfigPath(baseDir string, p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(baseDir, p)
+	}
+	return filepath.Clean(p)
+}
+
+// isExcluded reports whether an application matches one of the exclusions.
+// The bundle identifier is only read when an exclusion needs it.
+func (s *Scanner) isExcluded(appPath string) bool {
+	for _, exclusion := range s.opts.Exclude {
+		if exclusion.Path != "" && matchAppGlob(exclusion.Path, appPath) {
+			return true
+		}
+		if exclusion.BundleID != "" && matchBundleID(exclusion.BundleID, s.GetBundleIdentifier(appPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiredSuppressions returns the suppressions that no longer apply
+func (c *Config) ExpiredSuppressions(now time.Time) []Suppression {
+	var expired []Suppression
+	for _, suppression := range c.Suppressions {
+		if !suppression.active(now) {
+			expired = append(expired, suppression)
+		}
+	}
+	return expired
+}
+
+// active reports whether the suppression has not expired yet; it lasts through its expiry day
+func (s Suppression) active(now time.Time) bool {
+	expires, err := time.ParseInLocation(time.DateOnly, s.Expires, now.Location())
+	return err == nil && now.Before(expires.AddDate(0, 0, 1))
+}
+
+// matches reports whether the suppression covers a finding of an application
+func (s Suppression) matches(result *AppResult, finding Finding) bool {
+	if s.Rule != finding.RuleID {
+		return false
+	}
+	if s.App != "" && !matchAppGlob(s.App, result.Path) && !matchBundleID(s.App, result.BundleID) {
+		return false
+	}
+	return s.Path == "" || matchAppGlob(s.Path, finding.Path)
+}
+
+// ApplySuppressions moves the findings covered by an active suppression into
+// result.SuppressedFindings, which Findings then leaves out
+func ApplySuppressions(result *AppResult, suppressions []Suppression, now time.Time) {
+	if len(suppressions) == 0 {
+		return
+	}
+	for _, finding := range allFindings(*result) {
+		for _, suppression := range suppressions {
+			if suppression.active(now) && suppression.matches(result, finding) {
+				result.SuppressedFindings = append(result.SuppressedFindings, SuppressedFinding{
+					Finding:       finding,
+					Expires:       suppression.Expires,
+					Justification: suppression.Justification,
+				})
+				break
+			}
+		}
+	}
+}
+
+// matchAppGlob matches a glob against a full path or its base name
+func matchAppGlob(pattern string, appPath string) bool {
+	if matched, _ := path.Match(filepath.ToSlash(pattern), filepath.ToSlash(appPath)); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, filepath.Base(appPath))
+	return matched
+}
+
+// matchBundleID matches a glob against the bundle identifier of a macOS app
+func matchBundleID(pattern string, bundleID string) bool {
+	if bundleID == "" {
+		return false
+	}
+	matched, _ := path.Match(pattern, bundleID)
+	return matched
+}
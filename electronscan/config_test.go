@@ -0,0 +1,219 @@
+package electronscan_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// writeConfig writes a config file named name into a new directory
+func writeConfig(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigResolvesPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	absolute := filepath.Join(t.TempDir(), "Applications")
+	path := writeConfig(t, "config.yaml", `search_roots:
+  - apps
+  - ../shared/apps
+  - ~/Applications
+  - `+absolute+`
+policy: policy.yml
+`)
+	config, err := electronscan.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Dir(path)
+	want := []string{
+		filepath.Join(dir, "apps"),
+		filepath.Join(filepath.Dir(dir), "shared", "apps"),
+		filepath.Join(home, "Applications"),
+		absolute,
+	}
+	if !slices.Equal(config.SearchRoots, want) {
+		t.Errorf("search roots = %q, want %q", config.SearchRoots, want)
+	}
+	if config.Policy != filepath.Join(dir, "policy.yml") {
+		t.Errorf("policy = %q, want it next to the config", config.Policy)
+	}
+}
+
+func TestLoadConfigRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		config string
+		err    string
+	}{
+		{"misspelled YAML key", "config.yaml", "max_nodefiles: 5\n", "max_nodefiles"},
+		{"misspelled JSON key", "config.json", `{"workers": 2, "format ": "json"}`, "format "},
+		{"empty exclusion", "config.yaml", "exclude:\n  - {}\n", "needs a path or bundle_id"},
+		{"invalid glob", "config.yaml", "exclude:\n  - bundle_id: \"com.[example\"\n", "invalid pattern"},
+		{"unknown rule", "config.yaml", "suppressions:\n  - {rule: run-as-node, expires: 2099-01-01, justification: debug}\n", `unknown rule "run-as-node"`},
+		{"no justification", "config.yaml", "suppressions:\n  - {rule: run-as-node-enabled, expires: 2099-01-01, justification: \" \"}\n", "needs a justification"},
+		{"no expiry", "config.yaml", "suppressions:\n  - {rule: run-as-node-enabled, justification: debug}\n", "needs an expires date"},
+		{"expiry not a date", "config.yaml", "suppressions:\n  - {rule: run-as-node-enabled, expires: next year, justification: debug}\n", "needs an expires date"},
+		{"negative workers", "config.yml", "workers: -1\n", "cannot be negative"},
+		{"unknown severity", "config.json", `{"min_severity": "severe"}`, `unknown min_severity "severe"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := electronscan.LoadConfig(writeConfig(t, tt.file, tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if got := electronscan.DefaultConfigPath(); got != "" {
+		t.Errorf("path without a config = %q", got)
+	}
+
+	// config.json wins over the YAML names
+	if err := os.MkdirAll(filepath.Join(dir, "asarscan"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config.yml", "config.json"} {
+		if err := os.WriteFile(filepath.Join(dir, "asarscan", name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := electronscan.DefaultConfigPath(), filepath.Join(dir, "asarscan", "config.json"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestApplySuppressions(t *testing.T) {
+	exe := "/Applications/Demo.app/Contents/MacOS/Demo"
+	result := electronscan.AppResult{
+		Path:       "/Applications/Demo.app",
+		BundleID:   "com.example.demo",
+		IsElectron: true,
+		Executable: exe,
+		Fuses: map[string]string{
+			"RunAsNode":                     electronscan.FuseEnabled,
+			"EnableNodeCliInspectArguments": electronscan.FuseEnabled,
+		},
+	}
+	expiry := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		suppression electronscan.Suppression
+		now         time.Time
+		suppressed  bool
+	}{
+		{"any app", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled}, expiry, true},
+		{"last minute of the expiry day", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled}, expiry.Add(24*time.Hour - time.Minute), true},
+		{"day after expiry", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled}, expiry.AddDate(0, 0, 1), false},
+		{"bundle name glob", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, App: "Demo*.app"}, expiry, true},
+		{"full path glob", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, App: "/Applications/*.app"}, expiry, true},
+		{"bundle ID glob", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, App: "com.example.*"}, expiry, true},
+		{"other app", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, App: "Other.app"}, expiry, false},
+		{"finding path glob", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, Path: "/Applications/*.app/Contents/MacOS/*"}, expiry, true},
+		{"other finding path", electronscan.Suppression{Rule: electronscan.RuleRunAsNodeEnabled, Path: "*.node"}, expiry, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.suppression.Expires = expiry.Format(time.DateOnly)
+			tt.suppression.Justification = "accepted"
+			result := result
+			electronscan.ApplySuppressions(&result, []electronscan.Suppression{tt.suppression}, tt.now)
+
+			var active []string
+			for _, finding := range electronscan.Findings(result) {
+				active = append(active, finding.RuleID)
+			}
+			want := []string{electronscan.RuleRunAsNodeEnabled, electronscan.RuleNodeCliInspectEnabled}
+			if tt.suppressed {
+				want = want[1:]
+				if len(result.SuppressedFindings) != 1 || result.SuppressedFindings[0].Justification != "accepted" {
+					t.Errorf("suppressed = %+v", result.SuppressedFindings)
+				}
+			}
+			if !slices.Equal(active, want) {
+				t.Errorf("active findings = %q, want %q", active, want)
+			}
+		})
+	}
+
+	// Overlapping suppressions record a finding once
+	overlapping := result
+	electronscan.ApplySuppressions(&overlapping, []electronscan.Suppression{
+		{Rule: electronscan.RuleRunAsNodeEnabled, Expires: "2099-01-01", Justification: "first"},
+		{Rule: electronscan.RuleRunAsNodeEnabled, App: "Demo.app", Expires: "2099-01-01", Justification: "second"},
+	}, expiry)
+	if len(overlapping.SuppressedFindings) != 1 || overlapping.SuppressedFindings[0].Justification != "first" {
+		t.Errorf("suppressed = %+v, want only the first match", overlapping.SuppressedFindings)
+	}
+
+	config := electronscan.Config{Suppressions: []electronscan.Suppression{
+		{Rule: electronscan.RuleRunAsNodeEnabled, Expires: "2026-06-29"},
+		{Rule: electronscan.RuleNodeCliInspectEnabled, Expires: "2026-06-30"},
+	}}
+	if expired := config.ExpiredSuppressions(expiry); len(expired) != 1 || expired[0].Expires != "2026-06-29" {
+		t.Errorf("expired = %+v, want the one from the day before", expired)
+	}
+}
+
+func TestDiscoverExclusions(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, app := range []fixture.App{
+		{Name: "Demo"},
+		{Name: "Demo Beta", BundleID: "com.example.demo.beta"},
+		{Name: "Chat", BundleID: "org.example.chat"},
+	} {
+		if _, err := app.Build(fsys, "Applications"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		exclude []electronscan.Exclusion
+		want    []string
+	}{
+		{"none", nil, []string{"Chat.app", "Demo Beta.app", "Demo.app"}},
+		{"bundle name", []electronscan.Exclusion{{Path: "Demo*.app"}}, []string{"Chat.app"}},
+		{"full path", []electronscan.Exclusion{{Path: "/Applications/Chat.app"}}, []string{"Demo Beta.app", "Demo.app"}},
+		{"bundle ID", []electronscan.Exclusion{{BundleID: "com.example.demo.*"}}, []string{"Chat.app", "Demo.app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps, err := electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys, Exclude: tt.exclude}).Discover()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, app := range apps {
+				names = append(names, filepath.Base(app))
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("apps = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
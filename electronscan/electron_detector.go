@@ -0,0 +1,474 @@
+package electronscan
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IsElectronApp checks if the given path is an Electron application
+func (s *Scanner) IsElectronApp(appPath string) (bool, string, error) {
+	s.logger.Debug("Checking if app is an Electron app", "app", appPath)
+
+	switch s.goos {
+	case "darwin":
+		return s.isElectronAppMacos(appPath)
+	case "windows":
+		return s.isElectronAppWindows(appPath)
+	case "linux":
+		return s.isElectronAppLinux(appPath)
+	default:
+		return false, "", errors.New("unsupported operating system")
+	}
+}
+
+// isElectronAppMacos checks if the given path is an Electron application on macOS
+func (s *Scanner) isElectronAppMacos(appPath string) (bool, string, error) {
+	// Check for app bundle structure
+	if !strings.HasSuffix(appPath, ".app") {
+		s.logger.Debug("Not an app bundle", "app", appPath)
+		return false, "", nil
+	}
+
+	// Look for the Info.plist
+	plistPath := filepath.Join(appPath, "Contents", "Info.plist")
+	if _, err := s.stat(plistPath); os.IsNotExist(err) {
+		s.logger.Debug("No Info.plist found", "path", plistPath)
+		return false, "", nil
+	}
+
+	// Check for the Electron framework
+	frameworkPath := filepath.Join(appPath, "Contents", "Frameworks", "Electron Framework.framework")
+	if _, err := s.stat(frameworkPath); err == nil {
+		s.logger.Debug("Found Electron Framework", "path", frameworkPath)
+
+		// Try to extract Electron version from Info.plist
+		version := "unknown"
+		plistContent, err := s.readPlist(plistPath)
+		if err == nil {
+			plistStr := string(plistContent)
+
+			// Try to find version-like strings
+			versionRegexes := []string{
+				`<key>ElectronVersion</key>\s*<string>([0-9.]+)`,
+				`<key>CFBundleVersion</key>\s*<string>([0-9.]+)`,
+				`Electron/([0-9.]+)`,
+				`electron@([0-9.]+)`,
+				`electron": "([^"]+)"`,
+				`"electronVersion": "([^"]+)"`,
+			}
+
+			for _, regex := range versionRegexes {
+				re := regexp.MustCompile(regex)
+				matches := re.FindStringSubmatch(plistStr)
+				if len(matches) > 1 {
+					s.logger.Debug("Found Electron version", "version", matches[1])
+					version = matches[1]
+					break
+				}
+			}
+
+			// Also check the framework's Info.plist
+			frameworkPlistPath := filepath.Join(frameworkPath, "Resources", "Info.plist")
+			if _, err := s.stat(frameworkPlistPath); err == nil {
+				s.logger.Debug("Checking Electron framework Info.plist for version")
+				frameworkPlist, err := s.readPlist(frameworkPlistPath)
+				if err == nil {
+					for _, regex := range versionRegexes {
+						re := regexp.MustCompile(regex)
+						matches := re.FindStringSubmatch(string(frameworkPlist))
+						if len(matches) > 1 {
+							s.logger.Debug("Found Electron version in framework", "version", matches[1])
+							version = matches[1]
+							break
+						}
+					}
+				}
+			}
+		}
+
+		return true, version, nil
+	}
+
+	// Check for app.asar file
+	asarPath := filepath.Join(appPath, "Contents", "Resources", "app.asar")
+	if _, err := s.stat(asarPath); err == nil {
+		s.logger.Debug("Found app.asar", "path", asarPath)
+
+		// Try to extract version from package.json if it exists
+		version := "unknown"
+		packageJsonPath := filepath.Join(appPath, "Contents", "Resources", "app", "package.json")
+		if _, err := s.stat(packageJsonPath); err == nil {
+			s.logger.Debug("Found package.json, checking for Electron version")
+
+			packageContent, err := s.readFile(packageJsonPath)
+			if err == nil {
+				// Simple regex to find electron version in package.json
+				re := regexp.MustCompile(`"electron":\s*"([^"]+)"`)
+				matches := re.FindStringSubmatch(string(packageContent))
+				if len(matches) > 1 {
+					s.logger.Debug("Found Electron version in package.json", "version", matches[1])
+					version = matches[1]
+				}
+			}
+		}
+
+		return true, version, nil
+	}
+
+	return false, "", nil
+}
+
+// isElectronAppWindows checks if the given path is an Electron application on Windows
+func (s *Scanner) isElectronAppWindows(appPath string) (bool, string, error) {
+	// Check for common Electron files
+	exePath := appPath
+	if !strings.HasSuffix(exePath, ".exe") {
+		exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+	}
+
+	if _, err := s.stat(exePath); os.IsNotExist(err) {
+		s.logger.Debug("No executable found", "path", exePath)
+		return false, "", nil
+	}
+
+	// Check for resources directory
+	resourcesDir := filepath.Join(filepath.Dir(exePath), "resources")
+	if _, err := s.stat(resourcesDir); os.IsNotExist(err) {
+		s.logger.Debug("No resources directory found", "path", resourcesDir)
+		return false, "", nil
+	}
+
+	// Check for app.asar file
+	asarPath := filepath.Join(resourcesDir, "app.asar")
+	if _, err := s.stat(asarPath); err == nil {
+		s.logger.Debug("Found app.asar", "path", asarPath)
+
+		// Try to extract version from package.json if it exists
+		version := "unknown"
+		packageJsonPath := filepath.Join(resourcesDir, "app", "package.json")
+		if _, err := s.stat(packageJsonPath); err == nil {
+			s.logger.Debug("Found package.json, checking for Electron version")
+
+			packageContent, err := s.readFile(packageJsonPath)
+			if err == nil {
+				// Look for electron in dependencies or devDependencies
+				re := regexp.MustCompile(`"electron":\s*"([^"]+)"`)
+				matches := re.FindStringSubmatch(string(packageContent))
+				if len(matches) > 1 {
+					s.logger.Debug("Found Electron version in package.json", "version", matches[1])
+					version = matches[1]
+				} else {
+					// Try to find electronVersion
+					re = regexp.MustCompile(`"electronVersion":\s*"([^"]+)"`)
+					matches = re.FindStringSubmatch(string(packageContent))
+					if len(matches) > 1 {
+						s.logger.Debug("Found electronVersion in package.json", "version", matches[1])
+						version = matches[1]
+					}
+				}
+			}
+		} else {
+			// Check if there's version info in the executable
+			exeContent, err := s.readFile(exePath)
+			if err == nil {
+				// Look for patterns like Electron/X.Y.Z
+				re := regexp.MustCompile(`Electron/([0-9.]+)`)
+				matches := re.FindStringSubmatch(string(exeContent))
+				if len(matches) > 1 {
+					s.logger.Debug("Found Electron version in executable", "version", matches[1])
+					version = matches[1]
+				} else {
+					// Look for other common patterns
+					versionPatterns := []string{
+						`electron@([0-9.]+)`,
+						`"electron": "([^"]+)"`,
+						`"electronVersion": "([^"]+)"`,
+					}
+
+					for _, pattern := range versionPatterns {
+						re := regexp.MustCompile(pattern)
+						matches := re.FindStringSubmatch(string(exeContent))
+						if len(matches) > 1 {
+							s.logger.Debug("Found Electron version pattern in executable", "version", matches[1])
+							version = matches[1]
+							break
+						}
+					}
+				}
+			}
+		}
+
+		return true, version, nil
+	}
+
+	// Look for electron.asar which is common in Electron apps
+	electronAsarPath := filepath.Join(resourcesDir, "electron.asar")
+	if _, err := s.stat(electronAsarPath); err == nil {
+		s.logger.Debug("Found electron.asar", "path", electronAsarPath)
+
+		// Try to find version in the electron.asar metadata
+		version := "unknown"
+		// Check executable for version info
+		exeContent, err := s.readFile(exePath)
+		if err == nil {
+			// Look for patterns like Electron/X.Y.Z
+			re := regexp.MustCompile(`Electron/([0-9.]+)`)
+			matches := re.FindStringSubmatch(string(exeContent))
+			if len(matches) > 1 {
+				s.logger.Debug("Found Electron version in executable", "version", matches[1])
+				version = matches[1]
+			}
+		}
+
+		return true, version, nil
+	}
+
+	return false, "", nil
+}
+
+// isElectronAppLinux checks if the given path is an Electron application on Linux
+func (s *Scanner) isElectronAppLinux(appPath string) (bool, string, error) {
+	exePath := s.linuxExecutable(appPath)
+	if exePath == "" || !s.exists(exePath) {
+		s.logger.Debug("No executable found", "app", appPath)
+		return false, "", nil
+	}
+
+	// Electron apps keep their code in resources/app.asar, or only ship
+	// electron.asar when the code lives in resources/app
+	resourcesDir := filepath.Join(filepath.Dir(exePath), "resources")
+	if !s.exists(filepath.Join(resourcesDir, "app.asar")) && !s.exists(filepath.Join(resourcesDir, "electron.asar")) {
+		s.logger.Debug("No asar archive found", "path", resourcesDir)
+		return false, "", nil
+	}
+
+	// Electron is linked into the executable along with its version string
+	version := "unknown"
+	if exeContent, err := s.readFile(exePath); err == nil {
+		if matches := electronVersionRegex.FindSubmatch(exeContent); len(matches) > 1 {
+			s.logger.Debug("Found Electron version in executable", "version", string(matches[1]))
+			version = string(matches[1])
+		}
+	}
+	return true, version, nil
+}
+
+// electronVersionRegex matches the Electron version compiled into its user agent
+var electronVersionRegex = regexp.MustCompile(`Electron/([0-9.]+)`)
+
+// Executables shipped next to the main one by Linux Electron builds
+var linuxHelperExecutables = map[string]bool{
+	"chrome-sandbox":          true,
+	"chrome_crashpad_handler": true,
+	"crashpad_handler":        true,
+}
+
+// linuxInstallDir returns the install directory of a Linux application given
+// either its executable or the directory itself
+func (s *Scanner) linuxInstallDir(appPath string) string {
+	if info, err := s.stat(appPath); err == nil && info.IsDir() {
+		return appPath
+	}
+	return filepath.Dir(appPath)
+}
+
+// linuxExecutable returns the executable of a Linux application given either
+// the executable or its install directory, or "" if there is none
+func (s *Scanner) linuxExecutable(appPath string) string {
+	if info, err := s.stat(appPath); err == nil && info.IsDir() {
+		return s.linuxMainExecutable(appPath)
+	}
+	return appPath
+}
+
+// linuxMainExecutable picks the application executable in a Linux install
+// directory: the largest executable file that is neither a shared library nor
+// a Chromium helper, since Electron itself is linked into it
+func (s *Scanner) linuxMainExecutable(dir string) string {
+	entries, err := s.readDir(dir)
+	if err != nil {
+		return ""
+	}
+	var executable string
+	var largest int64 = -1
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || linuxHelperExecutables[name] || strings.Contains(name, ".so") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		if info.Size() > largest {
+			executable, largest = filepath.Join(dir, name), info.Size()
+		}
+	}
+	return executable
+}
+
+// GetResourcesPath returns the path to the resources directory of an Electron application
+func (s *Scanner) GetResourcesPath(appPath string) string {
+	switch s.goos {
+	case "darwin":
+		return filepath.Join(appPath, "Contents", "Resources")
+	case "windows":
+		exePath := appPath
+		if !strings.HasSuffix(exePath, ".exe") {
+			exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+		}
+		return filepath.Join(filepath.Dir(exePath), "resources")
+	case "linux":
+		return filepath.Join(s.linuxInstallDir(appPath), "resources")
+	default:
+		return ""
+	}
+}
+
+// GetExecutablePath returns the main executable of an Electron application
+func (s *Scanner) GetExecutablePath(appPath string) string {
+	switch s.goos {
+	case "darwin":
+		return s.GetBundleExecutable(appPath)
+	case "windows":
+		if strings.HasSuffix(appPath, ".exe") {
+			return appPath
+		}
+		return filepath.Join(appPath, filepath.Base(appPath)+".exe")
+	case "linux":
+		return s.linuxExecutable(appPath)
+	default:
+		return ""
+	}
+}
+
+// GetAsarPath returns the path to the app.asar file for an Electron application
+func (s *Scanner) GetAsarPath(appPath string) string {
+	resourcesDir := s.GetResourcesPath(appPath)
+	if resourcesDir == "" {
+		return ""
+	}
+	return filepath.Join(resourcesDir, "app.asar")
+}
+
+// bundleIdentifierRegex extracts CFBundleIdentifier from an XML Info.plist
+var bundleIdentifierRegex = regexp.MustCompile(`<key>CFBundleIdentifier</key>\s*<string>([^<]+)</string>`)
+
+// GetBundleIdentifier returns the CFBundleIdentifier of a macOS app bundle, or "" if unknown
+func (s *Scanner) GetBundleIdentifier(appPath string) string {
+	plistContent, err := s.readPlist(filepath.Join(appPath, "Contents", "Info.plist"))
+	if err != nil {
+		return ""
+	}
+	matches := bundleIdentifierRegex.FindSubmatch(plistContent)
+	if len(matches) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(string(matches[1]))
+}
+
+// HasAsarFile checks if the app has an app.asar file
+func (s *Scanner) HasAsarFile(appPath string) bool {
+	asarPath := s.GetAsarPath(appPath)
+	_, err := s.stat(asarPath)
+	return err == nil
+}
+
+// nodeSearchRoots returns the directories searched for .node files and the
+// directory holding the main executable, or nil on unsupported systems
+func (s *Scanner) nodeSearchRoots(appPath string) ([]string, string) {
+	switch s.goos {
+	case "darwin":
+		// For macOS, search in the main app resources
+		return []string{
+			filepath.Join(appPath, "Contents", "Resources"),
+			filepath.Join(appPath, "Contents", "Frameworks"),
+		}, filepath.Join(appPath, "Contents", "MacOS")
+	case "windows":
+		// For Windows, search in the app directory and resources
+		exePath := appPath
+		if !strings.HasSuffix(exePath, ".exe") {
+			exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+		}
+		dirPath := filepath.Dir(exePath)
+		return []string{
+			dirPath,
+			filepath.Join(dirPath, "resources"),
+		}, dirPath
+	case "linux":
+		// For Linux, search in the install directory and resources
+		dirPath := s.linuxInstallDir(appPath)
+		return []string{
+			dirPath,
+			filepath.Join(dirPath, "resources"),
+		}, dirPath
+	default:
+		return nil, ""
+	}
+}
+
+// FindNodeFiles finds .node files in an Electron application and inventories them
+// maxFiles specifies the maximum number of files to return (0 for unlimited)
+func (s *Scanner) FindNodeFiles(appPath string, maxFiles int) []NativeModule {
+	var nodeFiles []NativeModule
+
+	searchRoots, executableDir := s.nodeSearchRoots(appPath)
+	if searchRoots == nil {
+		s.logger.Debug("Unsupported OS for .node file search", "os", s.goos)
+		return nodeFiles
+	}
+
+	// The asar header tells us which files were deliberately unpacked
+	resourcesDir := s.GetResourcesPath(appPath)
+	archive, err := s.ReadAsarArchive(s.GetAsarPath(appPath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.logger.Warn("Error reading asar header", "app", appPath, "error", err)
+		}
+		archive = nil
+	}
+
+	// Search roots can overlap on Windows, so only inspect each file once
+	seen := make(map[string]bool)
+
+	// Search each root directory
+	for _, root := range searchRoots {
+		s.logger.Debug("Searching for .node files", "root", root)
+
+		err := s.walk(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				s.logger.Debug("Error accessing path", "path", path, "error", err)
+				return filepath.SkipDir
+			}
+
+			// Check if it's a .node file
+			if !d.IsDir() && strings.HasSuffix(strings.ToLower(d.Name()), ".node") && !seen[path] {
+				seen[path] = true
+				s.logger.Debug("Found .node file", "path", path)
+				nodeFiles = append(nodeFiles, s.inspectNativeModule(path, root, resourcesDir, executableDir, archive))
+
+				// Check if we've reached the maximum number of files
+				if maxFiles > 0 && len(nodeFiles) >= maxFiles {
+					return errors.New("max files reached")
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			s.logger.Debug("Error walking the path", "root", root, "error", err)
+		}
+
+		// Stop if we've reached the maximum number of files
+		if maxFiles > 0 && len(nodeFiles) >= maxFiles {
+			break
+		}
+	}
+
+	return nodeFiles
+}
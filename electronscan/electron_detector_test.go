@@ -0,0 +1,89 @@
+package electronscan_test
+
+import (
+	"cmp"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// installDirs are where fixture applications are built for each layout
+var installDirs = map[string]string{"darwin": "Applications", "windows": "Program Files", "linux": "opt"}
+
+// buildFixture builds app into a new MapFS and returns a scanner reading it
+// along with the OS path of the application
+func buildFixture(t *testing.T, app fixture.App) (*electronscan.Scanner, string) {
+	t.Helper()
+	goos := cmp.Or(app.GOOS, "darwin")
+	fsys := fstest.MapFS{}
+	appPath, err := app.Build(fsys, installDirs[goos])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return electronscan.New(electronscan.Options{GOOS: goos, FS: fsys}), string(filepath.Separator) + filepath.FromSlash(appPath)
+}
+
+func TestCheckAppDetectsElectron(t *testing.T) {
+	tests := []struct {
+		name     string
+		app      fixture.App
+		version  string
+		bundleID string
+	}{
+		{"macOS XML plist", fixture.App{GOOS: "darwin"}, fixture.DefaultElectronVersion, "com.example.demo"},
+		{"macOS binary plist", fixture.App{GOOS: "darwin", BinaryPlist: true, BundleID: "com.example.binary"}, fixture.DefaultElectronVersion, "com.example.binary"},
+		{"macOS older Electron", fixture.App{GOOS: "darwin", ElectronVersion: "22.3.27"}, "22.3.27", "com.example.demo"},
+		{"Windows", fixture.App{GOOS: "windows"}, fixture.DefaultElectronVersion, ""},
+		{"Windows older Electron", fixture.App{GOOS: "windows", ElectronVersion: "22.3.27"}, "22.3.27", ""},
+		{"Linux", fixture.App{GOOS: "linux", ElectronVersion: "22.3.27"}, "22.3.27", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, appPath := buildFixture(t, tt.app)
+			result := s.CheckApp(appPath)
+			if !result.IsElectron || result.Framework != electronscan.FrameworkElectron {
+				t.Fatalf("is electron = %t, framework = %q", result.IsElectron, result.Framework)
+			}
+			if result.Version != tt.version {
+				t.Errorf("electron version = %q, want %q", result.Version, tt.version)
+			}
+			if result.AppVersion != fixture.DefaultVersion {
+				t.Errorf("app version = %q, want %q", result.AppVersion, fixture.DefaultVersion)
+			}
+			if result.BundleID != tt.bundleID {
+				t.Errorf("bundle id = %q, want %q", result.BundleID, tt.bundleID)
+			}
+			if !result.HasAsarFile || result.Executable == "" || result.ResourcesDir == "" {
+				t.Errorf("asar = %t, executable = %q, resources = %q", result.HasAsarFile, result.Executable, result.ResourcesDir)
+			}
+		})
+	}
+}
+
+func TestCheckAppIgnoresOtherApps(t *testing.T) {
+	tests := []struct {
+		goos    string
+		appPath string
+		fsys    fstest.MapFS
+	}{
+		{"darwin", "Applications/Notes.app", fstest.MapFS{
+			"Applications/Notes.app/Contents/Info.plist":  {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict><key>CFBundleExecutable</key><string>Notes</string></dict></plist>`)},
+			"Applications/Notes.app/Contents/MacOS/Notes": {Data: []byte("not electron"), Mode: 0o755},
+		}},
+		{"windows", "Program Files/Notes/notes.exe", fstest.MapFS{
+			"Program Files/Notes/notes.exe": {Data: []byte("MZ not electron"), Mode: 0o755},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			s := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: tt.fsys})
+			result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(tt.appPath))
+			if result.IsElectron || result.Framework != "" || result.Risk != nil {
+				t.Errorf("is electron = %t, framework = %q, risk = %v", result.IsElectron, result.Framework, result.Risk)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package electronscan
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// electronSupportedMajors is how many of the latest stable majors receive security fixes
+const electronSupportedMajors = 3
+
+// electronReleaseCadence is the time between major releases since Electron 15
+const electronReleaseCadence = 8 * 7 * 24 * time.Hour
+
+// electronReleaseDates holds the stable release date of recent majors.
+// Later majors are extrapolated from the last entry using the release cadence.
+var electronReleaseDates = map[int]string{
+	22: "2022-11-29",
+	23: "2023-02-07",
+	24: "2023-04-04",
+	25: "2023-05-30",
+	26: "2023-08-15",
+	27: "2023-10-10",
+	28: "2023-12-05",
+	29: "2024-02-20",
+	30: "2024-04-16",
+	31: "2024-06-11",
+	32: "2024-08-20",
+	33: "2024-10-15",
+	34: "2025-01-14",
+}
+
+// lastKnownElectronMajor is the highest key of electronReleaseDates
+const lastKnownElectronMajor = 34
+
+// electronMajor parses the major version from strings like "27.0.2" or "v31.1.0+wvcus"
+func electronMajor(version string) (int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	majorStr, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major <= 0 {
+		return 0, false
+	}
+	return major, true
+}
+
+// compareVersions compares dotted numeric versions such as "30.1.2" and "30",
+// ignoring a leading "v" and any pre-release or build suffix. Missing parts count as zero.
+func compareVersions(a string, b string) int {
+	parse := func(version string) []int {
+		version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+		if i := strings.IndexAny(version, "-+"); i >= 0 {
+			version = version[:i]
+		}
+		var parts []int
+		for _, part := range strings.Split(version, ".") {
+			n, _ := strconv.Atoi(part)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// electronReleaseDate returns the (possibly estimated) stable release date of a major.
+// Majors before the table are reported as the zero time.
+func electronReleaseDate(major int) time.Time {
+	if date, ok := electronReleaseDates[major]; ok {
+		released, _ := time.Parse("2006-01-02", date)
+		return released
+	}
+	if major < lastKnownElectronMajor {
+		return time.Time{}
+	}
+	last := electronReleaseDate(lastKnownElectronMajor)
+	return last.Add(time.Duration(major-lastKnownElectronMajor) * electronReleaseCadence)
+}
+
+// latestElectronMajor estimates the newest stable major released by now
+func latestElectronMajor(now time.Time) int {
+	major := lastKnownElectronMajor
+	for !electronReleaseDate(major + 1).After(now) {
+		major++
+	}
+	return major
+}
+
+// ElectronSupported reports whether an Electron version is still among the
+// supported majors at the given time. ok is false if the version cannot be parsed.
+func ElectronSupported(version string, now time.Time) (supported bool, released time.Time, ok bool) {
+	major, ok := electronMajor(version)
+	if !ok {
+		return false, time.Time{}, false
+	}
+	return major > latestElectronMajor(now)-electronSupportedMajors, electronReleaseDate(major), true
+}
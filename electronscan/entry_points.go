@@ -0,0 +1,372 @@
+package electronscan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxScannedScripts bounds how many main process files are followed through require()
+const maxScannedScripts = 64
+
+// ScriptInfo describes a JavaScript file loaded by an application
+type ScriptInfo struct {
+	Path     string `json:"path"`
+	Location string `json:"location"`
+	SHA256   string `json:"sha256,omitempty"`
+	Missing  bool   `json:"missing,omitempty"`
+	Writable bool   `json:"writable"`
+}
+
+// WebPreference is a security-relevant webPreferences setting found in main process code
+type WebPreference struct {
+	Setting string `json:"setting"`
+	Value   string `json:"value"`
+	Script  string `json:"script"`
+}
+
+// EntryPoint describes the code an Electron application runs at startup
+type EntryPoint struct {
+	Source         string          `json:"source"`
+	Main           ScriptInfo      `json:"main"`
+	Preloads       []ScriptInfo    `json:"preloads,omitempty"`
+	WebPreferences []WebPreference `json:"web_preferences,omitempty"`
+}
+
+var (
+	requireRegex       = regexp.MustCompile(`(?:require\(\s*|import\(\s*|from\s+)['"](\.{1,2}/[^'"]+)['"]`)
+	preloadRegex       = regexp.MustCompile(`\bpreload\s*:\s*`)
+	stringLiteralRegex = regexp.MustCompile("['\"`]([^'\"`]*)['\"`]")
+	templateExprRegex  = regexp.MustCompile(`\$\{[^}]*\}`)
+	webPreferenceRegex = regexp.MustCompile(`\b(nodeIntegration|nodeIntegrationInWorker|nodeIntegrationInSubFrames|contextIsolation|sandbox|webSecurity|allowRunningInsecureContent|enableRemoteModule|experimentalFeatures)\s*:\s*(true|false|!0|!1)\b`)
+)
+
+// insecureWebPreferences lists the value of each setting that weakens the renderer
+var insecureWebPreferences = map[string]string{
+	"nodeIntegration":             "true",
+	"nodeIntegrationInWorker":     "true",
+	"nodeIntegrationInSubFrames":  "true",
+	"contextIsolation":            "false",
+	"sandbox":                     "false",
+	"webSecurity":                 "false",
+	"allowRunningInsecureContent": "true",
+	"enableRemoteModule":          "true",
+	"experimentalFeatures":        "true",
+}
+
+// appSource reads application code from app.asar or the resources/app folder
+type appSource struct {
+	scanner *Scanner
+	kind    string
+	dir     string
+	archive *AsarArchive
+}
+
+// diskPath returns where a source file lives on disk, or "" if it is packed in the asar
+func (s *appSource) diskPath(name string) string {
+	if s.kind == "folder" {
+		return filepath.Join(s.dir, filepath.FromSlash(name))
+	}
+	if entry := s.archive.Lookup(name); entry != nil && entry.Unpacked {
+		return s.archive.UnpackedPath(name)
+	}
+	return ""
+}
+
+// read returns the contents of a source file along with where it was found
+func (s *appSource) read(name string) ([]byte, ScriptInfo) {
+	info := ScriptInfo{}
+
+	var content []byte
+	var err error
+	if disk := s.diskPath(name); disk != "" {
+		info.Path = disk
+		info.Location = "filesystem"
+		if s.kind == "asar" {
+			info.Location = "unpacked"
+		}
+		info.Writable = s.scanner.isUserWritable(disk)
+		content, err = s.scanner.readFile(disk)
+	} else {
+		info.Path = filepath.Join(s.archive.Path, filepath.FromSlash(name))
+		info.Location = "asar"
+		content, err = s.archive.ReadFile(name)
+	}
+
+	if err != nil {
+		info.Missing = true
+		return nil, info
+	}
+
+	sum := sha256.Sum256(content)
+	info.SHA256 = hex.EncodeToString(sum[:])
+	return content, info
+}
+
+// exists reports whether a file is present in the source
+func (s *appSource) exists(name string) bool {
+	if s.kind == "folder" {
+		info, err := s.scanner.stat(s.diskPath(name))
+		return err == nil && !info.IsDir()
+	}
+	entry := s.archive.Lookup(name)
+	return entry != nil && entry.Files == nil
+}
+
+// resolveModule applies Node's file and index.js resolution to a module path
+func (s *appSource) resolveModule(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	for _, candidate := range []string{name, name + ".js", name + ".cjs", name + ".mjs", path.Join(name, "index.js")} {
+		if s.exists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// appPackage holds the package.json fields of the application code
+type appPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Main    string `json:"main"`
+}
+
+// openAppSource locates the code Electron will load for an application and
+// parses its package.json. It returns nil if neither source is readable.
+func (s *Scanner) openAppSource(appPath string) (*appSource, *appPackage) {
+	resourcesDir := s.GetResourcesPath(appPath)
+	if resourcesDir == "" {
+		return nil, nil
+	}
+
+	// Electron tries resources/app before app.asar, which is why a
+	// planted app folder wins unless OnlyLoadAppFromAsar is set
+	var source *appSource
+	appDir := filepath.Join(resourcesDir, "app")
+	if _, err := s.stat(filepath.Join(appDir, "package.json")); err == nil {
+		source = &appSource{scanner: s, kind: "folder", dir: appDir}
+	} else if archive, err := s.ReadAsarArchive(s.GetAsarPath(appPath)); err == nil {
+		source = &appSource{scanner: s, kind: "asar", archive: archive}
+	} else {
+		s.logger.Debug("No readable app.asar or app folder", "app", appPath)
+		return nil, nil
+	}
+
+	packageContent, _ := source.read("package.json")
+	if packageContent == nil {
+		s.logger.Debug("No package.json found", "source", source.kind)
+		return nil, nil
+	}
+
+	var pkg appPackage
+	if err := json.Unmarshal(packageContent, &pkg); err != nil {
+		s.logger.Warn("Error parsing package.json", "source", source.kind, "error", err)
+	}
+	if pkg.Main == "" {
+		pkg.Main = "index.js"
+	}
+
+	return source, &pkg
+}
+
+// walkMainProcess calls visit for the main script and every script reachable
+// from it through relative requires, up to maxScannedScripts files
+func (s *appSource) walkMainProcess(mainName string, visit func(name string, content []byte, info ScriptInfo)) {
+	seenScripts := map[string]bool{mainName: true}
+	queue := []string{mainName}
+	for len(queue) > 0 && len(seenScripts) <= maxScannedScripts {
+		name := queue[0]
+		queue = queue[1:]
+
+		content, info := s.read(name)
+		visit(name, content, info)
+		if content == nil {
+			continue
+		}
+
+		for _, matches := range requireRegex.FindAllSubmatch(content, -1) {
+			required := s.resolveModule(path.Join(path.Dir(name), string(matches[1])))
+			if required != "" && !seenScripts[required] {
+				seenScripts[required] = true
+				queue = append(queue, required)
+			}
+		}
+	}
+}
+
+// ResolveEntryPoint reads package.json to find the main script, then follows
+// the main process code to find preload scripts and webPreferences settings
+func (s *Scanner) ResolveEntryPoint(appPath string) *EntryPoint {
+	source, pkg := s.openAppSource(appPath)
+	if source == nil {
+		return nil
+	}
+
+	entry := &EntryPoint{
+		Source: source.kind,
+	}
+
+	mainName := source.resolveModule(pkg.Main)
+	if mainName == "" {
+		_, entry.Main = source.read(strings.TrimPrefix(path.Clean("/"+pkg.Main), "/"))
+		return entry
+	}
+
+	s.logger.Debug("Main entry point", "script", mainName, "source", source.kind)
+
+	seenPreloads := make(map[string]bool)
+	seenPreferences := make(map[string]bool)
+	source.walkMainProcess(mainName, func(name string, content []byte, info ScriptInfo) {
+		if name == mainName {
+			entry.Main = info
+		}
+		code := string(content)
+
+		for _, match := range preloadRegex.FindAllStringIndex(code, -1) {
+			preload := resolvePreloadExpression(propertyExpression(code[match[1]:]), name)
+			if preload == "" || seenPreloads[preload] {
+				continue
+			}
+			seenPreloads[preload] = true
+
+			var preloadInfo ScriptInfo
+			if path.IsAbs(preload) || filepath.IsAbs(preload) {
+				preloadInfo = s.diskScriptInfo(preload)
+			} else {
+				_, preloadInfo = source.read(preload)
+			}
+			s.logger.Debug("Found preload script", "path", preloadInfo.Path, "location", preloadInfo.Location)
+			entry.Preloads = append(entry.Preloads, preloadInfo)
+		}
+
+		for _, matches := range webPreferenceRegex.FindAllStringSubmatch(code, -1) {
+			value := matches[2]
+			switch value {
+			case "!0":
+				value = "true"
+			case "!1":
+				value = "false"
+			}
+			if insecureWebPreferences[matches[1]] != value || seenPreferences[name+":"+matches[1]] {
+				continue
+			}
+			seenPreferences[name+":"+matches[1]] = true
+			entry.WebPreferences = append(entry.WebPreferences, WebPreference{
+				Setting: matches[1],
+				Value:   value,
+				Script:  name,
+			})
+		}
+	})
+
+	return entry
+}
+
+// maxExpressionLength bounds the property values read from main process code
+const maxExpressionLength = 300
+
+// propertyExpression returns the value expression at the start of code, up to
+// the comma or closing bracket that ends it at the outermost level, so calls
+// such as path.join(__dirname, 'preload.js') are kept whole
+func propertyExpression(code string) string {
+	var closers []byte
+	var quote byte
+	for i := 0; i < len(code) && i < maxExpressionLength; i++ {
+		c := code[i]
+		if quote != 0 {
+			switch {
+			case c == '\\':
+				i++
+			case c == quote:
+				quote = 0
+			case quote == '`' && c == '$' && i+1 < len(code) && code[i+1] == '{':
+				// A ${...} substitution nests code inside the template
+				closers = append(closers, '`')
+				quote = 0
+				i++
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			closers = append(closers, ')')
+		case '[':
+			closers = append(closers, ']')
+		case '{':
+			closers = append(closers, '}')
+		case ')', ']', '}', ',', ';', '\n':
+			if len(closers) == 0 {
+				return strings.TrimSpace(code[:i])
+			}
+			if c == closers[len(closers)-1] || (c == '}' && closers[len(closers)-1] == '`') {
+				if closers[len(closers)-1] == '`' {
+					// Back inside the template literal
+					quote = '`'
+				}
+				closers = closers[:len(closers)-1]
+			}
+		}
+	}
+	if len(code) > maxExpressionLength {
+		return strings.TrimSpace(code[:maxExpressionLength])
+	}
+	return strings.TrimSpace(code)
+}
+
+// resolvePreloadExpression turns the right-hand side of a preload: property
+// into a path relative to the application root, or an absolute disk path
+func resolvePreloadExpression(expr string, scriptName string) string {
+	relative := strings.Contains(expr, "__dirname") || strings.Contains(expr, "getAppPath")
+
+	var parts []string
+	for _, literal := range stringLiteralRegex.FindAllStringSubmatch(expr, -1) {
+		part := templateExprRegex.ReplaceAllString(literal[1], "")
+		if relative {
+			// `${__dirname}/preload.js` leaves a leading slash behind
+			part = strings.TrimPrefix(part, "/")
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	joined := path.Join(parts...)
+	if !relative && (path.IsAbs(joined) || filepath.IsAbs(joined)) {
+		return joined
+	}
+
+	// Relative paths resolve against the calling script, except app.getAppPath()
+	base := path.Dir(scriptName)
+	if strings.Contains(expr, "getAppPath") {
+		base = ""
+	}
+	return strings.TrimPrefix(path.Clean("/"+path.Join(base, joined)), "/")
+}
+
+// diskScriptInfo describes a script referenced by absolute path
+func (s *Scanner) diskScriptInfo(diskPath string) ScriptInfo {
+	info := ScriptInfo{
+		Path:     diskPath,
+		Location: "filesystem",
+		Writable: s.isUserWritable(diskPath),
+	}
+
+	content, err := s.readFile(diskPath)
+	if err != nil {
+		info.Missing = true
+		return info
+	}
+
+	sum := sha256.Sum256(content)
+	info.SHA256 = hex.EncodeToString(sum[:])
+	return info
+}
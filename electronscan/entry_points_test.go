@@ -0,0 +1,46 @@
+package electronscan
+
+import "testing"
+
+func TestResolvePreloadExpression(t *testing.T) {
+	tests := []struct {
+		code   string
+		script string
+		want   string
+	}{
+		{`preload: path.join(__dirname, 'preload.js'),`, "main.js", "preload.js"},
+		{`preload: path.join(__dirname, "..", "renderer", "preload.js") }`, "dist/main.js", "renderer/preload.js"},
+		{`preload: path.resolve(__dirname, 'preload.js')})`, "src/main.js", "src/preload.js"},
+		{"preload: `${__dirname}/preload.js`,", "main.js", "preload.js"},
+		{`preload: path.join(app.getAppPath(), 'dist', 'preload.js'), sandbox: true`, "dist/main.js", "dist/preload.js"},
+		{`preload:"/opt/app/preload.js"}`, "main.js", "/opt/app/preload.js"},
+		{`preload: preloadPath,`, "main.js", ""},
+	}
+	for _, tt := range tests {
+		match := preloadRegex.FindStringIndex(tt.code)
+		if match == nil {
+			t.Fatalf("no preload property in %q", tt.code)
+		}
+		if got := resolvePreloadExpression(propertyExpression(tt.code[match[1]:]), tt.script); got != tt.want {
+			t.Errorf("%q in %s resolved to %q, want %q", tt.code, tt.script, got, tt.want)
+		}
+	}
+}
+
+func TestPropertyExpression(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{`path.join(__dirname, 'preload.js'), sandbox: true`, `path.join(__dirname, 'preload.js')`},
+		{`'a,b' }`, `'a,b'`},
+		{"`${a}, ${b}`)", "`${a}, ${b}`"},
+		{`fn({ x: 1 }, 'y');`, `fn({ x: 1 }, 'y')`},
+		{`x`, `x`},
+	}
+	for _, tt := range tests {
+		if got := propertyExpression(tt.code); got != tt.want {
+			t.Errorf("propertyExpression(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package electronscan_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// Scan a macOS image, here an in-memory one holding a fake application with
+// the fuses of an unmodified Electron build, and list what an attacker could use
+func ExampleScanner_Scan() {
+	image := fstest.MapFS{}
+	if _, err := (fixture.App{Name: "Demo", GOOS: "darwin"}).Build(image, "Applications"); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	scanner := electronscan.New(electronscan.Options{GOOS: "darwin", FS: image})
+	results, err := scanner.Scan()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, result := range results {
+		fmt.Printf("%s: Electron %s, risk %d (%s)\n", filepath.Base(result.Path), result.Version, result.Risk.Score, result.Risk.Severity)
+		for _, finding := range electronscan.Findings(result) {
+			fmt.Printf("  [%s] %s\n", finding.Severity, finding.RuleID)
+		}
+	}
+	// Output:
+	// Demo.app: Electron 30.1.0, risk 90 (critical)
+	//   [high] asar-integrity-disabled
+	//   [high] only-load-app-from-asar-disabled
+	//   [high] run-as-node-enabled
+	//   [medium] node-options-enabled
+	//   [medium] node-cli-inspect-enabled
+	//   [medium] unsigned-executable
+	//   [medium] unsupported-electron-version
+}
+
+// Check a build output against a release policy, as a CI gate would
+func ExamplePolicy_Evaluate() {
+	image := fstest.MapFS{}
+	appPath, err := fixture.App{
+		Name:      "Demo",
+		GOOS:      "windows",
+		Integrity: true,
+		Fuses: map[string]string{
+			"RunAsNode":                             electronscan.FuseDisabled,
+			"EnableEmbeddedAsarIntegrityValidation": electronscan.FuseEnabled,
+			"OnlyLoadAppFromAsar":                   electronscan.FuseEnabled,
+		},
+	}.Build(image, "build")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	policy := &electronscan.Policy{
+		Fuses:                   map[string]string{"RunAsNode": electronscan.FuseDisabled},
+		MinElectronVersion:      "30",
+		RequireAsarIntegrity:    true,
+		RequireSignedExecutable: true,
+	}
+	scanner := electronscan.New(electronscan.Options{GOOS: "windows", FS: image, Policy: policy})
+	result := scanner.CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+	for _, violation := range result.PolicyViolations {
+		fmt.Printf("%s: %s\n", violation.Requirement, violation.Message)
+	}
+	// Output:
+	// require_signed_executable: Demo.exe is not code signed
+}
@@ -0,0 +1,146 @@
+package electronscan
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/plist"
+)
+
+// hostFS opens files by their OS path. It is used when Options.FS is nil.
+type hostFS struct{}
+
+func (hostFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (hostFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (hostFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (hostFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// readerAtFile is an open file the debug/* and archive/zip readers can seek around in
+type readerAtFile interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// fsName converts an OS path into a name in Options.FS by dropping the volume
+// name and leading separators, so "/Applications/Slack.app" becomes "Applications/Slack.app"
+func fsName(name string) string {
+	name = filepath.ToSlash(strings.TrimPrefix(name, filepath.VolumeName(name)))
+	name = strings.TrimLeft(name, "/")
+	if name == "" {
+		return "."
+	}
+	return path.Clean(name)
+}
+
+// isHost reports whether the scanner reads the real filesystem
+func (s *Scanner) isHost() bool {
+	_, ok := s.fsys.(hostFS)
+	return ok
+}
+
+// name maps an OS path to the name used with s.fsys
+func (s *Scanner) name(p string) string {
+	if s.isHost() {
+		return p
+	}
+	return fsName(p)
+}
+
+// stat returns information about a file, following symlinks on the host
+func (s *Scanner) stat(p string) (fs.FileInfo, error) {
+	return fs.Stat(s.fsys, s.name(p))
+}
+
+// exists reports whether a file or directory exists
+func (s *Scanner) exists(p string) bool {
+	_, err := s.stat(p)
+	return err == nil
+}
+
+// readFile reads a whole file
+func (s *Scanner) readFile(p string) ([]byte, error) {
+	return fs.ReadFile(s.fsys, s.name(p))
+}
+
+// readPlist reads a property list as XML, converting binary plists so the
+// key lookups work the same for both formats
+func (s *Scanner) readPlist(p string) ([]byte, error) {
+	content, err := s.readFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return plist.ToXML(content)
+}
+
+// readDir lists a directory sorted by name
+func (s *Scanner) readDir(p string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(s.fsys, s.name(p))
+}
+
+// open opens a file for streaming reads
+func (s *Scanner) open(p string) (fs.File, error) {
+	return s.fsys.Open(s.name(p))
+}
+
+// openReaderAt opens a file for random access along with its size.
+// Files from filesystems without io.ReaderAt support are read into memory.
+func (s *Scanner) openReaderAt(p string) (readerAtFile, int64, error) {
+	f, err := s.open(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	if ra, ok := f.(readerAtFile); ok {
+		return ra, info.Size(), nil
+	}
+
+	content, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	return struct {
+		io.ReaderAt
+		io.Closer
+	}{bytes.NewReader(content), io.NopCloser(nil)}, int64(len(content)), nil
+}
+
+// walk calls fn for every file and directory under root, in lexical order.
+// Paths passed to fn are OS paths starting with root, whichever filesystem is read.
+func (s *Scanner) walk(root string, fn fs.WalkDirFunc) error {
+	if s.isHost() {
+		return filepath.WalkDir(root, fn)
+	}
+
+	fsRoot := fsName(root)
+	return fs.WalkDir(s.fsys, fsRoot, func(name string, d fs.DirEntry, err error) error {
+		var rel string
+		switch {
+		case name == fsRoot:
+		case fsRoot == ".":
+			rel = name
+		default:
+			rel = strings.TrimPrefix(name, fsRoot+"/")
+		}
+		return fn(filepath.Join(root, filepath.FromSlash(rel)), d, err)
+	})
+}
+
+// canWrite reports whether the current user has write permission on a file.
+// Outside the host filesystem the user is unknown, so only world-writable files count.
+func (s *Scanner) canWrite(p string) bool {
+	if s.isHost() {
+		return canWrite(p)
+	}
+	info, err := s.stat(p)
+	return err == nil && info.Mode().Perm()&0o002 != 0
+}
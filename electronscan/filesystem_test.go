@@ -0,0 +1,105 @@
+package electronscan_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// deniedFS fails to open one directory, like a profile the scan cannot read
+type deniedFS struct {
+	fstest.MapFS
+	denied string
+}
+
+func (f deniedFS) Open(name string) (fs.File, error) {
+	if name == f.denied {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.Open(name)
+}
+
+func (f deniedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == f.denied {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+// streamFS hides io.ReaderAt from its files, like a tar or network filesystem
+type streamFS struct{ fstest.MapFS }
+
+func (f streamFS) Open(name string) (fs.File, error) {
+	file, err := f.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := file.(fs.ReadDirFile); ok {
+		return file, nil
+	}
+	return struct{ fs.File }{file}, nil
+}
+
+func TestDiscoverRecordsDirectories(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, dir := range []string{"Applications", "Users/bob/Applications", "Users/bob/Applications/Locked"} {
+		if _, err := (fixture.App{}).Build(fsys, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := func(name string) string { return string(filepath.Separator) + filepath.FromSlash(name) }
+
+	s := electronscan.New(electronscan.Options{
+		GOOS:  "darwin",
+		FS:    deniedFS{fsys, "Users/bob/Applications/Locked"},
+		Roots: []string{root("Applications"), root("Users/bob/Applications"), root("Volumes/Missing")},
+	})
+	apps, err := s.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The scan goes on past the unreadable folder, and a missing root is
+	// neither scanned nor unreadable
+	if want := []string{root("Applications/Demo.app"), root("Users/bob/Applications/Demo.app")}; !slices.Equal(apps, want) {
+		t.Errorf("apps = %q, want %q", apps, want)
+	}
+	metadata := s.NewReport(nil, "test", time.Now(), time.Now()).Metadata
+	if want := []string{root("Applications"), root("Users/bob/Applications")}; !slices.Equal(metadata.ScannedDirs, want) {
+		t.Errorf("scanned dirs = %q, want %q", metadata.ScannedDirs, want)
+	}
+	if want := []string{root("Users/bob/Applications/Locked")}; !slices.Equal(metadata.UnreadableDirs, want) {
+		t.Errorf("unreadable dirs = %q, want %q", metadata.UnreadableDirs, want)
+	}
+}
+
+func TestCheckAppWithoutReaderAt(t *testing.T) {
+	for _, goos := range []string{"darwin", "windows"} {
+		t.Run(goos, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: goos, Signed: true, Integrity: true, NativeModules: []string{"keytar"}}.Build(fsys, installDirs[goos])
+			if err != nil {
+				t.Fatal(err)
+			}
+			appPath = string(filepath.Separator) + filepath.FromSlash(appPath)
+
+			want := electronscan.New(electronscan.Options{GOOS: goos, FS: fsys}).CheckApp(appPath)
+			got := electronscan.New(electronscan.Options{GOOS: goos, FS: streamFS{fsys}}).CheckApp(appPath)
+			if want.Signature != electronscan.SignatureSigned || len(want.NodeFiles) != 1 {
+				t.Fatalf("fixture scanned as %s with %d node files", want.Signature, len(want.NodeFiles))
+			}
+			if got.Signature != want.Signature || got.IntegrityHash != want.IntegrityHash || got.Version != want.Version {
+				t.Errorf("streamed scan = %s %s %s, want %s %s %s", got.Signature, got.IntegrityHash, got.Version, want.Signature, want.IntegrityHash, want.Version)
+			}
+			if len(got.NodeFiles) != 1 || got.NodeFiles[0].Format != want.NodeFiles[0].Format || !slices.Equal(got.NodeFiles[0].Architectures, want.NodeFiles[0].Architectures) {
+				t.Errorf("streamed node files = %+v, want %+v", got.NodeFiles, want.NodeFiles)
+			}
+		})
+	}
+}
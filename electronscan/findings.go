@@ -0,0 +1,227 @@
+package electronscan
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Finding severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Rule describes a class of weakness the scanner reports
+type Rule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+}
+
+// Finding is a single weakness found in an application
+type Finding struct {
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Path     string `json:"path"`
+}
+
+// Rule IDs are part of the output contract and must stay stable
+const (
+	RuleAsarIntegrityDisabled   = "asar-integrity-disabled"
+	RuleOnlyLoadFromAsarOff     = "only-load-app-from-asar-disabled"
+	RuleRunAsNodeEnabled        = "run-as-node-enabled"
+	RuleNodeOptionsEnabled      = "node-options-enabled"
+	RuleNodeCliInspectEnabled   = "node-cli-inspect-enabled"
+	RuleWritableNativeModule    = "writable-native-module"
+	RuleHijackableDependency    = "hijackable-native-dependency"
+	RuleWritableEntryScript     = "writable-entry-script"
+	RuleInsecureWebPreferences  = "insecure-web-preferences"
+	RuleInsecureUpdateFeed      = "insecure-update-feed"
+	RuleUpdateSignatureDisabled = "update-signature-disabled"
+	RuleWritableUpdateCache     = "writable-update-cache"
+	RuleFrameworkWeakness       = "framework-weakness"
+	RuleAsarIntegrityMismatch   = "asar-integrity-mismatch"
+	RuleUnsignedExecutable      = "unsigned-executable"
+	RuleUnsupportedElectron     = "unsupported-electron-version"
+)
+
+// Rules lists every rule the scanner can report
+var Rules = []Rule{
+	{RuleAsarIntegrityDisabled, "AsarIntegrityDisabled", "The app.asar archive is not protected by embedded ASAR integrity validation, so its contents can be modified.", SeverityHigh},
+	{RuleOnlyLoadFromAsarOff, "OnlyLoadAppFromAsarDisabled", "The OnlyLoadAppFromAsar fuse is off, so a resources/app folder takes precedence over the integrity-checked app.asar.", SeverityHigh},
+	{RuleRunAsNodeEnabled, "RunAsNodeEnabled", "The RunAsNode fuse is on, so ELECTRON_RUN_AS_NODE turns the signed binary into a Node.js interpreter.", SeverityHigh},
+	{RuleNodeOptionsEnabled, "NodeOptionsEnabled", "The EnableNodeOptionsEnvironmentVariable fuse is on, so NODE_OPTIONS can preload arbitrary code.", SeverityMedium},
+	{RuleNodeCliInspectEnabled, "NodeCliInspectEnabled", "The EnableNodeCliInspectArguments fuse is on, so --inspect exposes a debugger to inject code.", SeverityMedium},
+	{RuleWritableNativeModule, "WritableNativeModule", "A .node native module can be replaced by the current user and is loaded without integrity checks.", SeverityHigh},
+	{RuleHijackableDependency, "HijackableNativeDependency", "A library imported by a .node module is missing or resolves to a user-writable location.", SeverityHigh},
+	{RuleWritableEntryScript, "WritableEntryScript", "The main or preload script lives outside the asar in a user-writable location.", SeverityHigh},
+	{RuleInsecureWebPreferences, "InsecureWebPreferences", "Main process code creates windows with webPreferences that weaken renderer isolation.", SeverityMedium},
+	{RuleInsecureUpdateFeed, "InsecureUpdateFeed", "The auto-update feed is fetched over plain HTTP.", SeverityMedium},
+	{RuleUpdateSignatureDisabled, "UpdateSignatureDisabled", "Downloaded updates are not verified against a code signature.", SeverityMedium},
+	{RuleWritableUpdateCache, "WritableUpdateCache", "Downloaded updates are staged in a user-writable cache.", SeverityLow},
+	{RuleFrameworkWeakness, "FrameworkWeakness", "A framework-specific check for a non-Electron Chromium app failed.", SeverityMedium},
+	{RuleAsarIntegrityMismatch, "AsarIntegrityMismatch", "The app.asar header no longer matches the integrity hash embedded at build time, so the archive was modified after signing.", SeverityHigh},
+	{RuleUnsignedExecutable, "UnsignedExecutable", "The main executable carries no code signature, so it can be modified without invalidating one.", SeverityMedium},
+	{RuleUnsupportedElectron, "UnsupportedElectronVersion", "The bundled Electron major is no longer among the supported releases and misses Chromium security fixes.", SeverityMedium},
+}
+
+// RuleByID returns the rule with the given ID
+func RuleByID(id string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// newFinding creates a finding with the rule's default severity
+func newFinding(ruleID string, path string, format string, args ...any) Finding {
+	rule, _ := RuleByID(ruleID)
+	return Finding{
+		RuleID:   ruleID,
+		Severity: rule.Severity,
+		Message:  fmt.Sprintf(format, args...),
+		Path:     path,
+	}
+}
+
+// Findings derives the weaknesses present in an application result,
+// leaving out those listed in result.SuppressedFindings
+func Findings(result AppResult) []Finding {
+	findings := allFindings(result)
+	if len(result.SuppressedFindings) == 0 {
+		return findings
+	}
+
+	var active []Finding
+	for _, finding := range findings {
+		suppressed := false
+		for _, s := range result.SuppressedFindings {
+			if s.Finding == finding {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			active = append(active, finding)
+		}
+	}
+	return active
+}
+
+// allFindings derives every weakness present in an application result
+func allFindings(result AppResult) []Finding {
+	var findings []Finding
+
+	if !result.IsElectron {
+		for _, issue := range result.FrameworkFindings {
+			findings = append(findings, newFinding(RuleFrameworkWeakness, result.FrameworkEvidence, "%s: %s", result.Framework, issue))
+		}
+		return findings
+	}
+
+	executable := result.Executable
+	if executable == "" {
+		executable = result.Path
+	}
+	resourcesDir := result.ResourcesDir
+	if resourcesDir == "" {
+		resourcesDir = result.Path
+	}
+
+	if result.HasAsarFile {
+		asarPath := filepath.Join(resourcesDir, "app.asar")
+		if result.IntegrityHash == IntegrityHashMismatch {
+			findings = append(findings, newFinding(RuleAsarIntegrityMismatch, asarPath, "The header of %s does not match the embedded integrity hash", asarPath))
+		}
+		if !result.AsarIntegrity {
+			findings = append(findings, newFinding(RuleAsarIntegrityDisabled, asarPath, "ASAR integrity is not enabled for %s", asarPath))
+		} else if result.Fuses["EnableEmbeddedAsarIntegrityValidation"] == FuseDisabled {
+			findings = append(findings, newFinding(RuleAsarIntegrityDisabled, executable, "Integrity hashes are embedded but the EnableEmbeddedAsarIntegrityValidation fuse is disabled"))
+		}
+
+		if !result.OnlyLoadFromAsar {
+			findings = append(findings, newFinding(RuleOnlyLoadFromAsarOff, executable, "OnlyLoadAppFromAsar is disabled; a resources/app folder would be loaded instead of app.asar"))
+		}
+	}
+
+	fuseRules := []struct {
+		fuse string
+		rule string
+	}{
+		{"RunAsNode", RuleRunAsNodeEnabled},
+		{"EnableNodeOptionsEnvironmentVariable", RuleNodeOptionsEnabled},
+		{"EnableNodeCliInspectArguments", RuleNodeCliInspectEnabled},
+	}
+	for _, fr := range fuseRules {
+		if result.Fuses[fr.fuse] == FuseEnabled {
+			findings = append(findings, newFinding(fr.rule, executable, "The %s fuse is enabled", fr.fuse))
+		}
+	}
+
+	if result.Signature == SignatureUnsigned {
+		findings = append(findings, newFinding(RuleUnsignedExecutable, executable, "%s is not code signed", filepath.Base(executable)))
+	}
+
+	if supported, released, ok := ElectronSupported(result.Version, time.Now()); ok && !supported {
+		if released.IsZero() {
+			findings = append(findings, newFinding(RuleUnsupportedElectron, executable, "Electron %s is out of support", result.Version))
+		} else {
+			findings = append(findings, newFinding(RuleUnsupportedElectron, executable, "Electron %s (released %s) is out of support", result.Version, released.Format("Jan 2006")))
+		}
+	}
+
+	for _, module := range result.NodeFiles {
+		if module.Writable {
+			findings = append(findings, newFinding(RuleWritableNativeModule, module.Path, "Native module %s is writable by the current user", filepath.Base(module.Path)))
+		}
+		for _, dep := range module.Dependencies {
+			if dep.Missing {
+				findings = append(findings, newFinding(RuleHijackableDependency, module.Path, "Dependency %s of %s is missing (would load from %s)", dep.Name, filepath.Base(module.Path), dep.Resolved))
+			} else if dep.Writable {
+				findings = append(findings, newFinding(RuleHijackableDependency, module.Path, "Dependency %s of %s resolves to writable %s", dep.Name, filepath.Base(module.Path), dep.Resolved))
+			}
+		}
+	}
+
+	if entry := result.EntryPoint; entry != nil {
+		scripts := append([]ScriptInfo{entry.Main}, entry.Preloads...)
+		for i, script := range scripts {
+			kind := "Preload"
+			if i == 0 {
+				kind = "Main"
+			}
+			if script.Location != "asar" && script.Writable {
+				findings = append(findings, newFinding(RuleWritableEntryScript, script.Path, "%s script %s is writable outside the asar", kind, script.Path))
+			}
+		}
+
+		for _, pref := range entry.WebPreferences {
+			findings = append(findings, newFinding(RuleInsecureWebPreferences, result.Path, "webPreferences %s: %s in %s", pref.Setting, pref.Value, pref.Script))
+		}
+	}
+
+	if updater := result.Updater; updater != nil {
+		location := updater.ConfigPath
+		if location == "" {
+			location = result.Path
+		}
+		if strings.HasPrefix(strings.ToLower(updater.FeedURL), "http://") {
+			findings = append(findings, newFinding(RuleInsecureUpdateFeed, location, "Update feed %s uses plain HTTP", updater.FeedURL))
+		}
+		if updater.SignatureVerification == "disabled" || updater.SignatureVerification == "none" {
+			findings = append(findings, newFinding(RuleUpdateSignatureDisabled, location, "Update signature verification is %s", updater.SignatureVerification))
+		}
+		if updater.CacheWritable {
+			findings = append(findings, newFinding(RuleWritableUpdateCache, updater.CachePath, "Update cache %s is user-writable", updater.CachePath))
+		}
+	}
+
+	return findings
+}
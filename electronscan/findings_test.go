@@ -0,0 +1,200 @@
+package electronscan_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+func TestFindings(t *testing.T) {
+	app := filepath.FromSlash("/Applications/Demo.app")
+	exe := filepath.FromSlash("/Applications/Demo.app/Contents/MacOS/Demo")
+	resources := filepath.FromSlash("/Applications/Demo.app/Contents/Resources")
+	asar := filepath.Join(resources, "app.asar")
+	module := filepath.Join(resources, "app.asar.unpacked", "keytar.node")
+
+	// hardened describes an application that triggers no rule
+	hardened := func() electronscan.AppResult {
+		return electronscan.AppResult{
+			Path:             app,
+			IsElectron:       true,
+			Version:          "unknown",
+			HasAsarFile:      true,
+			AsarIntegrity:    true,
+			OnlyLoadFromAsar: true,
+			Executable:       exe,
+			ResourcesDir:     resources,
+			Signature:        electronscan.SignatureSigned,
+			Fuses:            map[string]string{"EnableEmbeddedAsarIntegrityValidation": electronscan.FuseEnabled},
+		}
+	}
+
+	// finding is a rule ID with the path it is reported at
+	type finding struct{ rule, path string }
+	tests := []struct {
+		name   string
+		change func(r *electronscan.AppResult)
+		want   []finding
+	}{
+		{
+			name:   "hardened",
+			change: func(*electronscan.AppResult) {},
+		},
+		{
+			name: "hash embedded but not validated",
+			change: func(r *electronscan.AppResult) {
+				r.Fuses["EnableEmbeddedAsarIntegrityValidation"] = electronscan.FuseDisabled
+			},
+			want: []finding{{electronscan.RuleAsarIntegrityDisabled, exe}},
+		},
+		{
+			name:   "tampered archive",
+			change: func(r *electronscan.AppResult) { r.IntegrityHash = electronscan.IntegrityHashMismatch },
+			want:   []finding{{electronscan.RuleAsarIntegrityMismatch, asar}},
+		},
+		{
+			name:   "resources/app folder would win",
+			change: func(r *electronscan.AppResult) { r.OnlyLoadFromAsar = false },
+			want:   []finding{{electronscan.RuleOnlyLoadFromAsarOff, exe}},
+		},
+		{
+			name: "no archive leaves the asar rules out",
+			change: func(r *electronscan.AppResult) {
+				r.HasAsarFile, r.AsarIntegrity, r.OnlyLoadFromAsar = false, false, false
+			},
+		},
+		{
+			name: "paths fall back to the application",
+			change: func(r *electronscan.AppResult) {
+				r.Executable, r.ResourcesDir, r.AsarIntegrity = "", "", false
+				r.Fuses["RunAsNode"] = electronscan.FuseEnabled
+			},
+			want: []finding{{electronscan.RuleAsarIntegrityDisabled, filepath.Join(app, "app.asar")}, {electronscan.RuleRunAsNodeEnabled, app}},
+		},
+		{
+			name: "removed fuses are not enabled",
+			change: func(r *electronscan.AppResult) {
+				r.Fuses["RunAsNode"] = electronscan.FuseRemoved
+				r.Fuses["EnableNodeOptionsEnvironmentVariable"] = electronscan.FuseEnabled
+				r.Fuses["EnableNodeCliInspectArguments"] = electronscan.FuseEnabled
+			},
+			want: []finding{{electronscan.RuleNodeOptionsEnabled, exe}, {electronscan.RuleNodeCliInspectEnabled, exe}},
+		},
+		{
+			name: "unsigned and out of support",
+			change: func(r *electronscan.AppResult) {
+				r.Signature, r.Version = electronscan.SignatureUnsigned, "1.8.8"
+			},
+			want: []finding{{electronscan.RuleUnsignedExecutable, exe}, {electronscan.RuleUnsupportedElectron, exe}},
+		},
+		{
+			name: "one finding per writable module and hijackable dependency",
+			change: func(r *electronscan.AppResult) {
+				r.NodeFiles = []electronscan.NativeModule{{
+					Path:     module,
+					Writable: true,
+					Dependencies: []electronscan.LibraryDependency{
+						{Name: "/usr/lib/libc++.1.dylib", Resolved: "/usr/lib/libc++.1.dylib"},
+						{Name: "@rpath/libsqlcipher.dylib", Missing: true},
+						{Name: "@rpath/libcrypto.dylib", Writable: true},
+					},
+				}}
+			},
+			want: []finding{{electronscan.RuleWritableNativeModule, module}, {electronscan.RuleHijackableDependency, module}, {electronscan.RuleHijackableDependency, module}},
+		},
+		{
+			name: "only scripts outside the asar can be writable",
+			change: func(r *electronscan.AppResult) {
+				r.EntryPoint = &electronscan.EntryPoint{
+					Main:     electronscan.ScriptInfo{Path: filepath.Join(asar, "main.js"), Location: "asar", Writable: true},
+					Preloads: []electronscan.ScriptInfo{{Path: filepath.Join(resources, "preload.js"), Location: "filesystem", Writable: true}},
+					WebPreferences: []electronscan.WebPreference{
+						{Setting: "sandbox", Value: "false", Script: "main.js"},
+					},
+				}
+			},
+			want: []finding{{electronscan.RuleWritableEntryScript, filepath.Join(resources, "preload.js")}, {electronscan.RuleInsecureWebPreferences, app}},
+		},
+		{
+			name: "update chain",
+			change: func(r *electronscan.AppResult) {
+				r.Updater = &electronscan.UpdaterInfo{
+					ConfigPath:            filepath.Join(resources, "app-update.yml"),
+					FeedURL:               "HTTP://updates.example.com",
+					SignatureVerification: "none",
+					CachePath:             filepath.FromSlash("/Users/alice/Library/Caches/demo-updater"),
+					CacheWritable:         true,
+				}
+			},
+			want: []finding{
+				{electronscan.RuleInsecureUpdateFeed, filepath.Join(resources, "app-update.yml")},
+				{electronscan.RuleUpdateSignatureDisabled, filepath.Join(resources, "app-update.yml")},
+				{electronscan.RuleWritableUpdateCache, filepath.FromSlash("/Users/alice/Library/Caches/demo-updater")},
+			},
+		},
+		{
+			name: "frameworks other than Electron only report their own checks",
+			change: func(r *electronscan.AppResult) {
+				*r = electronscan.AppResult{
+					Path:              app,
+					Framework:         electronscan.FrameworkCEF,
+					FrameworkEvidence: filepath.Join(app, "Contents", "Frameworks"),
+					FrameworkFindings: []string{"CEF runtime is user-writable"},
+					Signature:         electronscan.SignatureUnsigned,
+				}
+			},
+			want: []finding{{electronscan.RuleFrameworkWeakness, filepath.Join(app, "Contents", "Frameworks")}},
+		},
+	}
+
+	fired := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := hardened()
+			tt.change(&result)
+			var got []finding
+			for _, f := range electronscan.Findings(result) {
+				got = append(got, finding{f.RuleID, f.Path})
+				fired[f.RuleID] = true
+				if rule, ok := electronscan.RuleByID(f.RuleID); !ok || f.Severity != rule.Severity || f.Message == "" {
+					t.Errorf("%s: severity %q, message %q", f.RuleID, f.Severity, f.Message)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	for _, rule := range electronscan.Rules {
+		if !fired[rule.ID] {
+			t.Errorf("no case triggers %s", rule.ID)
+		}
+	}
+}
+
+func TestFindingsSuppression(t *testing.T) {
+	exe := filepath.FromSlash("/Applications/Demo.app/Contents/MacOS/Demo")
+	result := electronscan.AppResult{
+		Path:       filepath.FromSlash("/Applications/Demo.app"),
+		IsElectron: true,
+		Executable: exe,
+		Fuses:      map[string]string{"RunAsNode": electronscan.FuseEnabled, "EnableNodeCliInspectArguments": electronscan.FuseEnabled},
+	}
+	all := electronscan.Findings(result)
+	if len(all) != 2 {
+		t.Fatalf("findings = %+v", all)
+	}
+
+	// Only the exact finding is suppressed, not the rule elsewhere
+	elsewhere := all[0]
+	elsewhere.Path = filepath.FromSlash("/Applications/Other.app/Contents/MacOS/Other")
+	result.SuppressedFindings = []electronscan.SuppressedFinding{
+		{Finding: all[0], Expires: "2099-01-01", Justification: "debug build"},
+		{Finding: elsewhere, Expires: "2099-01-01", Justification: "other app"},
+	}
+	if active := electronscan.Findings(result); !slices.Equal(active, all[1:]) {
+		t.Errorf("active findings = %+v, want %+v", active, all[1:])
+	}
+}
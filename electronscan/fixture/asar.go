@@ -0,0 +1,72 @@
+package fixture
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+// asarFile is a file to store in an archive. Unpacked files are only listed
+// in the header; their contents belong in app.asar.unpacked.
+type asarFile struct {
+	content  []byte
+	unpacked bool
+}
+
+// buildAsar lays out an ASAR archive and returns it with the SHA-256 of its
+// header, which is what Electron embeds for integrity validation
+func buildAsar(files map[string]asarFile) ([]byte, string, error) {
+	root := &electronscan.AsarEntry{Files: map[string]*electronscan.AsarEntry{}}
+	var data []byte
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		file := files[name]
+		parts := strings.Split(strings.Trim(name, "/"), "/")
+		dir := root
+		for _, part := range parts[:len(parts)-1] {
+			if dir.Files[part] == nil {
+				dir.Files[part] = &electronscan.AsarEntry{Files: map[string]*electronscan.AsarEntry{}}
+			}
+			dir = dir.Files[part]
+		}
+
+		entry := &electronscan.AsarEntry{Size: int64(len(file.content)), Unpacked: file.unpacked}
+		if !file.unpacked {
+			entry.Offset = strconv.Itoa(len(data))
+			data = append(data, file.content...)
+		}
+		dir.Files[parts[len(parts)-1]] = entry
+	}
+
+	header, err := json.Marshal(root)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Two pickles precede the data: one holding the size of the header
+	// pickle, and the header pickle holding the JSON string padded to 4 bytes
+	padded := (len(header) + 3) &^ 3
+	archive := make([]byte, 16, 16+padded+len(data))
+	binary.LittleEndian.PutUint32(archive[0:], 4)
+	binary.LittleEndian.PutUint32(archive[4:], uint32(8+padded))
+	binary.LittleEndian.PutUint32(archive[8:], uint32(4+padded))
+	binary.LittleEndian.PutUint32(archive[12:], uint32(len(header)))
+	archive = append(archive, header...)
+	archive = append(archive, make([]byte, padded-len(header))...)
+	archive = append(archive, data...)
+
+	sum := sha256.Sum256(header)
+	return archive, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,351 @@
+package fixture
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/pe"
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// fuseSentinel marks the start of the fuse wire, as in Electron binaries
+const fuseSentinel = "dL7pKGdnNz796PbbjQWNKmHXBZaB9tsX"
+
+// Mach-O constants the stubs need
+const (
+	machoMagic64         = 0xfeedfacf
+	machoCPUArm64        = 0x0100000c
+	machoExecute         = 0x2
+	machoDylib           = 0x6
+	machoBundle          = 0x8
+	machoLoadDylib       = 0xc
+	machoCodeSignature   = 0x1d
+	machoHeaderSize      = 32
+	dylibCmdHeaderSize   = 24
+	codeSignatureCmdSize = 16
+)
+
+// PE layout of the stubs: headers in the first 0x400 bytes, sections after
+const (
+	peFileAlignment    = 0x200
+	peSectionAlignment = 0x1000
+	peHeadersSize      = 0x400
+	peLanguageEnglish  = 0x409
+)
+
+// ELF layout of the stubs: the header, then section contents, then the section headers
+const (
+	elfHeaderSize        = 64
+	elfSectionHeaderSize = 64
+	elfDynSize           = 16
+)
+
+// fuseWire encodes fuse states in wire order: sentinel, version, length, one byte per fuse
+func fuseWire(states []byte) []byte {
+	wire := append([]byte(fuseSentinel), 1, byte(len(states)))
+	return append(wire, states...)
+}
+
+// machoStub returns a 64-bit arm64 Mach-O of the given file type that loads
+// dylibs and whose only contents are the payload strings, optionally followed
+// by a code signature blob
+func machoStub(fileType uint32, signed bool, dylibs []string, payload ...[]byte) []byte {
+	var loads bytes.Buffer
+	for _, dylib := range dylibs {
+		cmdSize := alignUp(dylibCmdHeaderSize+uint32(len(dylib))+1, 8)
+		// Name offset, timestamp, current and compatibility versions
+		binary.Write(&loads, binary.LittleEndian, []uint32{machoLoadDylib, cmdSize, dylibCmdHeaderSize, 2, 0x10000, 0x10000})
+		loads.WriteString(dylib)
+		loads.Write(make([]byte, cmdSize-dylibCmdHeaderSize-uint32(len(dylib))))
+	}
+
+	ncmds, sizeofcmds := uint32(len(dylibs)), uint32(loads.Len())
+	if signed {
+		ncmds, sizeofcmds = ncmds+1, sizeofcmds+codeSignatureCmdSize
+	}
+
+	body := bytes.Join(payload, []byte{0})
+	dataStart := uint32(machoHeaderSize) + sizeofcmds
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{
+		machoMagic64, machoCPUArm64, 0, fileType, ncmds, sizeofcmds, 0, 0,
+	})
+	buf.Write(loads.Bytes())
+	if signed {
+		// The signature blob itself is only a placeholder; scanners locate it, they do not validate it
+		binary.Write(&buf, binary.LittleEndian, []uint32{
+			machoCodeSignature, codeSignatureCmdSize, dataStart + uint32(len(body)), 16,
+		})
+	}
+	buf.Write(body)
+	if signed {
+		buf.Write(make([]byte, 16))
+	}
+	return buf.Bytes()
+}
+
+// elfStub returns a 64-bit x86-64 ELF of the given type whose .rodata holds
+// the payload strings. Libraries are recorded as DT_NEEDED entries of a
+// .dynamic section, the way the dynamic loader finds them.
+func elfStub(fileType elf.Type, libraries []string, payload ...[]byte) []byte {
+	type section struct {
+		name    string
+		header  elf.Section64
+		content []byte
+	}
+
+	dynstr := []byte{0}
+	var dynamic bytes.Buffer
+	for _, library := range libraries {
+		binary.Write(&dynamic, binary.LittleEndian, elf.Dyn64{Tag: int64(elf.DT_NEEDED), Val: uint64(len(dynstr))})
+		dynstr = append(dynstr, library+"\x00"...)
+	}
+	binary.Write(&dynamic, binary.LittleEndian, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+
+	// Section 0 is the reserved null section; .dynamic links to .dynstr at index 1
+	sections := []section{
+		{},
+		{".dynstr", elf.Section64{Type: uint32(elf.SHT_STRTAB), Flags: uint64(elf.SHF_ALLOC), Addralign: 1}, dynstr},
+		{".dynamic", elf.Section64{Type: uint32(elf.SHT_DYNAMIC), Flags: uint64(elf.SHF_ALLOC | elf.SHF_WRITE), Link: 1, Addralign: 8, Entsize: elfDynSize}, dynamic.Bytes()},
+		{".rodata", elf.Section64{Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC), Addralign: 1}, bytes.Join(payload, []byte{0})},
+		{".shstrtab", elf.Section64{Type: uint32(elf.SHT_STRTAB), Addralign: 1}, nil},
+	}
+	shstrtab := []byte{0}
+	for i := 1; i < len(sections); i++ {
+		sections[i].header.Name = uint32(len(shstrtab))
+		shstrtab = append(shstrtab, sections[i].name+"\x00"...)
+	}
+	sections[len(sections)-1].content = shstrtab
+
+	var body bytes.Buffer
+	for i := 1; i < len(sections); i++ {
+		body.Write(make([]byte, alignUp(uint32(elfHeaderSize+body.Len()), 8)-uint32(elfHeaderSize+body.Len())))
+		sections[i].header.Off = uint64(elfHeaderSize + body.Len())
+		sections[i].header.Size = uint64(len(sections[i].content))
+		body.Write(sections[i].content)
+	}
+	body.Write(make([]byte, alignUp(uint32(elfHeaderSize+body.Len()), 8)-uint32(elfHeaderSize+body.Len())))
+
+	header := elf.Header64{
+		Type:      uint16(fileType),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     uint64(elfHeaderSize + body.Len()),
+		Ehsize:    elfHeaderSize,
+		Shentsize: elfSectionHeaderSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  uint16(len(sections) - 1),
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, header)
+	buf.Write(body.Bytes())
+	for _, section := range sections {
+		binary.Write(&buf, binary.LittleEndian, section.header)
+	}
+	return buf.Bytes()
+}
+
+// peSection is a section to place in a PE stub. Sections with content fill
+// it in once their RVA is known and are recorded in the data directory.
+type peSection struct {
+	name      string
+	data      []byte
+	flags     uint32
+	content   func(rva uint32) []byte
+	directory int
+}
+
+// peStub returns a PE32+ x64 image with the given sections. Signed images
+// get a placeholder Authenticode certificate table.
+func peStub(dll bool, signed bool, sections ...peSection) []byte {
+	characteristics := uint16(pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_LARGE_ADDRESS_AWARE)
+	if dll {
+		characteristics |= pe.IMAGE_FILE_DLL
+	}
+
+	optional := pe.OptionalHeader64{
+		Magic:                 0x20b,
+		ImageBase:             0x140000000,
+		SectionAlignment:      peSectionAlignment,
+		FileAlignment:         peFileAlignment,
+		MajorSubsystemVersion: 6,
+		SizeOfHeaders:         peHeadersSize,
+		Subsystem:             pe.IMAGE_SUBSYSTEM_WINDOWS_GUI,
+		NumberOfRvaAndSizes:   16,
+	}
+
+	var headers []pe.SectionHeader32
+	var raw []byte
+	rva := uint32(peSectionAlignment)
+	for _, section := range sections {
+		data := section.data
+		if section.content != nil {
+			data = section.content(rva)
+			optional.DataDirectory[section.directory] = pe.DataDirectory{
+				VirtualAddress: rva, Size: uint32(len(data)),
+			}
+		}
+
+		var name [8]uint8
+		copy(name[:], section.name)
+		rawSize := alignUp(uint32(len(data)), peFileAlignment)
+		headers = append(headers, pe.SectionHeader32{
+			Name:             name,
+			VirtualSize:      uint32(len(data)),
+			VirtualAddress:   rva,
+			SizeOfRawData:    rawSize,
+			PointerToRawData: peHeadersSize + uint32(len(raw)),
+			Characteristics:  section.flags,
+		})
+		raw = append(raw, data...)
+		raw = append(raw, make([]byte, rawSize-uint32(len(data)))...)
+		rva += alignUp(uint32(len(data)), peSectionAlignment)
+	}
+	optional.SizeOfImage = rva
+
+	var certificate []byte
+	if signed {
+		// WIN_CERTIFICATE header: length, revision 2.0, PKCS#7 signed data
+		certificate = make([]byte, 16)
+		binary.LittleEndian.PutUint32(certificate, uint32(len(certificate)))
+		binary.LittleEndian.PutUint16(certificate[4:], 0x0200)
+		binary.LittleEndian.PutUint16(certificate[6:], 0x0002)
+		optional.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY] = pe.DataDirectory{
+			VirtualAddress: peHeadersSize + uint32(len(raw)), Size: uint32(len(certificate)),
+		}
+	}
+
+	var buf bytes.Buffer
+	dos := make([]byte, 0x40)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], 0x40)
+	buf.Write(dos)
+	buf.WriteString("PE\x00\x00")
+	binary.Write(&buf, binary.LittleEndian, pe.FileHeader{
+		Machine:              pe.IMAGE_FILE_MACHINE_AMD64,
+		NumberOfSections:     uint16(len(headers)),
+		SizeOfOptionalHeader: uint16(binary.Size(optional)),
+		Characteristics:      characteristics,
+	})
+	binary.Write(&buf, binary.LittleEndian, optional)
+	binary.Write(&buf, binary.LittleEndian, headers)
+	buf.Write(make([]byte, peHeadersSize-buf.Len()))
+	buf.Write(raw)
+	buf.Write(certificate)
+	return buf.Bytes()
+}
+
+// resourceSection returns a .rsrc section holding one resource, addressed
+// by type and name with the English language ID, as rcedit writes it
+func resourceSection(typeName string, name string, data []byte) peSection {
+	return peSection{
+		name:      ".rsrc",
+		flags:     pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+		directory: pe.IMAGE_DIRECTORY_ENTRY_RESOURCE,
+		content: func(rva uint32) []byte {
+			const (
+				directorySize = 16
+				entrySize     = 8
+				dataEntrySize = 16
+				subdirectory  = 0x80000000
+				namedEntry    = 0x80000000
+			)
+
+			// Three single-entry directories (type, name, language), the data entry,
+			// then the name strings and the data itself
+			typeDir := uint32(0)
+			nameDir := typeDir + directorySize + entrySize
+			langDir := nameDir + directorySize + entrySize
+			dataEntry := langDir + directorySize + entrySize
+			typeString := dataEntry + dataEntrySize
+			nameString := typeString + resourceStringSize(typeName)
+			dataStart := alignUp(nameString+resourceStringSize(name), 8)
+
+			section := make([]byte, dataStart, dataStart+uint32(len(data)))
+			directory := func(off uint32, entryName uint32, target uint32) {
+				binary.LittleEndian.PutUint16(section[off+12:], boolToUint16(entryName&namedEntry != 0))
+				binary.LittleEndian.PutUint16(section[off+14:], boolToUint16(entryName&namedEntry == 0))
+				binary.LittleEndian.PutUint32(section[off+directorySize:], entryName)
+				binary.LittleEndian.PutUint32(section[off+directorySize+4:], target)
+			}
+			directory(typeDir, namedEntry|typeString, subdirectory|nameDir)
+			directory(nameDir, namedEntry|nameString, subdirectory|langDir)
+			directory(langDir, peLanguageEnglish, dataEntry)
+
+			binary.LittleEndian.PutUint32(section[dataEntry:], rva+dataStart)
+			binary.LittleEndian.PutUint32(section[dataEntry+4:], uint32(len(data)))
+			putResourceString(section[typeString:], typeName)
+			putResourceString(section[nameString:], name)
+
+			return append(section, data...)
+		},
+	}
+}
+
+// importSection returns an .idata section importing one function from each DLL
+func importSection(dlls []string) peSection {
+	return peSection{
+		name:      ".idata",
+		flags:     pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+		directory: pe.IMAGE_DIRECTORY_ENTRY_IMPORT,
+		content: func(rva uint32) []byte {
+			const (
+				descriptorSize = 20
+				thunkSize      = 8
+			)
+			const hintName = "\x00\x00init\x00\x00"
+
+			// Descriptors with a null terminator, then per DLL a lookup table
+			// of one entry and a terminator, the hint/name and the DLL name
+			section := make([]byte, descriptorSize*(len(dlls)+1))
+			for i, dll := range dlls {
+				thunks := uint32(len(section))
+				section = append(section, make([]byte, 2*thunkSize)...)
+				binary.LittleEndian.PutUint64(section[thunks:], uint64(rva)+uint64(len(section)))
+				section = append(section, hintName...)
+				name := uint32(len(section))
+				section = append(section, dll+"\x00"...)
+				section = append(section, make([]byte, alignUp(uint32(len(section)), 2)-uint32(len(section)))...)
+
+				descriptor := section[descriptorSize*i:]
+				binary.LittleEndian.PutUint32(descriptor, rva+thunks)
+				binary.LittleEndian.PutUint32(descriptor[12:], rva+name)
+				binary.LittleEndian.PutUint32(descriptor[16:], rva+thunks)
+			}
+			return section
+		},
+	}
+}
+
+// resourceStringSize is the size of a length-prefixed UTF-16 resource name
+func resourceStringSize(s string) uint32 {
+	return 2 + 2*uint32(len(utf16.Encode([]rune(s))))
+}
+
+// putResourceString writes a length-prefixed UTF-16LE resource name
+func putResourceString(b []byte, s string) {
+	units := utf16.Encode([]rune(s))
+	binary.LittleEndian.PutUint16(b, uint16(len(units)))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2+2*i:], u)
+	}
+}
+
+// boolToUint16 returns 1 for true and 0 for false
+func boolToUint16(b bool) uint16 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// alignUp rounds n up to a multiple of align, which must be a power of two
+func alignUp(n uint32, align uint32) uint32 {
+	return (n + align - 1) &^ (align - 1)
+}
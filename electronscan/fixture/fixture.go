@@ -0,0 +1,443 @@
+// Package fixture builds realistic fake Electron applications for tests: app
+// bundles and install directories with Info.plist files, Electron binary stubs
+// carrying a fuse wire, real ASAR archives with integrity hashes and native
+// module stubs. Nothing in them runs; they only look right to a scanner.
+package fixture
+
+import (
+	"debug/elf"
+	"debug/pe"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/internal/plist"
+	"github.com/adversis/electron-integrity/electronscan/internal/shelllink"
+)
+
+// Defaults used for empty App fields
+const (
+	DefaultName            = "Demo"
+	DefaultVersion         = "1.0.0"
+	DefaultElectronVersion = "30.1.0"
+)
+
+// DefaultFuses are the fuse states of an unmodified Electron build
+var DefaultFuses = map[string]string{
+	"RunAsNode":                             electronscan.FuseEnabled,
+	"EnableCookieEncryption":                electronscan.FuseDisabled,
+	"EnableNodeOptionsEnvironmentVariable":  electronscan.FuseEnabled,
+	"EnableNodeCliInspectArguments":         electronscan.FuseEnabled,
+	"EnableEmbeddedAsarIntegrityValidation": electronscan.FuseDisabled,
+	"OnlyLoadAppFromAsar":                   electronscan.FuseDisabled,
+	"LoadBrowserProcessSpecificV8Snapshot":  electronscan.FuseDisabled,
+	"GrantFileProtocolExtraPrivileges":      electronscan.FuseEnabled,
+}
+
+// App describes a fake Electron application
+type App struct {
+	// Name is the bundle and executable name; empty means DefaultName
+	Name string
+	// GOOS is the layout to build, "darwin", "windows" or "linux"; empty means "darwin"
+	GOOS string
+	// BundleID is the CFBundleIdentifier; empty means "com.example.<name>"
+	BundleID string
+	// Version is the application version; empty means DefaultVersion
+	Version string
+	// ElectronVersion is compiled into the executable; empty means DefaultElectronVersion
+	ElectronVersion string
+	// BinaryPlist writes the Info.plist files in binary format instead of XML
+	BinaryPlist bool
+	// Fuses overrides DefaultFuses by name with electronscan.FuseEnabled,
+	// FuseDisabled or FuseRemoved
+	Fuses map[string]string
+	// Integrity embeds the SHA-256 of the app.asar header, in Info.plist on
+	// macOS and in an INTEGRITY/ELECTRONASAR resource on Windows. Electron has
+	// no ASAR integrity on Linux, so it is ignored there.
+	Integrity bool
+	// TamperedAsar modifies app.asar after the integrity hash was taken
+	TamperedAsar bool
+	// Sources are the files packed in app.asar by slash-separated name; nil means
+	// a package.json and a main.js that opens a window with a preload script
+	Sources map[string]string
+	// NativeModules are npm packages shipping a .node addon, unpacked next to app.asar
+	NativeModules []string
+	// WritableModules makes the native modules world-writable
+	WritableModules bool
+	// ModuleImports are libraries every native module imports, named as the
+	// loader looks them up, e.g. "@rpath/libsqlcipher.dylib" or "sqlcipher.dll".
+	// They are not shipped, so they show up as missing or planted-over dependencies.
+	ModuleImports []string
+	// Signed adds a placeholder code signature to the executables; Linux
+	// executables are never signed
+	Signed bool
+	// AutoStart starts the application at login: a launch agent in
+	// Library/LaunchAgents on macOS, a shortcut in the common Startup folder
+	// on Windows and a desktop entry in etc/xdg/autostart on Linux, all at the
+	// root of fsys
+	AutoStart bool
+}
+
+// Build writes the application into fsys under dir, a slash-separated name,
+// and returns the name of the application: the .app bundle on macOS and the
+// executable on Windows and Linux. The name can be passed to a Scanner reading fsys.
+func (app App) Build(fsys fstest.MapFS, dir string) (string, error) {
+	app.setDefaults()
+
+	fuses, err := app.fuseStates()
+	if err != nil {
+		return "", err
+	}
+
+	switch app.GOOS {
+	case "darwin":
+		return app.buildMacos(fsys, dir, fuses)
+	case "windows":
+		return app.buildWindows(fsys, dir, fuses)
+	case "linux":
+		return app.buildLinux(fsys, dir, fuses)
+	}
+	return "", fmt.Errorf("unsupported fixture OS: %s", app.GOOS)
+}
+
+// setDefaults fills in empty fields
+func (app *App) setDefaults() {
+	if app.Name == "" {
+		app.Name = DefaultName
+	}
+	if app.GOOS == "" {
+		app.GOOS = "darwin"
+	}
+	if app.BundleID == "" {
+		app.BundleID = "com.example." + strings.ToLower(strings.ReplaceAll(app.Name, " ", "-"))
+	}
+	if app.Version == "" {
+		app.Version = DefaultVersion
+	}
+	if app.ElectronVersion == "" {
+		app.ElectronVersion = DefaultElectronVersion
+	}
+	if app.Sources == nil {
+		app.Sources = map[string]string{
+			"main.js": `const { app, BrowserWindow } = require('electron')
+const path = require('path')
+
+app.whenReady().then(() => {
+  const win = new BrowserWindow({
+    webPreferences: {
+      preload: path.join(__dirname, 'preload.js'),
+      contextIsolation: true,
+      sandbox: true
+    }
+  })
+  win.loadFile('index.html')
+})
+`,
+			"preload.js": "window.addEventListener('DOMContentLoaded', () => {})\n",
+			"index.html": "<!DOCTYPE html>\n<html><body><h1>" + app.Name + "</h1></body></html>\n",
+		}
+	}
+}
+
+// fuseStates returns the fuse wire bytes for the configured fuses
+func (app *App) fuseStates() ([]byte, error) {
+	for name := range app.Fuses {
+		if _, ok := DefaultFuses[name]; !ok {
+			return nil, fmt.Errorf("unknown fuse: %s", name)
+		}
+	}
+
+	states := make([]byte, len(electronscan.FuseNames))
+	for i, name := range electronscan.FuseNames {
+		state, ok := app.Fuses[name]
+		if !ok {
+			state = DefaultFuses[name]
+		}
+		switch state {
+		case electronscan.FuseEnabled:
+			states[i] = '1'
+		case electronscan.FuseDisabled:
+			states[i] = '0'
+		case electronscan.FuseRemoved:
+			states[i] = 'r'
+		default:
+			return nil, fmt.Errorf("invalid state for fuse %s: %s", name, state)
+		}
+	}
+	return states, nil
+}
+
+// archive builds app.asar, returning it with the integrity hash to embed.
+// Native modules are listed as unpacked and returned by name for the caller to lay out.
+func (app *App) archive(nativeModule func(name string) []byte) ([]byte, string, map[string][]byte, error) {
+	files := make(map[string]asarFile)
+	for name, content := range app.Sources {
+		files[name] = asarFile{content: []byte(content)}
+	}
+	if _, ok := files["package.json"]; !ok {
+		pkg, _ := json.MarshalIndent(map[string]string{
+			"name":    strings.ToLower(strings.ReplaceAll(app.Name, " ", "-")),
+			"version": app.Version,
+			"main":    "main.js",
+		}, "", "  ")
+		files["package.json"] = asarFile{content: pkg}
+	}
+
+	unpacked := make(map[string][]byte)
+	for _, module := range app.NativeModules {
+		pkg, _ := json.MarshalIndent(map[string]any{
+			"name":    module,
+			"version": "1.0.0",
+			"binary":  map[string]any{"napi_versions": []int{3}},
+		}, "", "  ")
+		pkgName := path.Join("node_modules", module, "package.json")
+		nodeName := path.Join("node_modules", module, "build", "Release", module+".node")
+
+		files[pkgName] = asarFile{content: pkg}
+		node := nativeModule(module)
+		files[nodeName] = asarFile{content: node, unpacked: true}
+		unpacked[pkgName] = pkg
+		unpacked[nodeName] = node
+	}
+
+	archive, hash, err := buildAsar(files)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if app.TamperedAsar {
+		// Rebuild with an injected main process script so the header, and with it
+		// the hash, changes while the embedded hash stays the original one
+		main := files["main.js"]
+		main.content = append([]byte("require('child_process').exec(process.env.PAYLOAD || '')\n"), main.content...)
+		files["main.js"] = main
+		if archive, _, err = buildAsar(files); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	return archive, hash, unpacked, nil
+}
+
+// moduleMode is the file mode of native modules and their package.json
+func (app *App) moduleMode() fs.FileMode {
+	if app.WritableModules {
+		return 0o666
+	}
+	return 0o644
+}
+
+// buildMacos lays out an .app bundle
+func (app *App) buildMacos(fsys fstest.MapFS, dir string, fuses []byte) (string, error) {
+	bundle := path.Join(dir, app.Name+".app")
+	contents := path.Join(bundle, "Contents")
+	resources := path.Join(contents, "Resources")
+
+	archive, hash, unpacked, err := app.archive(func(module string) []byte {
+		return machoStub(machoBundle, app.Signed, app.ModuleImports, []byte("napi_register_module_v1"), []byte(module))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	info := map[string]any{
+		"CFBundleDisplayName":        app.Name,
+		"CFBundleExecutable":         app.Name,
+		"CFBundleIdentifier":         app.BundleID,
+		"CFBundleName":               app.Name,
+		"CFBundlePackageType":        "APPL",
+		"CFBundleShortVersionString": app.Version,
+		"CFBundleVersion":            app.Version,
+		"LSMinimumSystemVersion":     "11.0",
+		"NSPrincipalClass":           "AtomApplication",
+	}
+	if app.Integrity {
+		info["ElectronAsarIntegrity"] = map[string]any{
+			"Resources/app.asar": map[string]any{
+				"algorithm": "SHA256",
+				"hash":      hash,
+			},
+		}
+	}
+	infoPlist, err := app.marshalPlist(info)
+	if err != nil {
+		return "", err
+	}
+
+	frameworkPlist, err := app.marshalPlist(map[string]any{
+		"CFBundleExecutable":  "Electron Framework",
+		"CFBundleIdentifier":  "com.github.Electron.framework",
+		"CFBundleName":        "Electron Framework",
+		"CFBundlePackageType": "FMWK",
+		"CFBundleVersion":     app.ElectronVersion,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	electron := []byte("Electron/" + app.ElectronVersion)
+	framework := path.Join(contents, "Frameworks", "Electron Framework.framework")
+	fsys[path.Join(contents, "Info.plist")] = &fstest.MapFile{Data: infoPlist, Mode: 0o644}
+	fsys[path.Join(contents, "PkgInfo")] = &fstest.MapFile{Data: []byte("APPL????"), Mode: 0o644}
+	fsys[path.Join(contents, "MacOS", app.Name)] = &fstest.MapFile{
+		Data: machoStub(machoExecute, app.Signed, nil),
+		Mode: 0o755,
+	}
+	// The fuse wire and version string live in the framework; the executable is a launcher
+	fsys[path.Join(framework, "Electron Framework")] = &fstest.MapFile{
+		Data: machoStub(machoDylib, app.Signed, nil, fuseWire(fuses), electron),
+		Mode: 0o755,
+	}
+	fsys[path.Join(framework, "Resources", "Info.plist")] = &fstest.MapFile{Data: frameworkPlist, Mode: 0o644}
+	fsys[path.Join(resources, "app.asar")] = &fstest.MapFile{Data: archive, Mode: 0o644}
+	for name, content := range unpacked {
+		fsys[path.Join(resources, "app.asar.unpacked", name)] = &fstest.MapFile{Data: content, Mode: app.moduleMode()}
+	}
+
+	if app.AutoStart {
+		agent, err := app.marshalPlist(map[string]any{
+			"Label":            app.BundleID + ".helper",
+			"ProgramArguments": []any{"/" + path.Join(contents, "MacOS", app.Name), "--hidden"},
+			"RunAtLoad":        true,
+		})
+		if err != nil {
+			return "", err
+		}
+		fsys[path.Join("Library", "LaunchAgents", app.BundleID+".helper.plist")] = &fstest.MapFile{Data: agent, Mode: 0o644}
+	}
+
+	return bundle, nil
+}
+
+// buildWindows lays out an install directory with the executable and resources folder
+func (app *App) buildWindows(fsys fstest.MapFS, dir string, fuses []byte) (string, error) {
+	installDir := path.Join(dir, app.Name)
+	resources := path.Join(installDir, "resources")
+
+	archive, hash, unpacked, err := app.archive(func(module string) []byte {
+		sections := []peSection{{
+			name:  ".rdata",
+			data:  []byte("napi_register_module_v1\x00" + module + "\x00"),
+			flags: pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+		}}
+		if len(app.ModuleImports) > 0 {
+			sections = append(sections, importSection(app.ModuleImports))
+		}
+		return peStub(true, app.Signed, sections...)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sections := []peSection{{
+		name:  ".rdata",
+		data:  append(append(fuseWire(fuses), 0), "Electron/"+app.ElectronVersion+"\x00"...),
+		flags: pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+	}}
+	if app.Integrity {
+		// Electron writes the fields in this order; a map would sort them
+		integrity, err := json.Marshal([]struct {
+			File  string `json:"file"`
+			Alg   string `json:"alg"`
+			Value string `json:"value"`
+		}{{File: `resources\app.asar`, Alg: "sha256", Value: hash}})
+		if err != nil {
+			return "", err
+		}
+		sections = append(sections, resourceSection("INTEGRITY", "ELECTRONASAR", integrity))
+	}
+
+	exe := path.Join(installDir, app.Name+".exe")
+	fsys[exe] = &fstest.MapFile{Data: peStub(false, app.Signed, sections...), Mode: 0o755}
+	fsys[path.Join(resources, "app.asar")] = &fstest.MapFile{Data: archive, Mode: 0o644}
+	for name, content := range unpacked {
+		fsys[path.Join(resources, "app.asar.unpacked", name)] = &fstest.MapFile{Data: content, Mode: app.moduleMode()}
+	}
+
+	if app.AutoStart {
+		shortcut := shelllink.Marshal(shelllink.Link{
+			Target:    `C:\` + strings.ReplaceAll(exe, "/", `\`),
+			Arguments: "--hidden",
+		})
+		startup := path.Join("ProgramData", "Microsoft", "Windows", "Start Menu", "Programs", "Startup")
+		fsys[path.Join(startup, app.Name+".lnk")] = &fstest.MapFile{Data: shortcut, Mode: 0o644}
+	}
+
+	return exe, nil
+}
+
+// buildLinux lays out an install directory, as deb and rpm packages put in
+// /opt, with the executable, the Chromium sandbox helper and the resources folder
+func (app *App) buildLinux(fsys fstest.MapFS, dir string, fuses []byte) (string, error) {
+	installDir := path.Join(dir, app.Name)
+	resources := path.Join(installDir, "resources")
+
+	archive, _, unpacked, err := app.archive(func(module string) []byte {
+		return elfStub(elf.ET_DYN, app.ModuleImports, []byte("napi_register_module_v1"), []byte(module))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	exe := path.Join(installDir, strings.ToLower(strings.ReplaceAll(app.Name, " ", "-")))
+	fsys[exe] = &fstest.MapFile{
+		Data: elfStub(elf.ET_DYN, nil, fuseWire(fuses), []byte("Electron/"+app.ElectronVersion)),
+		Mode: 0o755,
+	}
+	fsys[path.Join(installDir, "chrome-sandbox")] = &fstest.MapFile{
+		Data: elfStub(elf.ET_DYN, nil),
+		Mode: fs.ModeSetuid | 0o755,
+	}
+	fsys[path.Join(resources, "app.asar")] = &fstest.MapFile{Data: archive, Mode: 0o644}
+	for name, content := range unpacked {
+		fsys[path.Join(resources, "app.asar.unpacked", name)] = &fstest.MapFile{Data: content, Mode: app.moduleMode()}
+	}
+
+	if app.AutoStart {
+		entry := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=%s\nExec=/%s --hidden %%U\nX-GNOME-Autostart-enabled=true\n", app.Name, exe)
+		fsys[path.Join("etc", "xdg", "autostart", path.Base(exe)+".desktop")] = &fstest.MapFile{Data: []byte(entry), Mode: 0o644}
+	}
+
+	return exe, nil
+}
+
+// marshalPlist encodes a property list in the configured format
+func (app *App) marshalPlist(v map[string]any) ([]byte, error) {
+	if app.BinaryPlist {
+		return plist.MarshalBinary(v)
+	}
+	return plist.MarshalXML(v)
+}
+
+// WriteDir copies every file of fsys into root on disk, keeping file modes
+func WriteDir(fsys fs.FS, root string) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+			return err
+		}
+		// WriteFile applies the umask, which would drop world-writable bits
+		return os.Chmod(target, info.Mode().Perm())
+	})
+}
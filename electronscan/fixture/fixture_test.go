@@ -0,0 +1,270 @@
+package fixture_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/hex"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+	"github.com/adversis/electron-integrity/electronscan/internal/plist"
+)
+
+// osPath converts a fixture name into the path a scanner reading the MapFS expects
+func osPath(name string) string {
+	return string(filepath.Separator) + filepath.FromSlash(name)
+}
+
+// TestBinariesParse checks the stubs with the standard library readers rather
+// than the scanner, so both cannot share a misreading of the formats
+func TestBinariesParse(t *testing.T) {
+	imports := map[string][]string{
+		"darwin":  {"@rpath/libsqlcipher.dylib"},
+		"windows": {"sqlcipher.dll"},
+		"linux":   {"libsqlcipher.so.0"},
+	}
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		t.Run(goos, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: goos, NativeModules: []string{"keytar"}, ModuleImports: imports[goos]}.Build(fsys, "apps")
+			if err != nil {
+				t.Fatal(err)
+			}
+			module := path.Join("apps", "Demo", "resources", "app.asar.unpacked", "node_modules", "keytar", "build", "Release", "keytar.node")
+			executable := appPath
+			if goos == "darwin" {
+				module = path.Join(appPath, "Contents", "Resources", "app.asar.unpacked", "node_modules", "keytar", "build", "Release", "keytar.node")
+				executable = path.Join(appPath, "Contents", "MacOS", "Demo")
+			}
+
+			var libs []string
+			switch goos {
+			case "darwin":
+				exe, err := macho.NewFile(bytes.NewReader(fsys[executable].Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exe.Type != macho.TypeExec {
+					t.Errorf("executable type = %v", exe.Type)
+				}
+				f, err := macho.NewFile(bytes.NewReader(fsys[module].Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if f.Type != macho.TypeBundle {
+					t.Errorf("module type = %v, want a bundle", f.Type)
+				}
+				libs, err = f.ImportedLibraries()
+				if err != nil {
+					t.Fatal(err)
+				}
+			case "windows":
+				exe, err := pe.NewFile(bytes.NewReader(fsys[executable].Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exe.Characteristics&pe.IMAGE_FILE_DLL != 0 {
+					t.Error("executable is marked as a DLL")
+				}
+				f, err := pe.NewFile(bytes.NewReader(fsys[module].Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if f.Characteristics&pe.IMAGE_FILE_DLL == 0 {
+					t.Error("module is not marked as a DLL")
+				}
+				// debug/pe does not list libraries, only symbols as "name:dll"
+				symbols, err := f.ImportedSymbols()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, symbol := range symbols {
+					if _, dll, ok := strings.Cut(symbol, ":"); ok && !slices.Contains(libs, dll) {
+						libs = append(libs, dll)
+					}
+				}
+			case "linux":
+				if _, err := elf.NewFile(bytes.NewReader(fsys[executable].Data)); err != nil {
+					t.Fatal(err)
+				}
+				f, err := elf.NewFile(bytes.NewReader(fsys[module].Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				libs, err = f.ImportedLibraries()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !slices.Equal(libs, imports[goos]) {
+				t.Errorf("module imports = %q, want %q", libs, imports[goos])
+			}
+		})
+	}
+}
+
+func TestSignedStubs(t *testing.T) {
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		for _, signed := range []bool{false, true} {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: goos, Signed: signed}.Build(fsys, "apps")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := electronscan.New(electronscan.Options{GOOS: goos, FS: fsys})
+			got := s.CodeSignatureStatus(s.GetExecutablePath(osPath(appPath)))
+
+			// Linux executables carry no signature to find
+			want := electronscan.SignatureUnsigned
+			switch {
+			case goos == "linux":
+				want = ""
+			case signed:
+				want = electronscan.SignatureSigned
+			}
+			if got != want {
+				t.Errorf("%s signed=%t: signature = %q, want %q", goos, signed, got, want)
+			}
+		}
+	}
+}
+
+func TestIntegrityHash(t *testing.T) {
+	embedded := regexp.MustCompile(`[0-9a-f]{64}`)
+	for _, tampered := range []bool{false, true} {
+		for _, goos := range []string{"darwin", "windows"} {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: goos, Integrity: true, TamperedAsar: tampered}.Build(fsys, "apps")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var hash, asarPath string
+			if goos == "darwin" {
+				info, err := plist.Decode(fsys[path.Join(appPath, "Contents", "Info.plist")].Data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				integrity := info.(map[string]any)["ElectronAsarIntegrity"].(map[string]any)["Resources/app.asar"].(map[string]any)
+				if integrity["algorithm"] != "SHA256" {
+					t.Errorf("algorithm = %v", integrity["algorithm"])
+				}
+				hash, _ = integrity["hash"].(string)
+				asarPath = path.Join(appPath, "Contents", "Resources", "app.asar")
+			} else {
+				hash = string(embedded.Find(fsys[appPath].Data))
+				asarPath = path.Join(path.Dir(appPath), "resources", "app.asar")
+			}
+
+			s := electronscan.New(electronscan.Options{GOOS: goos, FS: fsys})
+			archive, err := s.ReadAsarArchive(osPath(asarPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sum := sha256.Sum256(archive.HeaderJSON)
+			if matches := hex.EncodeToString(sum[:]) == hash; matches == tampered {
+				t.Errorf("%s tampered=%t: embedded hash %q matches the header: %t", goos, tampered, hash, matches)
+			}
+
+			main, err := archive.ReadFile("main.js")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if injected := strings.Contains(string(main), "child_process"); injected != tampered {
+				t.Errorf("%s tampered=%t: main.js injected = %t", goos, tampered, injected)
+			}
+		}
+	}
+}
+
+func TestFuseWire(t *testing.T) {
+	overrides := map[string]string{
+		"RunAsNode":                             electronscan.FuseDisabled,
+		"EnableEmbeddedAsarIntegrityValidation": electronscan.FuseEnabled,
+		"GrantFileProtocolExtraPrivileges":      electronscan.FuseRemoved,
+	}
+	want := maps.Clone(fixture.DefaultFuses)
+	maps.Copy(want, overrides)
+
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		fsys := fstest.MapFS{}
+		appPath, err := fixture.App{GOOS: goos, Fuses: overrides}.Build(fsys, "apps")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := electronscan.New(electronscan.Options{GOOS: goos, FS: fsys})
+		fuses, err := s.ReadFuses(s.ElectronBinaryPath(osPath(appPath)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(fuses, want) {
+			t.Errorf("%s fuses = %v, want %v", goos, fuses, want)
+		}
+	}
+}
+
+func TestBuildRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		app  fixture.App
+		err  string
+	}{
+		{"unknown fuse", fixture.App{Fuses: map[string]string{"RunAsNodes": electronscan.FuseDisabled}}, "unknown fuse: RunAsNodes"},
+		{"invalid fuse state", fixture.App{Fuses: map[string]string{"RunAsNode": "off"}}, "invalid state for fuse RunAsNode"},
+		{"unsupported OS", fixture.App{GOOS: "plan9"}, "unsupported fixture OS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			if _, err := tt.app.Build(fsys, "apps"); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	fsys := fstest.MapFS{}
+	appPath, err := fixture.App{GOOS: "linux", NativeModules: []string{"keytar"}, WritableModules: true}.Build(fsys, "opt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := fixture.WriteDir(fsys, root); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, file := range fsys {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(content, file.Data) {
+			t.Errorf("%s differs on disk", name)
+		}
+	}
+
+	// The world-writable bit survives the umask
+	if runtime.GOOS != "windows" {
+		module := filepath.Join(root, "opt", "Demo", "resources", "app.asar.unpacked", "node_modules", "keytar", "build", "Release", "keytar.node")
+		if info, err := os.Stat(module); err != nil || info.Mode().Perm() != 0o666 {
+			t.Errorf("module mode = %v, %v, want 0666", info.Mode(), err)
+		}
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(appPath))); err != nil || info.Mode().Perm() != 0o755 {
+			t.Errorf("executable mode = %v, %v, want 0755", info.Mode(), err)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package electronscan
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Chromium-embedding frameworks recognized by the scanner
+const (
+	FrameworkElectron         = "electron"
+	FrameworkElectronCastlabs = "electron-castlabs"
+	FrameworkNWJS             = "nwjs"
+	FrameworkCEF              = "cef"
+	FrameworkTauri            = "tauri"
+	FrameworkWebView2         = "webview2"
+)
+
+// Files next to a Windows executable that identify a non-Electron framework
+var windowsFrameworkMarkers = []struct {
+	file      string
+	framework string
+}{
+	{"nw.dll", FrameworkNWJS},
+	{"package.nw", FrameworkNWJS},
+	{"libcef.dll", FrameworkCEF},
+	{"WebView2Loader.dll", FrameworkWebView2},
+}
+
+// Strings compiled into Tauri binaries
+var tauriSignatures = [][]byte{
+	[]byte("__TAURI__"),
+	[]byte("tauri://localhost"),
+}
+
+// castlabsVersionRegex matches the tag castlabs Electron for Content Security
+// compiles into its user agent, e.g. Electron/30.1.0+wvcus. Version numbers are
+// read without it, so the raw string has to be checked.
+var castlabsVersionRegex = regexp.MustCompile(`Electron/[0-9.]+\+wvcus`)
+
+// bundleExecutableRegex extracts CFBundleExecutable from an XML Info.plist
+var bundleExecutableRegex = regexp.MustCompile(`<key>CFBundleExecutable</key>\s*<string>([^<]+)</string>`)
+
+// HasWindowsFrameworkMarker reports whether a directory contains files that
+// identify a Chromium-embedding framework other than Electron
+func (s *Scanner) HasWindowsFrameworkMarker(dir string) bool {
+	for _, marker := range windowsFrameworkMarkers {
+		if _, err := s.stat(filepath.Join(dir, marker.file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ElectronFrameworkVariant distinguishes upstream Electron from known forks by version string
+func ElectronFrameworkVariant(version string) string {
+	// castlabs Electron for Content Security tags its releases "+wvcus"
+	if strings.Contains(version, "+wvcus") {
+		return FrameworkElectronCastlabs
+	}
+	return FrameworkElectron
+}
+
+// electronBinaryVariant distinguishes upstream Electron from castlabs builds by
+// the contents of the Electron binary, see ElectronBinaryPath
+func electronBinaryVariant(content []byte) string {
+	if castlabsVersionRegex.Match(content) {
+		return FrameworkElectronCastlabs
+	}
+	return FrameworkElectron
+}
+
+// ElectronBinaryPath returns the file holding Electron itself: the Electron
+// Framework binary on macOS, where the app executable is only a small
+// launcher, and the main executable on Windows
+func (s *Scanner) ElectronBinaryPath(appPath string) string {
+	if s.goos == "darwin" {
+		framework := filepath.Join(appPath, "Contents", "Frameworks", "Electron Framework.framework", "Electron Framework")
+		if s.exists(framework) {
+			return framework
+		}
+	}
+	return s.GetExecutablePath(appPath)
+}
+
+// GetBundleExecutable returns the main executable of a macOS app bundle
+func (s *Scanner) GetBundleExecutable(appPath string) string {
+	name := filepath.Base(strings.TrimSuffix(appPath, ".app"))
+	if plistContent, err := s.readPlist(filepath.Join(appPath, "Contents", "Info.plist")); err == nil {
+		if matches := bundleExecutableRegex.FindSubmatch(plistContent); len(matches) > 1 {
+			name = strings.TrimSpace(string(matches[1]))
+		}
+	}
+	return filepath.Join(appPath, "Contents", "MacOS", name)
+}
+
+// DetectFramework identifies non-Electron Chromium-embedding frameworks.
+// It returns the framework name and the file that identified it, or "" if none matched.
+func (s *Scanner) DetectFramework(appPath string) (string, string) {
+	var framework, evidence string
+	switch s.goos {
+	case "darwin":
+		framework, evidence = s.detectFrameworkMacos(appPath)
+	case "windows":
+		framework, evidence = s.detectFrameworkWindows(appPath)
+	}
+
+	if framework != "" {
+		s.logger.Debug("Detected framework", "framework", framework, "evidence", evidence)
+	}
+	return framework, evidence
+}
+
+// detectFrameworkMacos checks an app bundle for NW.js, CEF and Tauri
+func (s *Scanner) detectFrameworkMacos(appPath string) (string, string) {
+	frameworksDir := filepath.Join(appPath, "Contents", "Frameworks")
+
+	candidates := []struct {
+		path      string
+		framework string
+	}{
+		{filepath.Join(frameworksDir, "nwjs Framework.framework"), FrameworkNWJS},
+		{filepath.Join(appPath, "Contents", "Resources", "app.nw"), FrameworkNWJS},
+		{filepath.Join(frameworksDir, "Chromium Embedded Framework.framework"), FrameworkCEF},
+	}
+	for _, candidate := range candidates {
+		if _, err := s.stat(candidate.path); err == nil {
+			return candidate.framework, candidate.path
+		}
+	}
+
+	// Tauri compiles the frontend into a single binary using the system WebView
+	executable := s.GetBundleExecutable(appPath)
+	if s.containsAny(executable, tauriSignatures) {
+		return FrameworkTauri, executable
+	}
+
+	return "", ""
+}
+
+// detectFrameworkWindows checks the directory of an executable for NW.js, CEF, Tauri and WebView2
+func (s *Scanner) detectFrameworkWindows(appPath string) (string, string) {
+	exePath := appPath
+	if !strings.HasSuffix(exePath, ".exe") {
+		exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+	}
+	dir := filepath.Dir(exePath)
+
+	// Markers take a stat each, so they go before reading the binary. Tauri
+	// also loads WebView2 though, so the binary is checked before the loader DLL.
+	for _, marker := range windowsFrameworkMarkers {
+		markerPath := filepath.Join(dir, marker.file)
+		if _, err := s.stat(markerPath); err != nil {
+			continue
+		}
+		if marker.framework == FrameworkWebView2 && s.containsAny(exePath, tauriSignatures) {
+			return FrameworkTauri, exePath
+		}
+		return marker.framework, markerPath
+	}
+
+	if s.containsAny(exePath, tauriSignatures) {
+		return FrameworkTauri, exePath
+	}
+
+	return "", ""
+}
+
+// containsAnyChunkSize is how much of a file containsAny holds in memory at once
+const containsAnyChunkSize = 1 << 20
+
+// containsAny reports whether the file at path contains any of the signatures.
+// The file is streamed in chunks, each searched together with the tail of the
+// previous one so a signature split between two reads is still found.
+func (s *Scanner) containsAny(path string, signatures [][]byte) bool {
+	f, err := s.open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	overlap := 0
+	for _, sig := range signatures {
+		overlap = max(overlap, len(sig)-1)
+	}
+	buf := make([]byte, containsAnyChunkSize+overlap)
+	kept := 0
+	for {
+		n, err := f.Read(buf[kept:])
+		window := buf[:kept+n]
+		for _, sig := range signatures {
+			if bytes.Contains(window, sig) {
+				return true
+			}
+		}
+		if err != nil {
+			return false
+		}
+		kept = copy(buf, window[max(0, len(window)-overlap):])
+	}
+}
+
+// CheckFramework runs framework-specific checks and returns human-readable findings
+func (s *Scanner) CheckFramework(appPath string, framework string, evidence string) []string {
+	var findings []string
+
+	switch framework {
+	case FrameworkNWJS:
+		findings = s.checkNWJS(appPath)
+	case FrameworkCEF:
+		if s.isUserWritable(evidence) {
+			findings = append(findings, fmt.Sprintf("CEF runtime is user-writable: %s", evidence))
+		}
+	case FrameworkWebView2:
+		if s.isUserWritable(evidence) {
+			findings = append(findings, fmt.Sprintf("WebView2 loader is user-writable: %s", evidence))
+		}
+	case FrameworkTauri:
+		if s.isUserWritable(evidence) {
+			findings = append(findings, fmt.Sprintf("Tauri executable is user-writable: %s", evidence))
+		}
+	}
+
+	for _, finding := range findings {
+		s.logger.Debug("Framework finding", "finding", finding)
+	}
+	return findings
+}
+
+// checkNWJS locates the NW.js application package, which has no integrity
+// protection, and inspects its manifest for settings that expose Node.js
+func (s *Scanner) checkNWJS(appPath string) []string {
+	var candidates []string
+	switch s.goos {
+	case "darwin":
+		candidates = []string{filepath.Join(appPath, "Contents", "Resources", "app.nw")}
+	case "windows":
+		exePath := appPath
+		if !strings.HasSuffix(exePath, ".exe") {
+			exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+		}
+		dir := filepath.Dir(exePath)
+		candidates = []string{
+			filepath.Join(dir, "package.nw"),
+			filepath.Join(dir, "package.json"),
+		}
+	}
+
+	var findings []string
+	for _, candidate := range candidates {
+		info, err := s.stat(candidate)
+		if err != nil {
+			continue
+		}
+
+		if s.isUserWritable(candidate) {
+			findings = append(findings, fmt.Sprintf("NW.js application code is user-writable: %s", candidate))
+		}
+
+		var manifest []byte
+		switch {
+		case info.IsDir():
+			manifest, _ = s.readFile(filepath.Join(candidate, "package.json"))
+		case strings.HasSuffix(candidate, ".json"):
+			manifest, _ = s.readFile(candidate)
+		default:
+			manifest = s.readZipFile(candidate, "package.json")
+		}
+
+		var pkg struct {
+			NodeRemote   any    `json:"node-remote"`
+			ChromiumArgs string `json:"chromium-args"`
+		}
+		if manifest != nil && json.Unmarshal(manifest, &pkg) == nil {
+			if pkg.NodeRemote != nil {
+				findings = append(findings, fmt.Sprintf("node-remote grants Node.js to remote pages: %v", pkg.NodeRemote))
+			}
+			if strings.Contains(pkg.ChromiumArgs, "--disable-web-security") {
+				findings = append(findings, "chromium-args disables web security")
+			}
+		}
+		break
+	}
+
+	return findings
+}
+
+// readZipFile returns a file from a zip archive (package.nw), or nil
+func (s *Scanner) readZipFile(archivePath string, name string) []byte {
+	archive, size, err := s.openReaderAt(archivePath)
+	if err != nil {
+		return nil
+	}
+	defer archive.Close()
+
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil
+	}
+
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return nil
+		}
+		return content
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package electronscan_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestElectronVariant(t *testing.T) {
+	tests := []struct {
+		goos            string
+		electronVersion string
+		want            string
+	}{
+		{"darwin", "30.1.0", electronscan.FrameworkElectron},
+		{"darwin", "30.1.0+wvcus", electronscan.FrameworkElectronCastlabs},
+		{"windows", "30.1.0", electronscan.FrameworkElectron},
+		{"windows", "30.1.0+wvcus", electronscan.FrameworkElectronCastlabs},
+	}
+	for _, tt := range tests {
+		fsys := fstest.MapFS{}
+		appPath, err := fixture.App{GOOS: tt.goos, ElectronVersion: tt.electronVersion}.Build(fsys, "Apps")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: fsys}).CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+		if result.Framework != tt.want {
+			t.Errorf("%s Electron %s detected as %q, want %q", tt.goos, tt.electronVersion, result.Framework, tt.want)
+		}
+		if result.Version != "30.1.0" {
+			t.Errorf("%s Electron %s has version %q", tt.goos, tt.electronVersion, result.Version)
+		}
+	}
+}
+
+func TestDetectFrameworkWindows(t *testing.T) {
+	// tauriAt places a Tauri string in a binary larger than one read, starting at offset
+	tauriAt := func(offset int) []byte {
+		exe := bytes.Repeat([]byte{0}, 3<<20)
+		copy(exe, "MZ")
+		copy(exe[offset:], "__TAURI__")
+		return exe
+	}
+	plain := []byte("MZ\x00\x00This program cannot be run in DOS mode")
+
+	sep := string(filepath.Separator)
+	tests := []struct {
+		name      string
+		exe       []byte
+		markers   []string
+		framework string
+		evidence  string
+	}{
+		{"Tauri binary", tauriAt(100), nil, electronscan.FrameworkTauri, "Demo.exe"},
+		{"Tauri string across the first read", tauriAt(1<<20 - 4), nil, electronscan.FrameworkTauri, "Demo.exe"},
+		{"Tauri string at the end", tauriAt(3<<20 - 9), nil, electronscan.FrameworkTauri, "Demo.exe"},
+		{"Tauri with the WebView2 loader", tauriAt(100), []string{"WebView2Loader.dll"}, electronscan.FrameworkTauri, "Demo.exe"},
+		{"WebView2", plain, []string{"WebView2Loader.dll"}, electronscan.FrameworkWebView2, "WebView2Loader.dll"},
+		{"NW.js marker wins over a Tauri string", tauriAt(100), []string{"nw.dll"}, electronscan.FrameworkNWJS, "nw.dll"},
+		{"CEF", plain, []string{"libcef.dll", "WebView2Loader.dll"}, electronscan.FrameworkCEF, "libcef.dll"},
+		{"nothing", plain, nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"Program Files/Demo/Demo.exe": {Data: tt.exe}}
+			for _, marker := range tt.markers {
+				fsys["Program Files/Demo/"+marker] = &fstest.MapFile{Data: []byte("MZ")}
+			}
+			s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+			framework, evidence := s.DetectFramework(filepath.Join(sep, "Program Files", "Demo", "Demo.exe"))
+			if framework != tt.framework || filepath.Base(evidence) != filepath.Base(tt.evidence) {
+				t.Errorf("framework = %q (%s), want %q (%s)", framework, evidence, tt.framework, tt.evidence)
+			}
+		})
+	}
+}
+
+func TestDetectFrameworkMacos(t *testing.T) {
+	// Tauri bundles often name their executable differently from the bundle
+	infoPlist := []byte(`<plist><dict><key>CFBundleExecutable</key><string>demo-app</string></dict></plist>`)
+	tests := []struct {
+		name      string
+		fsys      fstest.MapFS
+		framework string
+		evidence  string
+	}{
+		{"Tauri executable named in Info.plist", fstest.MapFS{
+			"Applications/Demo.app/Contents/Info.plist":     {Data: infoPlist},
+			"Applications/Demo.app/Contents/MacOS/demo-app": {Data: []byte("\xcf\xfa\xed\xfe tauri://localhost")},
+		}, electronscan.FrameworkTauri, "demo-app"},
+		{"NW.js framework wins over a Tauri string", fstest.MapFS{
+			"Applications/Demo.app/Contents/Info.plist":                                  {Data: infoPlist},
+			"Applications/Demo.app/Contents/MacOS/demo-app":                              {Data: []byte("__TAURI__")},
+			"Applications/Demo.app/Contents/Frameworks/nwjs Framework.framework/Version": {Data: []byte("1")},
+		}, electronscan.FrameworkNWJS, "nwjs Framework.framework"},
+		{"CEF", fstest.MapFS{
+			"Applications/Demo.app/Contents/Frameworks/Chromium Embedded Framework.framework/Chromium Embedded Framework": {},
+		}, electronscan.FrameworkCEF, "Chromium Embedded Framework.framework"},
+		{"native app", fstest.MapFS{
+			"Applications/Demo.app/Contents/MacOS/Demo": {Data: []byte("\xcf\xfa\xed\xfe")},
+		}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := electronscan.New(electronscan.Options{GOOS: "darwin", FS: tt.fsys})
+			framework, evidence := s.DetectFramework(filepath.Join(string(filepath.Separator), "Applications", "Demo.app"))
+			if framework != tt.framework || filepath.Base(evidence) != filepath.Base(tt.evidence) {
+				t.Errorf("framework = %q (%s), want %q (%s)", framework, evidence, tt.framework, tt.evidence)
+			}
+		})
+	}
+}
+
+func TestCheckNWJS(t *testing.T) {
+	// packageNw zips a manifest the way NW.js apps ship it next to the executable
+	packageNw := func(manifest string) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		f, err := w.Create("package.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(manifest)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		appPath  string
+		fsys     fstest.MapFS
+		findings []string
+	}{
+		{"read-only package.json", "windows", "Program Files/Notes/notes.exe", fstest.MapFS{
+			"Program Files/Notes/notes.exe":    {Data: []byte("MZ"), Mode: 0o755},
+			"Program Files/Notes/nw.dll":       {Data: []byte("MZ"), Mode: 0o644},
+			"Program Files/Notes/package.json": {Data: []byte(`{"main": "index.html"}`), Mode: 0o644},
+		}, nil},
+		{"writable package.json", "windows", "Program Files/Notes/notes.exe", fstest.MapFS{
+			"Program Files/Notes/notes.exe":    {Data: []byte("MZ"), Mode: 0o755},
+			"Program Files/Notes/nw.dll":       {Data: []byte("MZ"), Mode: 0o644},
+			"Program Files/Notes/package.json": {Data: []byte(`{"main": "index.html"}`), Mode: 0o666},
+		}, []string{"NW.js application code is user-writable"}},
+		{"manifest zipped in package.nw", "windows", "Program Files/Notes/notes.exe", fstest.MapFS{
+			"Program Files/Notes/notes.exe":  {Data: []byte("MZ"), Mode: 0o755},
+			"Program Files/Notes/package.nw": {Data: packageNw(`{"node-remote": ["*://*.example.com"], "chromium-args": "--disable-web-security --mixed-context"}`), Mode: 0o644},
+		}, []string{"node-remote grants Node.js to remote pages", "chromium-args disables web security"}},
+		{"app.nw folder", "darwin", "Applications/Notes.app", fstest.MapFS{
+			"Applications/Notes.app/Contents/Resources/app.nw/package.json": {Data: []byte(`{"main": "index.html", "node-remote": "*://*"}`), Mode: 0o644},
+		}, []string{"node-remote grants Node.js to remote pages"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: tt.fsys})
+			result := s.CheckApp(string(filepath.Separator) + filepath.FromSlash(tt.appPath))
+			if result.Framework != electronscan.FrameworkNWJS {
+				t.Fatalf("framework = %q", result.Framework)
+			}
+			if len(result.FrameworkFindings) != len(tt.findings) {
+				t.Fatalf("findings = %q, want %q", result.FrameworkFindings, tt.findings)
+			}
+			for i, finding := range result.FrameworkFindings {
+				if !strings.HasPrefix(finding, tt.findings[i]) {
+					t.Errorf("finding %d = %q, want %q", i, finding, tt.findings[i])
+				}
+			}
+			var rules []string
+			for _, finding := range electronscan.Findings(result) {
+				rules = append(rules, finding.RuleID)
+			}
+			if len(rules) != len(tt.findings) || slices.ContainsFunc(rules, func(id string) bool { return id != electronscan.RuleFrameworkWeakness }) {
+				t.Errorf("rules = %v, want one %s per finding", rules, electronscan.RuleFrameworkWeakness)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package electronscan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// fuseSentinel marks the start of the fuse wire compiled into Electron binaries
+const fuseSentinel = "dL7pKGdnNz796PbbjQWNKmHXBZaB9tsX"
+
+// Fuse states as reported in AppResult.Fuses
+const (
+	FuseEnabled  = "enabled"
+	FuseDisabled = "disabled"
+	FuseRemoved  = "removed"
+)
+
+// FuseNames lists the v1 fuses in wire order
+var FuseNames = []string{
+	"RunAsNode",
+	"EnableCookieEncryption",
+	"EnableNodeOptionsEnvironmentVariable",
+	"EnableNodeCliInspectArguments",
+	"EnableEmbeddedAsarIntegrityValidation",
+	"OnlyLoadAppFromAsar",
+	"LoadBrowserProcessSpecificV8Snapshot",
+	"GrantFileProtocolExtraPrivileges",
+}
+
+// ReadFuses reads the Electron fuse wire from the binary at ElectronBinaryPath:
+// on macOS that is the Electron Framework, not the app executable.
+// Fuses newer than this scanner are reported by their index.
+func (s *Scanner) ReadFuses(executablePath string) (map[string]string, error) {
+	content, err := s.readFile(executablePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading executable: %v", err)
+	}
+	return parseFuseWire(content)
+}
+
+// parseFuseWire decodes the fuse wire: sentinel, version byte, length byte,
+// then one byte per fuse ('0' disabled, '1' enabled, 'r' removed)
+func parseFuseWire(content []byte) (map[string]string, error) {
+	idx := bytes.Index(content, []byte(fuseSentinel))
+	if idx < 0 {
+		return nil, errors.New("fuse wire not found")
+	}
+
+	wire := content[idx+len(fuseSentinel):]
+	if len(wire) < 2 {
+		return nil, errors.New("truncated fuse wire")
+	}
+	if version := wire[0]; version != 1 {
+		return nil, fmt.Errorf("unsupported fuse wire version: %d", version)
+	}
+	length := int(wire[1])
+	if len(wire) < 2+length {
+		return nil, errors.New("truncated fuse wire")
+	}
+
+	fuses := make(map[string]string, length)
+	for i, state := range wire[2 : 2+length] {
+		name := fmt.Sprintf("Fuse%d", i)
+		if i < len(FuseNames) {
+			name = FuseNames[i]
+		}
+
+		switch state {
+		case '1':
+			fuses[name] = FuseEnabled
+		case '0':
+			fuses[name] = FuseDisabled
+		case 'r':
+			fuses[name] = FuseRemoved
+		default:
+			fuses[name] = fmt.Sprintf("unknown(0x%02x)", state)
+		}
+	}
+
+	return fuses, nil
+}
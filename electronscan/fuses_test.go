@@ -0,0 +1,79 @@
+package electronscan_test
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+func TestReadFuses(t *testing.T) {
+	const sentinel = "dL7pKGdnNz796PbbjQWNKmHXBZaB9tsX"
+	tests := []struct {
+		name   string
+		binary string
+		want   map[string]string
+		err    string
+	}{
+		{
+			name:   "states",
+			binary: "\x7fELF padding " + sentinel + "\x01\x03" + "10r" + " more",
+			want: map[string]string{
+				"RunAsNode":                            electronscan.FuseEnabled,
+				"EnableCookieEncryption":               electronscan.FuseDisabled,
+				"EnableNodeOptionsEnvironmentVariable": electronscan.FuseRemoved,
+			},
+		},
+		{
+			name:   "newer fuses and states than the scanner knows",
+			binary: sentinel + "\x01\x0a" + "00000000" + "1x",
+			want: map[string]string{
+				"RunAsNode":                             electronscan.FuseDisabled,
+				"EnableCookieEncryption":                electronscan.FuseDisabled,
+				"EnableNodeOptionsEnvironmentVariable":  electronscan.FuseDisabled,
+				"EnableNodeCliInspectArguments":         electronscan.FuseDisabled,
+				"EnableEmbeddedAsarIntegrityValidation": electronscan.FuseDisabled,
+				"OnlyLoadAppFromAsar":                   electronscan.FuseDisabled,
+				"LoadBrowserProcessSpecificV8Snapshot":  electronscan.FuseDisabled,
+				"GrantFileProtocolExtraPrivileges":      electronscan.FuseDisabled,
+				"Fuse8":                                 electronscan.FuseEnabled,
+				"Fuse9":                                 "unknown(0x78)",
+			},
+		},
+		{
+			name:   "empty wire",
+			binary: sentinel + "\x01\x00",
+			want:   map[string]string{},
+		},
+		{name: "no sentinel", binary: "MZ not electron", err: "fuse wire not found"},
+		{name: "cut after the sentinel", binary: sentinel + "\x01", err: "truncated"},
+		{name: "shorter than its length", binary: sentinel + "\x01\x08" + "101", err: "truncated"},
+		{name: "unknown wire version", binary: sentinel + "\x02\x01" + "1", err: "unsupported fuse wire version: 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := electronscan.New(electronscan.Options{FS: fstest.MapFS{"Electron": {Data: []byte(tt.binary)}}})
+			fuses, err := s.ReadFuses(string(filepath.Separator) + "Electron")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("error = %v, want it to mention %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(fuses, tt.want) {
+				t.Errorf("fuses = %v, want %v", fuses, tt.want)
+			}
+		})
+	}
+
+	s := electronscan.New(electronscan.Options{FS: fstest.MapFS{}})
+	if _, err := s.ReadFuses(string(filepath.Separator) + "Electron"); err == nil {
+		t.Error("missing binary read without an error")
+	}
+}
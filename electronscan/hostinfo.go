@@ -0,0 +1,165 @@
+package electronscan
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Privilege levels reported in ScanMetadata.Privilege
+const (
+	PrivilegeRoot          = "root"
+	PrivilegeAdministrator = "administrator"
+	PrivilegeUser          = "user"
+)
+
+// Files of a scanned system that describe it
+const (
+	systemVersionPlist       = "/System/Library/CoreServices/SystemVersion.plist"
+	systemConfigurationPlist = "/Library/Preferences/SystemConfiguration/preferences.plist"
+)
+
+// windowsHive returns the path of a machine-wide registry hive in the scanned filesystem
+func (s *Scanner) windowsHive(name string) string {
+	root, _ := s.windowsSystemDrive()
+	return filepath.Join(root, "Windows", "System32", "config", name)
+}
+
+// hostName returns the name of the system scanned: the host's own, or the
+// one configured in an image, or "" if the image does not record it
+func (s *Scanner) hostName() string {
+	if s.isHost() {
+		host, _ := os.Hostname()
+		return host
+	}
+
+	switch s.goos {
+	case "darwin":
+		content, err := s.readPlist(systemConfigurationPlist)
+		if err != nil {
+			return ""
+		}
+		if host := plistString(content, "HostName"); host != "" {
+			return host
+		}
+		return plistString(content, "LocalHostName")
+	case "windows":
+		hive := s.openHive(s.windowsHive("SYSTEM"))
+		if hive == nil {
+			return ""
+		}
+		return hiveString(hive, currentControlSet(hive)+`\Control\ComputerName\ComputerName`, "ComputerName")
+	}
+
+	content, err := s.readFile("/etc/hostname")
+	if err != nil {
+		return ""
+	}
+	host, _, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+	return strings.TrimSpace(host)
+}
+
+// arch returns the CPU architecture of the system scanned in GOARCH terms:
+// the host's, or in an image the one Windows records or the one /bin/sh is
+// built for, or "" if that is unknown or a universal binary
+func (s *Scanner) arch() string {
+	if s.isHost() {
+		return runtime.GOARCH
+	}
+
+	if s.goos == "windows" {
+		hive := s.openHive(s.windowsHive("SYSTEM"))
+		if hive == nil {
+			return ""
+		}
+		switch hiveString(hive, currentControlSet(hive)+`\Control\Session Manager\Environment`, "PROCESSOR_ARCHITECTURE") {
+		case "AMD64":
+			return "amd64"
+		case "x86":
+			return "386"
+		case "ARM64":
+			return "arm64"
+		}
+		return ""
+	}
+
+	_, archs := s.binaryArchitectures("/bin/sh")
+	if len(archs) != 1 {
+		return ""
+	}
+	switch archs[0] {
+	case "x86_64":
+		return "amd64"
+	case "x86":
+		return "386"
+	case "arm64", "arm":
+		return archs[0]
+	}
+	return ""
+}
+
+// osVersion describes the operating system scanned, e.g. "macOS 14.5 (23F79)",
+// or returns "" if it cannot be determined
+func (s *Scanner) osVersion() string {
+	switch s.goos {
+	case "darwin":
+		content, err := s.readPlist(systemVersionPlist)
+		if err != nil {
+			return ""
+		}
+		name := plistString(content, "ProductName")
+		version := plistString(content, "ProductVersion")
+		if build := plistString(content, "ProductBuildVersion"); build != "" {
+			version += " (" + build + ")"
+		}
+		return strings.TrimSpace(name + " " + version)
+	case "windows":
+		if s.isHost() {
+			return windowsVersion()
+		}
+		hive := s.openHive(s.windowsHive("SOFTWARE"))
+		if hive == nil {
+			return ""
+		}
+		key := `Microsoft\Windows NT\CurrentVersion`
+		version := strings.TrimSpace(hiveString(hive, key, "ProductName") + " " + hiveString(hive, key, "DisplayVersion"))
+		if build := hiveString(hive, key, "CurrentBuild"); build != "" {
+			version += " (build " + build + ")"
+		}
+		return strings.TrimSpace(version)
+	}
+
+	if content, err := s.readFile("/etc/os-release"); err == nil {
+		return osReleaseName(content)
+	}
+	return ""
+}
+
+// plistString returns the string value of a top-level key in an XML property list
+func plistString(content []byte, key string) string {
+	re := regexp.MustCompile(`<key>` + regexp.QuoteMeta(key) + `</key>\s*<string>([^<]*)</string>`)
+	if matches := re.FindSubmatch(content); len(matches) > 1 {
+		return strings.TrimSpace(string(matches[1]))
+	}
+	return ""
+}
+
+// osReleaseName reads PRETTY_NAME, or NAME and VERSION, from an os-release file
+func osReleaseName(content []byte) string {
+	fields := make(map[string]string)
+	lines := bufio.NewScanner(bytes.NewReader(content))
+	for lines.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(lines.Text()), "=")
+		if ok {
+			fields[key] = strings.Trim(value, `"'`)
+		}
+	}
+	if fields["PRETTY_NAME"] != "" {
+		return fields["PRETTY_NAME"]
+	}
+	return strings.TrimSpace(fields["NAME"] + " " + fields["VERSION"])
+}
@@ -0,0 +1,109 @@
+package electronscan_test
+
+import (
+	"encoding/binary"
+	"os"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+)
+
+func TestReportDescribesImage(t *testing.T) {
+	current := make([]byte, 4)
+	binary.LittleEndian.PutUint32(current, 2)
+	system := registry.Marshal(map[string][]registry.Value{
+		`Select`: {{Name: "Current", Type: registry.TypeDWord, Data: current}},
+		`ControlSet001\Control\ComputerName\ComputerName`:   {registry.StringValue("ComputerName", "OLD-NAME")},
+		`ControlSet002\Control\ComputerName\ComputerName`:   {registry.StringValue("ComputerName", "FIN-LAPTOP-12")},
+		`ControlSet002\Control\Session Manager\Environment`: {registry.StringValue("PROCESSOR_ARCHITECTURE", "ARM64")},
+	})
+	software := registry.Marshal(map[string][]registry.Value{
+		`Microsoft\Windows NT\CurrentVersion`: {
+			registry.StringValue("ProductName", "Windows 10 Enterprise"),
+			registry.StringValue("DisplayVersion", "23H2"),
+			registry.StringValue("CurrentBuild", "22631"),
+		},
+	})
+
+	// Whatever the test binary is built for, it stands in for the image's shell
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shell, err := os.ReadFile(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		goos      string
+		fsys      fstest.MapFS
+		host      string
+		osVersion string
+		arch      string
+	}{
+		{
+			name: "macOS",
+			goos: "darwin",
+			fsys: fstest.MapFS{
+				"System/Library/CoreServices/SystemVersion.plist": {Data: []byte(`<plist><dict>
+<key>ProductBuildVersion</key><string>24D60</string>
+<key>ProductName</key><string>macOS</string>
+<key>ProductVersion</key><string>15.3</string>
+</dict></plist>`)},
+				"Library/Preferences/SystemConfiguration/preferences.plist": {Data: []byte(`<plist><dict>
+<key>System</key><dict><key>Network</key><dict><key>HostNames</key><dict>
+<key>LocalHostName</key><string>design-mbp</string>
+</dict></dict></dict></dict></plist>`)},
+			},
+			host:      "design-mbp",
+			osVersion: "macOS 15.3 (24D60)",
+		},
+		{
+			name: "Windows",
+			goos: "windows",
+			fsys: fstest.MapFS{
+				"Windows/System32/config/SYSTEM":   {Data: system},
+				"Windows/System32/config/SOFTWARE": {Data: software},
+			},
+			host:      "FIN-LAPTOP-12",
+			osVersion: "Windows 10 Enterprise 23H2 (build 22631)",
+			arch:      "arm64",
+		},
+		{
+			name: "Linux",
+			goos: "linux",
+			fsys: fstest.MapFS{
+				"etc/hostname":   {Data: []byte("build-07\n")},
+				"etc/os-release": {Data: []byte("NAME=\"Ubuntu\"\nPRETTY_NAME=\"Ubuntu 24.04.1 LTS\"\n")},
+				"bin/sh":         {Data: shell},
+			},
+			host:      "build-07",
+			osVersion: "Ubuntu 24.04.1 LTS",
+			arch:      runtime.GOARCH,
+		},
+		{
+			name: "nothing recorded",
+			goos: "windows",
+			fsys: fstest.MapFS{"Windows/System32/config/SYSTEM": {Data: []byte("not a hive")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: tt.fsys})
+			metadata := s.NewReport(nil, "test", time.Now(), time.Now()).Metadata
+			if metadata.Host != tt.host || metadata.OSVersion != tt.osVersion || metadata.Arch != tt.arch {
+				t.Errorf("host, OS version, arch = %q, %q, %q, want %q, %q, %q",
+					metadata.Host, metadata.OSVersion, metadata.Arch, tt.host, tt.osVersion, tt.arch)
+			}
+			if metadata.OS != tt.goos {
+				t.Errorf("OS = %q, want %q", metadata.OS, tt.goos)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package electronscan
+
+import "os"
+
+// privilegeLevel reports whether the scan runs as root
+func privilegeLevel() string {
+	if os.Geteuid() == 0 {
+		return PrivilegeRoot
+	}
+	return PrivilegeUser
+}
+
+// windowsVersion is only available on Windows
+func windowsVersion() string {
+	return ""
+}
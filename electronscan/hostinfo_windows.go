@@ -0,0 +1,68 @@
+//go:build windows
+
+package electronscan
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// tokenElevation is the TOKEN_INFORMATION_CLASS value for TOKEN_ELEVATION
+const tokenElevation = 20
+
+// privilegeLevel reports whether the scan runs elevated
+func privilegeLevel() string {
+	token, err := syscall.OpenCurrentProcessToken()
+	if err != nil {
+		return ""
+	}
+	defer token.Close()
+
+	var elevated, size uint32
+	if err := syscall.GetTokenInformation(token, tokenElevation, (*byte)(unsafe.Pointer(&elevated)), uint32(unsafe.Sizeof(elevated)), &size); err != nil {
+		return ""
+	}
+	if elevated != 0 {
+		return PrivilegeAdministrator
+	}
+	return PrivilegeUser
+}
+
+// windowsVersion reads the product name, release and build from the registry,
+// e.g. "Windows 10 Pro 22H2 (build 19045)"
+func windowsVersion() string {
+	path, err := syscall.UTF16PtrFromString(`SOFTWARE\Microsoft\Windows NT\CurrentVersion`)
+	if err != nil {
+		return ""
+	}
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, path, 0, syscall.KEY_READ, &key); err != nil {
+		return ""
+	}
+	defer syscall.RegCloseKey(key)
+
+	version := strings.TrimSpace(registryString(key, "ProductName") + " " + registryString(key, "DisplayVersion"))
+	if build := registryString(key, "CurrentBuild"); build != "" {
+		version += fmt.Sprintf(" (build %s)", build)
+	}
+	return strings.TrimSpace(version)
+}
+
+// registryString reads a REG_SZ value, or returns "" if it is missing
+func registryString(key syscall.Handle, name string) string {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return ""
+	}
+	var valueType, size uint32
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size); err != nil || valueType != syscall.REG_SZ || size < 2 {
+		return ""
+	}
+	buf := make([]uint16, size/2)
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
@@ -0,0 +1,279 @@
+// Package jsonschema validates JSON documents against the subset of JSON Schema
+// (draft 2020-12) used by the published report schema: type, properties,
+// required, additionalProperties, items, enum, const, pattern, minimum,
+// format "date-time" and local "$ref"s into "$defs".
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Schema is a parsed schema node
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*Schema `json:"$defs"`
+	Type                 typeList           `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Const                any                `json:"const"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *json.Number       `json:"minimum"`
+	Format               string             `json:"format"`
+
+	pattern *regexp.Regexp
+}
+
+// typeList accepts "type" as a single name or a list of names
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = typeList{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// additional is additionalProperties, either a boolean or a schema
+type additional struct {
+	allowed bool
+	schema  *Schema
+}
+
+func (a *additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+	a.allowed = true
+	return json.Unmarshal(data, &a.schema)
+}
+
+// Parse reads a schema and compiles its patterns
+func Parse(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %v", err)
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// compile prepares the patterns of a node and its children
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, child := range s.Defs {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	for _, child := range s.Properties {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.schema.compile(); err != nil {
+			return err
+		}
+	}
+	return s.Items.compile()
+}
+
+// ValidationError lists every place a document breaks the schema
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate checks a JSON document against the schema. It returns a
+// *ValidationError if the document parses but does not conform.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("error parsing document: %v", err)
+	}
+
+	v := &validator{root: s}
+	v.check(s, doc, "$")
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validator collects problems while walking a document
+type validator struct {
+	root     *Schema
+	problems []string
+}
+
+func (v *validator) fail(at string, format string, args ...any) {
+	v.problems = append(v.problems, at+": "+fmt.Sprintf(format, args...))
+}
+
+// resolve follows a "#/$defs/name" reference
+func (v *validator) resolve(ref string) *Schema {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil
+	}
+	return v.root.Defs[name]
+}
+
+// check validates one value against one schema node
+func (v *validator) check(s *Schema, value any, at string) {
+	if s.Ref != "" {
+		target := v.resolve(s.Ref)
+		if target == nil {
+			v.fail(at, "unresolvable reference %s", s.Ref)
+			return
+		}
+		s = target
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(value, t) }) {
+		v.fail(at, "expected %s, got %s", strings.Join(s.Type, " or "), typeName(value))
+		return
+	}
+	if s.Const != nil && !equal(value, s.Const) {
+		v.fail(at, "must be %v", s.Const)
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(value, e) }) {
+		v.fail(at, "%v is not one of %v", value, s.Enum)
+	}
+
+	switch value := value.(type) {
+	case string:
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			v.fail(at, "%q does not match %s", value, s.Pattern)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				v.fail(at, "%q is not an RFC 3339 date-time", value)
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil && compareNumbers(value, *s.Minimum) < 0 {
+			v.fail(at, "%s is less than %s", value, *s.Minimum)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range value {
+				v.check(s.Items, item, fmt.Sprintf("%s[%d]", at, i))
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				v.fail(at, "missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			child := at + "." + name
+			if property, ok := s.Properties[name]; ok {
+				v.check(property, value[name], child)
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.allowed {
+				v.fail(at, "unexpected property %q", name)
+			} else if s.AdditionalProperties.schema != nil {
+				v.check(s.AdditionalProperties.schema, value[name], child)
+			}
+		}
+	}
+}
+
+// hasType reports whether a decoded value is of a JSON Schema type
+func hasType(value any, t string) bool {
+	switch value := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		_, ok := new(big.Int).SetString(value.String(), 10)
+		return t == "integer" && ok
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+// typeName names the JSON type of a decoded value for error messages
+func typeName(value any) string {
+	for _, t := range []string{"null", "boolean", "string", "integer", "number", "array", "object"} {
+		if hasType(value, t) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares a document value with a schema constant
+func equal(value any, constant any) bool {
+	if n, ok := value.(json.Number); ok {
+		if c, ok := constant.(float64); ok {
+			f, err := n.Float64()
+			return err == nil && f == c
+		}
+		return false
+	}
+	return value == constant
+}
+
+// compareNumbers compares two JSON numbers
+func compareNumbers(a json.Number, b json.Number) int {
+	x, _ := new(big.Float).SetString(a.String())
+	y, _ := new(big.Float).SetString(b.String())
+	if x == nil || y == nil {
+		return 0
+	}
+	return x.Cmp(y)
+}
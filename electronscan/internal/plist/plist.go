@@ -0,0 +1,582 @@
+// Package plist reads and writes XML and binary property lists.
+//
+// Values map to Go types as follows: dict to map[string]any, array to []any,
+// string to string, integer to int64, real to float64, boolean to bool,
+// data to []byte, date to time.Time and the UIDs of keyed archives to UID.
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// binaryMagic starts every binary property list
+const binaryMagic = "bplist00"
+
+// xmlHeader is what Apple tools write before the root value
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// maxDepth bounds nesting so a malicious file cannot recurse forever
+const maxDepth = 64
+
+// appleEpoch is the reference date of plist dates
+var appleEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// UID is an object reference in an NSKeyedArchiver archive, an index into its $objects
+type UID uint64
+
+// IsBinary reports whether data is a binary property list
+func IsBinary(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(binaryMagic))
+}
+
+// ToXML returns data as an XML property list, converting it if it is binary
+func ToXML(data []byte) ([]byte, error) {
+	if !IsBinary(data) {
+		return data, nil
+	}
+	v, err := DecodeBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalXML(v)
+}
+
+// binaryDecoder walks the object table of a binary property list
+type binaryDecoder struct {
+	data       []byte
+	offsets    []uint64
+	refSize    int
+	inProgress map[uint64]bool
+}
+
+// DecodeBinary parses a binary property list
+func DecodeBinary(data []byte) (any, error) {
+	if !IsBinary(data) || len(data) < len(binaryMagic)+32 {
+		return nil, errors.New("not a binary property list")
+	}
+
+	trailer := data[len(data)-32:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:16])
+	topObject := binary.BigEndian.Uint64(trailer[16:24])
+	offsetTable := binary.BigEndian.Uint64(trailer[24:32])
+
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 {
+		return nil, errors.New("invalid binary property list trailer")
+	}
+	tableEnd := uint64(len(data) - 32)
+	if offsetTable > tableEnd || numObjects > (tableEnd-offsetTable)/uint64(offsetSize) || topObject >= numObjects {
+		return nil, errors.New("invalid binary property list offset table")
+	}
+
+	d := &binaryDecoder{data: data, refSize: refSize, inProgress: make(map[uint64]bool)}
+	d.offsets = make([]uint64, numObjects)
+	for i := range d.offsets {
+		start := offsetTable + uint64(i*offsetSize)
+		d.offsets[i] = readUint(data[start : start+uint64(offsetSize)])
+	}
+	return d.object(topObject, 0)
+}
+
+// readUint reads a big-endian unsigned integer of up to 8 bytes
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// bytesAt returns n bytes at off, or an error if they run past the object table
+func (d *binaryDecoder) bytesAt(off uint64, n uint64) ([]byte, error) {
+	end := uint64(len(d.data) - 32)
+	if off > end || n > end-off {
+		return nil, errors.New("truncated binary property list")
+	}
+	return d.data[off : off+n], nil
+}
+
+// count returns the element count of an object and where its payload starts.
+// A low nibble of 0xF means the count follows as an integer object.
+func (d *binaryDecoder) count(off uint64, marker byte) (uint64, uint64, error) {
+	if marker&0x0F != 0x0F {
+		return uint64(marker & 0x0F), off + 1, nil
+	}
+	head, err := d.bytesAt(off+1, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if head[0]&0xF0 != 0x10 {
+		return 0, 0, errors.New("invalid object count")
+	}
+	size := uint64(1) << (head[0] & 0x0F)
+	if size > 8 {
+		return 0, 0, errors.New("invalid object count")
+	}
+	b, err := d.bytesAt(off+2, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	return readUint(b), off + 2 + size, nil
+}
+
+// refs reads n object references starting at off
+func (d *binaryDecoder) refs(off uint64, n uint64) ([]uint64, error) {
+	if n > uint64(len(d.data)) {
+		return nil, errors.New("invalid object count")
+	}
+	b, err := d.bytesAt(off, n*uint64(d.refSize))
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, n)
+	for i := range refs {
+		refs[i] = readUint(b[i*d.refSize : (i+1)*d.refSize])
+	}
+	return refs, nil
+}
+
+// object decodes the object with the given index
+func (d *binaryDecoder) object(ref uint64, depth int) (any, error) {
+	if ref >= uint64(len(d.offsets)) {
+		return nil, fmt.Errorf("object reference %d out of range", ref)
+	}
+	if depth > maxDepth || d.inProgress[ref] {
+		return nil, errors.New("binary property list nests too deeply")
+	}
+	d.inProgress[ref] = true
+	defer delete(d.inProgress, ref)
+
+	off := d.offsets[ref]
+	head, err := d.bytesAt(off, 1)
+	if err != nil {
+		return nil, err
+	}
+	marker := head[0]
+
+	switch marker & 0xF0 {
+	case 0x00:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+		return nil, nil
+	case 0x10:
+		size := uint64(1) << (marker & 0x0F)
+		b, err := d.bytesAt(off+1, size)
+		if err != nil {
+			return nil, err
+		}
+		if size == 16 {
+			// 128-bit integers only hold 64-bit values in practice
+			b = b[8:]
+		}
+		return int64(readUint(b)), nil
+	case 0x20:
+		size := uint64(1) << (marker & 0x0F)
+		b, err := d.bytesAt(off+1, size)
+		if err != nil {
+			return nil, err
+		}
+		switch size {
+		case 4:
+			return float64(math.Float32frombits(uint32(readUint(b)))), nil
+		case 8:
+			return math.Float64frombits(readUint(b)), nil
+		}
+		return nil, errors.New("invalid real size")
+	case 0x30:
+		b, err := d.bytesAt(off+1, 8)
+		if err != nil {
+			return nil, err
+		}
+		seconds := math.Float64frombits(readUint(b))
+		return appleEpoch.Add(time.Duration(seconds * float64(time.Second))), nil
+	case 0x40, 0x50, 0x60:
+		n, start, err := d.count(off, marker)
+		if err != nil {
+			return nil, err
+		}
+		if marker&0xF0 == 0x60 {
+			if n > uint64(len(d.data)) {
+				return nil, errors.New("invalid string length")
+			}
+			b, err := d.bytesAt(start, n*2)
+			if err != nil {
+				return nil, err
+			}
+			units := make([]uint16, n)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(b[i*2:])
+			}
+			return string(utf16.Decode(units)), nil
+		}
+		b, err := d.bytesAt(start, n)
+		if err != nil {
+			return nil, err
+		}
+		if marker&0xF0 == 0x40 {
+			return bytes.Clone(b), nil
+		}
+		return string(b), nil
+	case 0x80:
+		size := uint64(marker&0x0F) + 1
+		b, err := d.bytesAt(off+1, size)
+		if err != nil {
+			return nil, err
+		}
+		return UID(readUint(b)), nil
+	case 0xA0:
+		n, start, err := d.count(off, marker)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := d.refs(start, n)
+		if err != nil {
+			return nil, err
+		}
+		array := make([]any, 0, n)
+		for _, r := range refs {
+			v, err := d.object(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+	case 0xD0:
+		n, start, err := d.count(off, marker)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := d.refs(start, n*2)
+		if err != nil {
+			return nil, err
+		}
+		dict := make(map[string]any, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.object(refs[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, errors.New("dictionary key is not a string")
+			}
+			if dict[name], err = d.object(refs[n+i], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("unknown object marker 0x%02x", marker)
+}
+
+// MarshalXML writes v as an XML property list. Dictionary keys are sorted.
+func MarshalXML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xmlHeader)
+	if err := writeXML(&buf, v, 0); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</plist>\n")
+	return buf.Bytes(), nil
+}
+
+// writeXML writes one value at the given indentation
+func writeXML(buf *bytes.Buffer, v any, depth int) error {
+	indent := bytes.Repeat([]byte("\t"), depth)
+	buf.Write(indent)
+
+	switch v := v.(type) {
+	case map[string]any:
+		buf.WriteString("<dict>\n")
+		for _, key := range sortedKeys(v) {
+			buf.Write(indent)
+			buf.WriteString("\t<key>")
+			escapeXML(buf, key)
+			buf.WriteString("</key>\n")
+			if err := writeXML(buf, v[key], depth+1); err != nil {
+				return err
+			}
+		}
+		buf.Write(indent)
+		buf.WriteString("</dict>\n")
+		return nil
+	case []any:
+		buf.WriteString("<array>\n")
+		for _, item := range v {
+			if err := writeXML(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.Write(indent)
+		buf.WriteString("</array>\n")
+		return nil
+	case string:
+		buf.WriteString("<string>")
+		escapeXML(buf, v)
+		buf.WriteString("</string>\n")
+	case bool:
+		if v {
+			buf.WriteString("<true/>\n")
+		} else {
+			buf.WriteString("<false/>\n")
+		}
+	case int:
+		fmt.Fprintf(buf, "<integer>%d</integer>\n", v)
+	case int64:
+		fmt.Fprintf(buf, "<integer>%d</integer>\n", v)
+	case float64:
+		fmt.Fprintf(buf, "<real>%s</real>\n", strconv.FormatFloat(v, 'g', -1, 64))
+	case []byte:
+		fmt.Fprintf(buf, "<data>%s</data>\n", base64.StdEncoding.EncodeToString(v))
+	case time.Time:
+		fmt.Fprintf(buf, "<date>%s</date>\n", v.UTC().Format(time.RFC3339))
+	case UID:
+		// XML has no UID type; CoreFoundation writes them as a one-key dictionary
+		fmt.Fprintf(buf, "<dict>\n%s\t<key>CF$UID</key>\n%s\t<integer>%d</integer>\n%s</dict>\n", indent, indent, v, indent)
+	default:
+		return fmt.Errorf("unsupported property list value %T", v)
+	}
+	return nil
+}
+
+// escapeXML writes s with XML special characters escaped
+func escapeXML(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+// binaryEncoder lays out objects in the order they are visited
+type binaryEncoder struct {
+	objects [][]byte
+	refSize int
+}
+
+// MarshalBinary writes v as a binary property list. Dictionary keys are sorted.
+func MarshalBinary(v any) ([]byte, error) {
+	total, err := countObjects(v)
+	if err != nil {
+		return nil, err
+	}
+	e := &binaryEncoder{refSize: minBytes(uint64(total))}
+	if _, err := e.add(v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	offsets := make([]uint64, len(e.objects))
+	for i, object := range e.objects {
+		offsets[i] = uint64(buf.Len())
+		buf.Write(object)
+	}
+
+	offsetTable := uint64(buf.Len())
+	offsetSize := minBytes(offsetTable)
+	for _, off := range offsets {
+		buf.Write(uintBytes(off, offsetSize))
+	}
+
+	trailer := make([]byte, 32)
+	trailer[6] = byte(offsetSize)
+	trailer[7] = byte(e.refSize)
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(e.objects)))
+	binary.BigEndian.PutUint64(trailer[16:], 0)
+	binary.BigEndian.PutUint64(trailer[24:], offsetTable)
+	buf.Write(trailer)
+	return buf.Bytes(), nil
+}
+
+// countObjects returns how many objects v is written as, which sizes the references
+func countObjects(v any) (int, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		n := 1
+		for _, item := range v {
+			c, err := countObjects(item)
+			if err != nil {
+				return 0, err
+			}
+			n += 1 + c
+		}
+		return n, nil
+	case []any:
+		n := 1
+		for _, item := range v {
+			c, err := countObjects(item)
+			if err != nil {
+				return 0, err
+			}
+			n += c
+		}
+		return n, nil
+	case string, bool, int, int64, float64, []byte, time.Time, UID:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unsupported property list value %T", v)
+	}
+}
+
+// add appends v and its children and returns its object index
+func (e *binaryEncoder) add(v any) (int, error) {
+	index := len(e.objects)
+	e.objects = append(e.objects, nil)
+
+	var object []byte
+	switch v := v.(type) {
+	case map[string]any:
+		keys := sortedKeys(v)
+		object = markerWithCount(0xD0, len(keys))
+		var keyRefs, valueRefs []byte
+		for _, key := range keys {
+			ref, err := e.add(key)
+			if err != nil {
+				return 0, err
+			}
+			keyRefs = append(keyRefs, uintBytes(uint64(ref), e.refSize)...)
+		}
+		for _, key := range keys {
+			ref, err := e.add(v[key])
+			if err != nil {
+				return 0, err
+			}
+			valueRefs = append(valueRefs, uintBytes(uint64(ref), e.refSize)...)
+		}
+		object = append(append(object, keyRefs...), valueRefs...)
+	case []any:
+		object = markerWithCount(0xA0, len(v))
+		for _, item := range v {
+			ref, err := e.add(item)
+			if err != nil {
+				return 0, err
+			}
+			object = append(object, uintBytes(uint64(ref), e.refSize)...)
+		}
+	case string:
+		if isASCII(v) {
+			object = append(markerWithCount(0x50, len(v)), v...)
+		} else {
+			units := utf16.Encode([]rune(v))
+			object = markerWithCount(0x60, len(units))
+			for _, u := range units {
+				object = binary.BigEndian.AppendUint16(object, u)
+			}
+		}
+	case bool:
+		object = []byte{0x08}
+		if v {
+			object = []byte{0x09}
+		}
+	case int:
+		object = intObject(int64(v))
+	case int64:
+		object = intObject(v)
+	case float64:
+		object = binary.BigEndian.AppendUint64([]byte{0x23}, math.Float64bits(v))
+	case []byte:
+		object = append(markerWithCount(0x40, len(v)), v...)
+	case time.Time:
+		seconds := v.Sub(appleEpoch).Seconds()
+		object = binary.BigEndian.AppendUint64([]byte{0x33}, math.Float64bits(seconds))
+	case UID:
+		size := minBytes(uint64(v))
+		object = append([]byte{0x80 | byte(size-1)}, uintBytes(uint64(v), size)...)
+	default:
+		return 0, fmt.Errorf("unsupported property list value %T", v)
+	}
+
+	e.objects[index] = object
+	return index, nil
+}
+
+// markerWithCount encodes an object marker with its element count
+func markerWithCount(marker byte, n int) []byte {
+	if n < 0x0F {
+		return []byte{marker | byte(n)}
+	}
+	return append([]byte{marker | 0x0F}, intObject(int64(n))...)
+}
+
+// intObject encodes an integer object in the smallest size that holds it.
+// Negative numbers always take 8 bytes.
+func intObject(n int64) []byte {
+	if n < 0 {
+		return binary.BigEndian.AppendUint64([]byte{0x13}, uint64(n))
+	}
+	size := minBytes(uint64(n))
+	if size > 4 {
+		size = 8
+	} else if size == 3 {
+		size = 4
+	}
+	nibble := map[int]byte{1: 0, 2: 1, 4: 2, 8: 3}[size]
+	return append([]byte{0x10 | nibble}, uintBytes(uint64(n), size)...)
+}
+
+// minBytes returns how many bytes are needed to hold n
+func minBytes(n uint64) int {
+	size := 1
+	for n > 0xFF {
+		n >>= 8
+		size++
+	}
+	return size
+}
+
+// uintBytes encodes n big-endian in size bytes
+func uintBytes(n uint64, size int) []byte {
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
+
+// isASCII reports whether s can be stored as an ASCII string object
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the keys of a dictionary in order
+func sortedKeys(dict map[string]any) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
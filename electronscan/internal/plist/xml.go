@@ -0,0 +1,186 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decode parses an XML or binary property list
+func Decode(data []byte) (any, error) {
+	if IsBinary(data) {
+		return DecodeBinary(data)
+	}
+	return decodeXML(data)
+}
+
+// xmlDecoder reads the values of an XML property list
+type xmlDecoder struct {
+	*xml.Decoder
+}
+
+// decodeXML parses the value inside the <plist> element
+func decodeXML(data []byte) (any, error) {
+	d := xmlDecoder{xml.NewDecoder(bytes.NewReader(data))}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("not a property list: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "plist" {
+			// A bare value without the <plist> wrapper
+			return d.value(start, 0)
+		}
+		if start, err = d.nextStart(); err != nil {
+			return nil, err
+		}
+		return d.value(start, 0)
+	}
+}
+
+// nextStart skips to the next start element
+func (d xmlDecoder) nextStart() (xml.StartElement, error) {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			return token, nil
+		case xml.EndElement:
+			return xml.StartElement{}, errors.New("empty property list")
+		}
+	}
+}
+
+// value reads the element that start opened
+func (d xmlDecoder) value(start xml.StartElement, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("property list nests too deeply")
+	}
+
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		key := ""
+		haveKey := false
+		for {
+			token, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				if token.Name.Local == "key" {
+					if key, err = d.text(); err != nil {
+						return nil, err
+					}
+					haveKey = true
+					continue
+				}
+				if !haveKey {
+					return nil, errors.New("dictionary value without a key")
+				}
+				if dict[key], err = d.value(token, depth+1); err != nil {
+					return nil, err
+				}
+				haveKey = false
+			case xml.EndElement:
+				if uid, ok := dict["CF$UID"].(int64); ok && len(dict) == 1 {
+					return UID(uid), nil
+				}
+				return dict, nil
+			}
+		}
+	case "array":
+		array := []any{}
+		for {
+			token, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				v, err := d.value(token, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, v)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := d.text()
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		text = strings.TrimSpace(text)
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		n, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return int64(n), nil
+	case "real":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q", text)
+		}
+		return f, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data: %v", err)
+		}
+		return b, nil
+	case "date":
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", text)
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown property list element <%s>", start.Name.Local)
+}
+
+// text reads the character data of an element up to its end
+func (d xmlDecoder) text() (string, error) {
+	var b strings.Builder
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			b.Write(token)
+		case xml.StartElement:
+			return "", fmt.Errorf("unexpected <%s> in text", token.Name.Local)
+		case xml.EndElement:
+			return b.String(), nil
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// node is a key being written and its children
+type node struct {
+	name     string
+	children map[string]*node
+	values   []Value
+}
+
+// hiveWriter lays out cells in one hive bin
+type hiveWriter struct {
+	cells []byte
+}
+
+// Marshal writes a hive holding the given values, keyed by path relative to
+// the root such as `Microsoft\Windows\CurrentVersion\Run`. Parent keys are
+// created as needed.
+func Marshal(keys map[string][]Value) []byte {
+	root := &node{name: "ROOT", children: map[string]*node{}}
+	for path, values := range keys {
+		n := root
+		for _, name := range strings.Split(path, `\`) {
+			if name == "" {
+				continue
+			}
+			child := n.children[strings.ToUpper(name)]
+			if child == nil {
+				child = &node{name: name, children: map[string]*node{}}
+				n.children[strings.ToUpper(name)] = child
+			}
+			n = child
+		}
+		n.values = append(n.values, values...)
+	}
+
+	w := &hiveWriter{}
+	rootOff := w.key(root, 0, true)
+
+	// One hive bin with a 32-byte header, padded to 4 KiB by a free cell
+	binSize := (0x20 + len(w.cells) + 8 + 0xFFF) &^ 0xFFF
+	bin := make([]byte, binSize)
+	copy(bin, "hbin")
+	binary.LittleEndian.PutUint32(bin[0x08:], uint32(binSize))
+	copy(bin[0x20:], w.cells)
+	free := 0x20 + len(w.cells)
+	binary.LittleEndian.PutUint32(bin[free:], uint32(binSize-free))
+
+	base := make([]byte, hbinStart)
+	copy(base, "regf")
+	binary.LittleEndian.PutUint32(base[0x04:], 1) // primary sequence number
+	binary.LittleEndian.PutUint32(base[0x08:], 1) // secondary sequence number
+	binary.LittleEndian.PutUint32(base[0x14:], 1) // major version
+	binary.LittleEndian.PutUint32(base[0x18:], 5) // minor version
+	binary.LittleEndian.PutUint32(base[0x20:], 1) // file format: direct memory load
+	binary.LittleEndian.PutUint32(base[0x24:], rootOff)
+	binary.LittleEndian.PutUint32(base[0x28:], uint32(binSize))
+	binary.LittleEndian.PutUint32(base[0x2C:], 1) // clustering factor
+	var checksum uint32
+	for i := 0; i < 0x1FC; i += 4 {
+		checksum ^= binary.LittleEndian.Uint32(base[i:])
+	}
+	binary.LittleEndian.PutUint32(base[0x1FC:], checksum)
+
+	return append(base, bin...)
+}
+
+// alloc appends an allocated cell and returns its offset
+func (w *hiveWriter) alloc(data []byte) uint32 {
+	off := uint32(0x20 + len(w.cells))
+	size := (4 + len(data) + 7) &^ 7
+	cell := make([]byte, size)
+	binary.LittleEndian.PutUint32(cell, uint32(-int32(size)))
+	copy(cell[4:], data)
+	w.cells = append(w.cells, cell...)
+	return off
+}
+
+// key writes a key node, its values and its children
+func (w *hiveWriter) key(n *node, parent uint32, isRoot bool) uint32 {
+	name := []byte(n.name)
+	nk := make([]byte, 0x4C+len(name))
+	off := w.alloc(nk)
+	nkStart := off - 0x20 + 4
+
+	var children []uint32
+	names := make([]string, 0, len(n.children))
+	for upper := range n.children {
+		names = append(names, upper)
+	}
+	slices.Sort(names)
+	for _, upper := range names {
+		children = append(children, w.key(n.children[upper], off, false))
+	}
+
+	var subkeyList, valueList uint32
+	if len(children) > 0 {
+		li := make([]byte, 4+4*len(children))
+		copy(li, "li")
+		binary.LittleEndian.PutUint16(li[2:], uint16(len(children)))
+		for i, child := range children {
+			binary.LittleEndian.PutUint32(li[4+4*i:], child)
+		}
+		subkeyList = w.alloc(li)
+	}
+	if len(n.values) > 0 {
+		list := make([]byte, 4*len(n.values))
+		for i, v := range n.values {
+			binary.LittleEndian.PutUint32(list[4*i:], w.value(v))
+		}
+		valueList = w.alloc(list)
+	}
+
+	// Fill in the key node now that its lists have offsets
+	nk = w.cells[nkStart : nkStart+uint32(len(nk))]
+	copy(nk, "nk")
+	flags := uint16(keyCompressedName)
+	if isRoot {
+		flags |= 0x04 | 0x08 // hive entry, cannot be deleted
+	}
+	binary.LittleEndian.PutUint16(nk[0x02:], flags)
+	binary.LittleEndian.PutUint32(nk[0x10:], parent)
+	binary.LittleEndian.PutUint32(nk[0x14:], uint32(len(children)))
+	binary.LittleEndian.PutUint32(nk[0x1C:], orNone(subkeyList, len(children)))
+	binary.LittleEndian.PutUint32(nk[0x20:], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(nk[0x24:], uint32(len(n.values)))
+	binary.LittleEndian.PutUint32(nk[0x28:], orNone(valueList, len(n.values)))
+	binary.LittleEndian.PutUint32(nk[0x2C:], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(nk[0x30:], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint16(nk[0x48:], uint16(len(name)))
+	copy(nk[0x4C:], name)
+	return off
+}
+
+// value writes a value node and its data
+func (w *hiveWriter) value(v Value) uint32 {
+	name := []byte(v.Name)
+	vk := make([]byte, 0x14+len(name))
+	copy(vk, "vk")
+	binary.LittleEndian.PutUint16(vk[0x02:], uint16(len(name)))
+	if len(v.Data) <= 4 {
+		binary.LittleEndian.PutUint32(vk[0x04:], uint32(len(v.Data))|0x80000000)
+		copy(vk[0x08:], v.Data)
+	} else {
+		binary.LittleEndian.PutUint32(vk[0x04:], uint32(len(v.Data)))
+		binary.LittleEndian.PutUint32(vk[0x08:], w.alloc(v.Data))
+	}
+	binary.LittleEndian.PutUint32(vk[0x0C:], v.Type)
+	binary.LittleEndian.PutUint16(vk[0x10:], valueCompressedName)
+	copy(vk[0x14:], name)
+	return w.alloc(vk)
+}
+
+// orNone returns off, or the "no list" marker when there are no entries
+func orNone(off uint32, n int) uint32 {
+	if n == 0 {
+		return 0xFFFFFFFF
+	}
+	return off
+}
+
+// StringValue makes a REG_SZ value
+func StringValue(name string, text string) Value {
+	return Value{Name: name, Type: TypeString, Data: encodeUTF16(text)}
+}
+
+// encodeUTF16 encodes text as NUL-terminated little-endian UTF-16
+func encodeUTF16(text string) []byte {
+	units := append(utf16.Encode([]rune(text)), 0)
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
@@ -0,0 +1,291 @@
+// Package registry reads values from offline Windows registry hive files
+// (regf), such as SOFTWARE and NTUSER.DAT copied from a disk image, and
+// writes small hives for test fixtures. Transaction logs are not replayed,
+// so a hive that was not flushed may lack the latest changes.
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Value types
+const (
+	TypeString       = 1
+	TypeExpandString = 2
+	TypeBinary       = 3
+	TypeDWord        = 4
+)
+
+// hbinStart is where the first hive bin follows the base block; cell offsets are relative to it
+const hbinStart = 0x1000
+
+// maxDepth bounds index lists nested in index roots
+const maxDepth = 8
+
+// Flags of key and value names stored in Latin-1 instead of UTF-16
+const (
+	keyCompressedName   = 0x20
+	valueCompressedName = 0x01
+)
+
+// ErrNotFound is returned for a key path that does not exist in the hive
+var ErrNotFound = errors.New("registry key not found")
+
+// Hive is a parsed hive file
+type Hive struct {
+	data []byte
+	root uint32
+}
+
+// Value is a named value of a key
+type Value struct {
+	Name string
+	Type uint32
+	Data []byte
+}
+
+// String returns the text of a REG_SZ or REG_EXPAND_SZ value, without
+// expanding environment variables, or "" for other types
+func (v Value) String() string {
+	if v.Type != TypeString && v.Type != TypeExpandString {
+		return ""
+	}
+	return decodeUTF16(v.Data)
+}
+
+// Open checks the base block of a hive file
+func Open(data []byte) (*Hive, error) {
+	if len(data) < hbinStart+0x20 || string(data[:4]) != "regf" {
+		return nil, errors.New("not a registry hive")
+	}
+	h := &Hive{data: data, root: binary.LittleEndian.Uint32(data[0x24:])}
+	if _, err := h.key(h.root); err != nil {
+		return nil, fmt.Errorf("invalid root key: %v", err)
+	}
+	return h, nil
+}
+
+// Values returns the values of the key at path, given relative to the hive
+// root with backslashes, such as `Microsoft\Windows\CurrentVersion\Run`.
+// Key names are compared case-insensitively.
+func (h *Hive) Values(path string) ([]Value, error) {
+	off := h.root
+	for _, name := range strings.Split(path, `\`) {
+		if name == "" {
+			continue
+		}
+		child, err := h.subkey(off, name)
+		if err != nil {
+			return nil, err
+		}
+		off = child
+	}
+
+	k, err := h.key(off)
+	if err != nil {
+		return nil, err
+	}
+	if k.values == 0 {
+		return nil, nil
+	}
+	list, err := h.cell(k.valueList)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(k.values)*4 > uint64(len(list)) {
+		return nil, errors.New("value list out of range")
+	}
+
+	values := make([]Value, 0, k.values)
+	for i := uint32(0); i < k.values; i++ {
+		v, err := h.value(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// keyNode is the part of a key node cell ("nk") this package uses
+type keyNode struct {
+	name       string
+	subkeys    uint32
+	subkeyList uint32
+	values     uint32
+	valueList  uint32
+}
+
+// cell returns the data of the cell at a hive bin offset
+func (h *Hive) cell(off uint32) ([]byte, error) {
+	start := uint64(hbinStart) + uint64(off)
+	if start+4 > uint64(len(h.data)) {
+		return nil, fmt.Errorf("cell offset 0x%x out of range", off)
+	}
+	size := int32(binary.LittleEndian.Uint32(h.data[start:]))
+	if size < 0 {
+		size = -size
+	}
+	if size < 4 || start+uint64(size) > uint64(len(h.data)) {
+		return nil, fmt.Errorf("invalid cell size at 0x%x", off)
+	}
+	return h.data[start+4 : start+uint64(size)], nil
+}
+
+// key reads a key node
+func (h *Hive) key(off uint32) (keyNode, error) {
+	b, err := h.cell(off)
+	if err != nil {
+		return keyNode{}, err
+	}
+	if len(b) < 0x4C || string(b[:2]) != "nk" {
+		return keyNode{}, fmt.Errorf("no key node at 0x%x", off)
+	}
+	nameLen := uint32(binary.LittleEndian.Uint16(b[0x48:]))
+	if 0x4C+nameLen > uint32(len(b)) {
+		return keyNode{}, fmt.Errorf("key name at 0x%x out of range", off)
+	}
+	return keyNode{
+		name:       decodeName(b[0x4C:0x4C+nameLen], binary.LittleEndian.Uint16(b[0x02:])&keyCompressedName != 0),
+		subkeys:    binary.LittleEndian.Uint32(b[0x14:]),
+		subkeyList: binary.LittleEndian.Uint32(b[0x1C:]),
+		values:     binary.LittleEndian.Uint32(b[0x24:]),
+		valueList:  binary.LittleEndian.Uint32(b[0x28:]),
+	}, nil
+}
+
+// subkey finds the child of a key by name
+func (h *Hive) subkey(off uint32, name string) (uint32, error) {
+	k, err := h.key(off)
+	if err != nil {
+		return 0, err
+	}
+	if k.subkeys == 0 {
+		return 0, ErrNotFound
+	}
+	children, err := h.subkeyOffsets(k.subkeyList, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, child := range children {
+		ck, err := h.key(child)
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(ck.name, name) {
+			return child, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// subkeyOffsets reads a subkey list: "li", "lf" and "lh" lists hold key
+// nodes, "ri" index roots hold further lists
+func (h *Hive) subkeyOffsets(off uint32, depth int) ([]uint32, error) {
+	if depth > maxDepth {
+		return nil, errors.New("subkey lists nest too deeply")
+	}
+	b, err := h.cell(off)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, fmt.Errorf("no subkey list at 0x%x", off)
+	}
+	count := uint32(binary.LittleEndian.Uint16(b[2:]))
+	stride := uint32(4)
+	switch string(b[:2]) {
+	case "lf", "lh":
+		stride = 8
+	case "li", "ri":
+	default:
+		return nil, fmt.Errorf("unknown subkey list %q at 0x%x", b[:2], off)
+	}
+	if 4+count*stride > uint32(len(b)) {
+		return nil, fmt.Errorf("subkey list at 0x%x out of range", off)
+	}
+
+	var offsets []uint32
+	for i := uint32(0); i < count; i++ {
+		entry := binary.LittleEndian.Uint32(b[4+i*stride:])
+		if string(b[:2]) == "ri" {
+			nested, err := h.subkeyOffsets(entry, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			offsets = append(offsets, nested...)
+			continue
+		}
+		offsets = append(offsets, entry)
+	}
+	return offsets, nil
+}
+
+// value reads a value node ("vk") and its data
+func (h *Hive) value(off uint32) (Value, error) {
+	b, err := h.cell(off)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(b) < 0x14 || string(b[:2]) != "vk" {
+		return Value{}, fmt.Errorf("no value node at 0x%x", off)
+	}
+	nameLen := uint32(binary.LittleEndian.Uint16(b[0x02:]))
+	if 0x14+nameLen > uint32(len(b)) {
+		return Value{}, fmt.Errorf("value name at 0x%x out of range", off)
+	}
+	v := Value{
+		Name: decodeName(b[0x14:0x14+nameLen], binary.LittleEndian.Uint16(b[0x10:])&valueCompressedName != 0),
+		Type: binary.LittleEndian.Uint32(b[0x0C:]),
+	}
+
+	size := binary.LittleEndian.Uint32(b[0x04:])
+	if size&0x80000000 != 0 {
+		// Up to four bytes are stored in the offset field itself
+		size &^= 0x80000000
+		v.Data = bytes.Clone(b[0x08 : 0x08+min(size, 4)])
+		return v, nil
+	}
+	data, err := h.cell(binary.LittleEndian.Uint32(b[0x08:]))
+	if err != nil {
+		return Value{}, err
+	}
+	if len(data) >= 2 && string(data[:2]) == "db" {
+		return Value{}, fmt.Errorf("value %q is stored in big data segments, which are not supported", v.Name)
+	}
+	if size > uint32(len(data)) {
+		return Value{}, fmt.Errorf("value %q data out of range", v.Name)
+	}
+	v.Data = bytes.Clone(data[:size])
+	return v, nil
+}
+
+// decodeName decodes a key or value name stored as Latin-1 or UTF-16
+func decodeName(b []byte, compressed bool) string {
+	if !compressed {
+		return decodeUTF16(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// decodeUTF16 decodes little-endian UTF-16 up to the first NUL
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u := binary.LittleEndian.Uint16(b[i:])
+		if u == 0 {
+			break
+		}
+		units = append(units, u)
+	}
+	return string(utf16.Decode(units))
+}
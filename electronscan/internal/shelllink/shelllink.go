@@ -0,0 +1,245 @@
+// Package shelllink reads the target of Windows shortcut (.lnk) files as
+// described in [MS-SHLLINK], and writes simple shortcuts for test fixtures.
+// Targets only stored as a shell item ID list are not resolved.
+package shelllink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// headerSize is the size of the ShellLinkHeader
+const headerSize = 0x4C
+
+// linkCLSID is the class identifier every shortcut header carries
+var linkCLSID = []byte{0x01, 0x14, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}
+
+// LinkFlags
+const (
+	hasLinkTargetIDList = 0x01
+	hasLinkInfo         = 0x02
+	hasName             = 0x04
+	hasRelativePath     = 0x08
+	hasWorkingDir       = 0x10
+	hasArguments        = 0x20
+	hasIconLocation     = 0x40
+	isUnicode           = 0x80
+)
+
+// volumeIDAndLocalBasePath is the LinkInfo flag for a local target path
+const volumeIDAndLocalBasePath = 0x01
+
+// Link is what a shortcut launches
+type Link struct {
+	// Target is the absolute path of the target, or the path relative to
+	// the shortcut when only that was stored
+	Target     string
+	Arguments  string
+	WorkingDir string
+}
+
+// Parse reads a shortcut file
+func Parse(data []byte) (Link, error) {
+	if len(data) < headerSize || binary.LittleEndian.Uint32(data) != headerSize || !bytes.Equal(data[4:20], linkCLSID) {
+		return Link{}, errors.New("not a shortcut file")
+	}
+	flags := binary.LittleEndian.Uint32(data[0x14:])
+	off := headerSize
+
+	if flags&hasLinkTargetIDList != 0 {
+		if off+2 > len(data) {
+			return Link{}, errors.New("truncated shortcut")
+		}
+		off += 2 + int(binary.LittleEndian.Uint16(data[off:]))
+	}
+
+	var link Link
+	if flags&hasLinkInfo != 0 {
+		if off+4 > len(data) {
+			return Link{}, errors.New("truncated shortcut")
+		}
+		size := int(binary.LittleEndian.Uint32(data[off:]))
+		if size < 0x1C || off+size > len(data) {
+			return Link{}, errors.New("invalid link info")
+		}
+		link.Target = localBasePath(data[off : off+size])
+		off += size
+	}
+
+	// StringData follows in a fixed order, each present only with its flag
+	var relativePath string
+	for _, field := range []struct {
+		flag uint32
+		dest *string
+	}{
+		{hasName, nil},
+		{hasRelativePath, &relativePath},
+		{hasWorkingDir, &link.WorkingDir},
+		{hasArguments, &link.Arguments},
+		{hasIconLocation, nil},
+	} {
+		if flags&field.flag == 0 {
+			continue
+		}
+		s, n, err := stringData(data[off:], flags&isUnicode != 0)
+		if err != nil {
+			return Link{}, err
+		}
+		if field.dest != nil {
+			*field.dest = s
+		}
+		off += n
+	}
+
+	if link.Target == "" {
+		link.Target = relativePath
+	}
+	if link.Target == "" {
+		return Link{}, errors.New("shortcut has no target path")
+	}
+	return link, nil
+}
+
+// localBasePath reads the local path from a LinkInfo structure, preferring the Unicode copy
+func localBasePath(info []byte) string {
+	headerLen := binary.LittleEndian.Uint32(info[4:])
+	if binary.LittleEndian.Uint32(info[8:])&volumeIDAndLocalBasePath == 0 {
+		return ""
+	}
+	if headerLen >= 0x24 && len(info) >= 0x24 {
+		base := utf16String(info, binary.LittleEndian.Uint32(info[0x1C:]))
+		suffix := utf16String(info, binary.LittleEndian.Uint32(info[0x20:]))
+		if base != "" {
+			return base + suffix
+		}
+	}
+	return cString(info, binary.LittleEndian.Uint32(info[0x10:])) + cString(info, binary.LittleEndian.Uint32(info[0x18:]))
+}
+
+// cString reads a NUL-terminated string in the system code page; ASCII is all that is decoded reliably
+func cString(b []byte, off uint32) string {
+	if off == 0 || off >= uint32(len(b)) {
+		return ""
+	}
+	end := bytes.IndexByte(b[off:], 0)
+	if end < 0 {
+		return ""
+	}
+	runes := make([]rune, end)
+	for i, c := range b[off : off+uint32(end)] {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// utf16String reads a NUL-terminated little-endian UTF-16 string
+func utf16String(b []byte, off uint32) string {
+	if off == 0 || off >= uint32(len(b)) {
+		return ""
+	}
+	var units []uint16
+	for i := off; i+1 < uint32(len(b)); i += 2 {
+		u := binary.LittleEndian.Uint16(b[i:])
+		if u == 0 {
+			return string(utf16.Decode(units))
+		}
+		units = append(units, u)
+	}
+	return ""
+}
+
+// stringData reads a counted string and returns it with the bytes it took
+func stringData(b []byte, unicode bool) (string, int, error) {
+	if len(b) < 2 {
+		return "", 0, errors.New("truncated shortcut")
+	}
+	count := int(binary.LittleEndian.Uint16(b))
+	if !unicode {
+		if 2+count > len(b) {
+			return "", 0, errors.New("truncated shortcut")
+		}
+		runes := make([]rune, count)
+		for i, c := range b[2 : 2+count] {
+			runes[i] = rune(c)
+		}
+		return string(runes), 2 + count, nil
+	}
+	if 2+2*count > len(b) {
+		return "", 0, errors.New("truncated shortcut")
+	}
+	units := make([]uint16, count)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2+2*i:])
+	}
+	return string(utf16.Decode(units)), 2 + 2*count, nil
+}
+
+// Marshal writes a shortcut to an absolute local target
+func Marshal(link Link) []byte {
+	flags := uint32(hasLinkInfo | isUnicode)
+	if link.WorkingDir != "" {
+		flags |= hasWorkingDir
+	}
+	if link.Arguments != "" {
+		flags |= hasArguments
+	}
+
+	header := make([]byte, headerSize)
+	binary.LittleEndian.PutUint32(header, headerSize)
+	copy(header[4:], linkCLSID)
+	binary.LittleEndian.PutUint32(header[0x14:], flags)
+	binary.LittleEndian.PutUint32(header[0x3C:], 1) // SW_SHOWNORMAL
+
+	// LinkInfo: header, an empty fixed-disk VolumeID, then the ANSI and Unicode paths
+	volumeID := make([]byte, 0x14)
+	binary.LittleEndian.PutUint32(volumeID, uint32(len(volumeID)))
+	binary.LittleEndian.PutUint32(volumeID[4:], 3) // DRIVE_FIXED
+	binary.LittleEndian.PutUint32(volumeID[0x0C:], 0x10)
+	ansi := append([]byte(link.Target), 0)
+	unicode := encodeUTF16(link.Target)
+	const infoHeader = 0x24
+	info := make([]byte, infoHeader)
+	info = append(info, volumeID...)
+	basePath := len(info)
+	info = append(info, ansi...)
+	suffix := len(info)
+	info = append(info, 0)
+	basePathUnicode := len(info)
+	info = append(info, unicode...)
+	suffixUnicode := len(info)
+	info = append(info, 0, 0)
+	binary.LittleEndian.PutUint32(info, uint32(len(info)))
+	binary.LittleEndian.PutUint32(info[4:], infoHeader)
+	binary.LittleEndian.PutUint32(info[8:], volumeIDAndLocalBasePath)
+	binary.LittleEndian.PutUint32(info[0x0C:], infoHeader)
+	binary.LittleEndian.PutUint32(info[0x10:], uint32(basePath))
+	binary.LittleEndian.PutUint32(info[0x18:], uint32(suffix))
+	binary.LittleEndian.PutUint32(info[0x1C:], uint32(basePathUnicode))
+	binary.LittleEndian.PutUint32(info[0x20:], uint32(suffixUnicode))
+
+	out := append(header, info...)
+	for _, s := range []string{link.WorkingDir, link.Arguments} {
+		if s == "" {
+			continue
+		}
+		units := utf16.Encode([]rune(s))
+		out = binary.LittleEndian.AppendUint16(out, uint16(len(units)))
+		for _, u := range units {
+			out = binary.LittleEndian.AppendUint16(out, u)
+		}
+	}
+	// Terminal block of the (empty) ExtraData section
+	return binary.LittleEndian.AppendUint32(out, 0)
+}
+
+// encodeUTF16 encodes s as NUL-terminated little-endian UTF-16
+func encodeUTF16(s string) []byte {
+	units := append(utf16.Encode([]rune(s)), 0)
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
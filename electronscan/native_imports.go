@@ -0,0 +1,379 @@
+package electronscan
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+)
+
+// LibraryDependency is a shared library imported by a native module
+type LibraryDependency struct {
+	Name     string `json:"name"`
+	Resolved string `json:"resolved,omitempty"`
+	Missing  bool   `json:"missing,omitempty"`
+	Writable bool   `json:"writable,omitempty"`
+}
+
+// IsHijackable reports whether the dependency could be planted or replaced by the current user
+func (d LibraryDependency) IsHijackable() bool {
+	return d.Missing || d.Writable
+}
+
+// Default library search directories used by the ELF dynamic loader
+var elfDefaultLibDirs = []string{
+	"/lib",
+	"/usr/lib",
+	"/lib64",
+	"/usr/lib64",
+	"/lib/x86_64-linux-gnu",
+	"/usr/lib/x86_64-linux-gnu",
+	"/lib/aarch64-linux-gnu",
+	"/usr/lib/aarch64-linux-gnu",
+}
+
+// KnownDLLs are always mapped from System32 regardless of search order
+var windowsKnownDlls = map[string]bool{
+	"advapi32.dll": true,
+	"combase.dll":  true,
+	"comdlg32.dll": true,
+	"gdi32.dll":    true,
+	"imm32.dll":    true,
+	"kernel32.dll": true,
+	"msvcrt.dll":   true,
+	"ntdll.dll":    true,
+	"ole32.dll":    true,
+	"oleaut32.dll": true,
+	"rpcrt4.dll":   true,
+	"sechost.dll":  true,
+	"shell32.dll":  true,
+	"shlwapi.dll":  true,
+	"user32.dll":   true,
+	"ws2_32.dll":   true,
+}
+
+// isUserWritable reports whether the current user can modify or replace the file at path
+func (s *Scanner) isUserWritable(path string) bool {
+	if _, err := s.stat(path); err == nil && s.canWrite(path) {
+		return true
+	}
+	return s.canWrite(filepath.Dir(path))
+}
+
+// resolveCandidates picks the first existing candidate path for a library.
+// Candidates searched before the match that sit in writable directories
+// would take precedence if planted, so they count as writable too.
+func (s *Scanner) resolveCandidates(name string, candidates []string) LibraryDependency {
+	dep := LibraryDependency{Name: name}
+
+	for _, candidate := range candidates {
+		if _, err := s.stat(candidate); err == nil {
+			dep.Resolved = candidate
+			if s.isUserWritable(candidate) {
+				dep.Writable = true
+			}
+			return dep
+		}
+
+		dir := filepath.Dir(candidate)
+		if _, err := s.stat(dir); err == nil && s.canWrite(dir) {
+			dep.Resolved = candidate
+			dep.Writable = true
+			return dep
+		}
+	}
+
+	dep.Missing = true
+	if len(candidates) > 0 {
+		dep.Resolved = candidates[0]
+	}
+	return dep
+}
+
+// nativeModuleDependencies parses the dynamic imports of a native module and
+// resolves them the way the platform loader would
+func (s *Scanner) nativeModuleDependencies(path string, executableDir string) []LibraryDependency {
+	ra, _, err := s.openReaderAt(path)
+	if err != nil {
+		return nil
+	}
+	defer ra.Close()
+
+	if f, err := macho.NewFatFile(ra); err == nil {
+		// Each slice can link different libraries; whichever the target
+		// machine loads, a hijackable dependency in any of them counts
+		var deps []LibraryDependency
+		index := make(map[string]int)
+		for _, arch := range f.Arches {
+			for _, dep := range s.machoDependencies(arch.File, path, executableDir) {
+				i, seen := index[dep.Name]
+				switch {
+				case !seen:
+					index[dep.Name] = len(deps)
+					deps = append(deps, dep)
+				case dep.IsHijackable() && !deps[i].IsHijackable():
+					deps[i] = dep
+				}
+			}
+		}
+		return deps
+	}
+
+	if f, err := macho.NewFile(ra); err == nil {
+		return s.machoDependencies(f, path, executableDir)
+	}
+
+	if f, err := pe.NewFile(ra); err == nil {
+		return s.peDependencies(f, path, executableDir)
+	}
+
+	if f, err := elf.NewFile(ra); err == nil {
+		return s.elfDependencies(f, path)
+	}
+
+	return nil
+}
+
+// machoDependencies resolves LC_LOAD_DYLIB entries, expanding @rpath,
+// @loader_path and @executable_path
+func (s *Scanner) machoDependencies(f *macho.File, path string, executableDir string) []LibraryDependency {
+	loaderDir := filepath.Dir(path)
+	expand := func(p string) string {
+		switch {
+		case strings.HasPrefix(p, "@loader_path"):
+			return filepath.Join(loaderDir, strings.TrimPrefix(p, "@loader_path"))
+		case strings.HasPrefix(p, "@executable_path"):
+			return filepath.Join(executableDir, strings.TrimPrefix(p, "@executable_path"))
+		default:
+			return p
+		}
+	}
+
+	// The addon's own rpaths are searched before the host executable's,
+	// which for Electron points at Contents/Frameworks
+	var rpaths []string
+	var libraries []string
+	for _, load := range f.Loads {
+		switch l := load.(type) {
+		case *macho.Rpath:
+			rpaths = append(rpaths, expand(l.Path))
+		case *macho.Dylib:
+			libraries = append(libraries, l.Name)
+		}
+	}
+	rpaths = append(rpaths, filepath.Join(executableDir, "..", "Frameworks"))
+
+	var deps []LibraryDependency
+	for _, lib := range libraries {
+		// System libraries live in the dyld shared cache, not on disk
+		if strings.HasPrefix(lib, "/usr/lib/") || strings.HasPrefix(lib, "/System/Library/") {
+			deps = append(deps, LibraryDependency{Name: lib, Resolved: lib})
+			continue
+		}
+
+		var candidates []string
+		if strings.HasPrefix(lib, "@rpath/") {
+			for _, rpath := range rpaths {
+				candidates = append(candidates, filepath.Join(rpath, strings.TrimPrefix(lib, "@rpath/")))
+			}
+		} else {
+			candidates = []string{expand(lib)}
+		}
+
+		deps = append(deps, s.resolveCandidates(lib, candidates))
+	}
+
+	return deps
+}
+
+// peDependencies resolves the import table using the standard DLL search order
+func (s *Scanner) peDependencies(f *pe.File, path string, executableDir string) []LibraryDependency {
+	// debug/pe does not expose the import directory directly, but imported
+	// symbols are reported as "symbol:library"
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var libraries []string
+	for _, sym := range symbols {
+		idx := strings.LastIndex(sym, ":")
+		if idx < 0 {
+			continue
+		}
+		lib := sym[idx+1:]
+		if !seen[strings.ToLower(lib)] {
+			seen[strings.ToLower(lib)] = true
+			libraries = append(libraries, lib)
+		}
+	}
+
+	systemRoot, pathDirs := s.windowsSearchPath()
+	searchDirs := []string{filepath.Dir(path), executableDir, filepath.Join(systemRoot, "System32"), systemRoot}
+	searchDirs = append(searchDirs, pathDirs...)
+
+	var deps []LibraryDependency
+	for _, lib := range libraries {
+		lower := strings.ToLower(lib)
+
+		// node.exe imports are redirected to the host process by Electron's
+		// delay-load hook, and API sets are resolved by the loader itself
+		if lower == "node.exe" || strings.HasPrefix(lower, "api-ms-win-") || strings.HasPrefix(lower, "ext-ms-") {
+			deps = append(deps, LibraryDependency{Name: lib})
+			continue
+		}
+
+		if windowsKnownDlls[lower] {
+			deps = append(deps, s.resolveCandidates(lib, []string{filepath.Join(systemRoot, "System32", lib)}))
+			continue
+		}
+
+		var candidates []string
+		for _, dir := range searchDirs {
+			if dir != "" {
+				candidates = append(candidates, filepath.Join(dir, lib))
+			}
+		}
+
+		deps = append(deps, s.resolveCandidates(lib, candidates))
+	}
+
+	return deps
+}
+
+// windowsSearchPath returns the Windows directory and the system PATH of the
+// target. An image's PATH is read from its SYSTEM hive, falling back to the
+// Windows default, rather than taken from the machine running the scan.
+func (s *Scanner) windowsSearchPath() (string, []string) {
+	if s.isHost() {
+		systemRoot := os.Getenv("SystemRoot")
+		if systemRoot == "" {
+			systemRoot = `C:\Windows`
+		}
+		return systemRoot, filepath.SplitList(os.Getenv("PATH"))
+	}
+
+	root, drive := s.windowsSystemDrive()
+	systemRoot := filepath.Join(root, "Windows")
+	system32 := filepath.Join(systemRoot, "System32")
+	pathValue := s.imageSystemPath(filepath.Join(system32, "config", "SYSTEM"))
+	if pathValue == "" {
+		return systemRoot, []string{system32, systemRoot, filepath.Join(system32, "Wbem"), filepath.Join(system32, "WindowsPowerShell", "v1.0")}
+	}
+
+	var dirs []string
+	for _, dir := range strings.Split(expandWindowsEnv(pathValue, windowsEnv(drive, "")), ";") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, imagePath(root, dir))
+		}
+	}
+	return systemRoot, dirs
+}
+
+// imageSystemPath reads the system-wide Path variable from a SYSTEM hive, or returns ""
+func (s *Scanner) imageSystemPath(hivePath string) string {
+	hive := s.openHive(hivePath)
+	if hive == nil {
+		return ""
+	}
+	return hiveString(hive, currentControlSet(hive)+`\Control\Session Manager\Environment`, "Path")
+}
+
+// openHive reads and parses a registry hive file, or returns nil
+func (s *Scanner) openHive(hivePath string) *registry.Hive {
+	content, err := s.readFile(hivePath)
+	if err != nil {
+		return nil
+	}
+	hive, err := registry.Open(content)
+	if err != nil {
+		s.logger.Debug("Error parsing registry hive", "path", hivePath, "error", err)
+		return nil
+	}
+	return hive
+}
+
+// currentControlSet returns the control set of a SYSTEM hive that Select\Current names
+func currentControlSet(hive *registry.Hive) string {
+	controlSet := "ControlSet001"
+	if values, err := hive.Values("Select"); err == nil {
+		for _, v := range values {
+			if strings.EqualFold(v.Name, "Current") && v.Type == registry.TypeDWord && len(v.Data) == 4 {
+				controlSet = fmt.Sprintf("ControlSet%03d", binary.LittleEndian.Uint32(v.Data))
+			}
+		}
+	}
+	return controlSet
+}
+
+// hiveString returns a value of a hive key as a string, or "" if it is missing
+func hiveString(hive *registry.Hive, key string, name string) string {
+	values, err := hive.Values(key)
+	if err != nil {
+		return ""
+	}
+	for _, v := range values {
+		if strings.EqualFold(v.Name, name) {
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// imagePath maps a Windows path such as C:\Windows\System32 into the scanned
+// filesystem, whose root stands for the system drive
+func imagePath(root string, windowsPath string) string {
+	windowsPath = strings.ReplaceAll(windowsPath, `\`, "/")
+	if len(windowsPath) >= 2 && windowsPath[1] == ':' {
+		windowsPath = windowsPath[2:]
+	}
+	return filepath.Join(root, filepath.FromSlash(windowsPath))
+}
+
+// elfDependencies resolves DT_NEEDED entries using DT_RPATH/DT_RUNPATH and
+// the default loader directories
+func (s *Scanner) elfDependencies(f *elf.File, path string) []LibraryDependency {
+	libraries, err := f.DynString(elf.DT_NEEDED)
+	if err != nil {
+		return nil
+	}
+
+	origin := filepath.Dir(path)
+	var searchDirs []string
+	for _, tag := range []elf.DynTag{elf.DT_RPATH, elf.DT_RUNPATH} {
+		values, _ := f.DynString(tag)
+		for _, value := range values {
+			for _, dir := range strings.Split(value, ":") {
+				dir = strings.ReplaceAll(dir, "${ORIGIN}", origin)
+				dir = strings.ReplaceAll(dir, "$ORIGIN", origin)
+				if dir != "" {
+					searchDirs = append(searchDirs, dir)
+				}
+			}
+		}
+	}
+	searchDirs = append(searchDirs, elfDefaultLibDirs...)
+
+	var deps []LibraryDependency
+	for _, lib := range libraries {
+		if strings.Contains(lib, "/") {
+			deps = append(deps, s.resolveCandidates(lib, []string{lib}))
+			continue
+		}
+
+		var candidates []string
+		for _, dir := range searchDirs {
+			candidates = append(candidates, filepath.Join(dir, lib))
+		}
+		deps = append(deps, s.resolveCandidates(lib, candidates))
+	}
+
+	return deps
+}
@@ -0,0 +1,40 @@
+package electronscan
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/registry"
+)
+
+func TestWindowsSearchPathFromImage(t *testing.T) {
+	current := make([]byte, 4)
+	binary.LittleEndian.PutUint32(current, 2)
+	hive := registry.Marshal(map[string][]registry.Value{
+		`Select`: {{Name: "Current", Type: registry.TypeDWord, Data: current}},
+		`ControlSet001\Control\Session Manager\Environment`: {registry.StringValue("Path", `C:\Stale`)},
+		`ControlSet002\Control\Session Manager\Environment`: {
+			{Name: "Path", Type: registry.TypeExpandString, Data: registry.StringValue("", `%SystemRoot%\system32;C:\Tools\bin;;`).Data},
+		},
+	})
+
+	s := New(Options{GOOS: "windows", FS: fstest.MapFS{"Windows/System32/config/SYSTEM": {Data: hive}}})
+	systemRoot, dirs := s.windowsSearchPath()
+	sep := string(filepath.Separator)
+	if systemRoot != filepath.Join(sep, "Windows") {
+		t.Errorf("system root = %q", systemRoot)
+	}
+	want := []string{filepath.Join(sep, "Windows", "system32"), filepath.Join(sep, "Tools", "bin")}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("PATH = %q, want %q", dirs, want)
+	}
+
+	// Without a SYSTEM hive the Windows default applies, never the scanning host's PATH
+	s = New(Options{GOOS: "windows", FS: fstest.MapFS{}})
+	if _, dirs := s.windowsSearchPath(); len(dirs) == 0 || dirs[0] != filepath.Join(sep, "Windows", "System32") {
+		t.Errorf("default PATH = %q", dirs)
+	}
+}
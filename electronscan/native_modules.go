@@ -0,0 +1,203 @@
+package electronscan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NativeModule describes a .node addon shipped with an application
+type NativeModule struct {
+	Path           string              `json:"path"`
+	Size           int64               `json:"size"`
+	SHA256         string              `json:"sha256,omitempty"`
+	Format         string              `json:"format,omitempty"`
+	Architectures  []string            `json:"architectures,omitempty"`
+	Package        string              `json:"package,omitempty"`
+	PackageVersion string              `json:"package_version,omitempty"`
+	ABI            string              `json:"abi,omitempty"`
+	ABIVersion     string              `json:"abi_version,omitempty"`
+	Unpacked       bool                `json:"asar_unpacked"`
+	Writable       bool                `json:"writable"`
+	Signature      string              `json:"code_signature,omitempty"`
+	Dependencies   []LibraryDependency `json:"dependencies,omitempty"`
+}
+
+// nodeModuleVersionRegex matches the NODE_MODULE_VERSION suffixed registration
+// symbol exported by addons built against the V8 API (NAN)
+var nodeModuleVersionRegex = regexp.MustCompile(`node_register_module_v([0-9]+)`)
+
+// inspectNativeModule builds a NativeModule record for a .node file found under searchRoot.
+// archive may be nil if the application has no readable app.asar.
+func (s *Scanner) inspectNativeModule(path string, searchRoot string, resourcesDir string, executableDir string, archive *AsarArchive) NativeModule {
+	module := NativeModule{
+		Path: path,
+	}
+
+	content, err := s.readFile(path)
+	if err != nil {
+		s.logger.Debug("Error reading .node file", "path", path, "error", err)
+		return module
+	}
+
+	module.Size = int64(len(content))
+	sum := sha256.Sum256(content)
+	module.SHA256 = hex.EncodeToString(sum[:])
+	module.Format, module.Architectures = s.binaryArchitectures(path)
+	module.Writable = s.isUserWritable(path)
+	module.Signature = s.CodeSignatureStatus(path)
+	module.Dependencies = s.nativeModuleDependencies(path, executableDir)
+
+	// N-API addons register through napi_*, NAN addons export a symbol
+	// carrying the NODE_MODULE_VERSION they were compiled against
+	if bytes.Contains(content, []byte("napi_register_module_v1")) ||
+		bytes.Contains(content, []byte("napi_module_register")) {
+		module.ABI = "N-API"
+	} else if matches := nodeModuleVersionRegex.FindSubmatch(content); len(matches) > 1 {
+		module.ABI = "NAN"
+		module.ABIVersion = string(matches[1])
+	} else if bytes.Contains(content, []byte("node_module_register")) {
+		module.ABI = "NAN"
+	}
+
+	// Find the npm package the addon belongs to
+	if packageJsonPath := s.findPackageJson(path, searchRoot); packageJsonPath != "" {
+		packageContent, err := s.readFile(packageJsonPath)
+		if err == nil {
+			var pkg struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+				Binary  struct {
+					NapiVersions []int `json:"napi_versions"`
+				} `json:"binary"`
+			}
+			if err := json.Unmarshal(packageContent, &pkg); err == nil {
+				module.Package = pkg.Name
+				module.PackageVersion = pkg.Version
+				if module.ABI == "N-API" && len(pkg.Binary.NapiVersions) > 0 {
+					highest := pkg.Binary.NapiVersions[0]
+					for _, v := range pkg.Binary.NapiVersions {
+						if v > highest {
+							highest = v
+						}
+					}
+					module.ABIVersion = strconv.Itoa(highest)
+				}
+			} else {
+				s.logger.Debug("Error parsing package.json", "path", packageJsonPath, "error", err)
+			}
+		}
+	}
+
+	// Check whether the asar header lists the file as unpacked
+	if archive != nil {
+		unpackedDir := filepath.Join(resourcesDir, "app.asar.unpacked")
+		if rel, err := filepath.Rel(unpackedDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			if entry := archive.Lookup(filepath.ToSlash(rel)); entry != nil && entry.Unpacked {
+				module.Unpacked = true
+			}
+		}
+	}
+
+	s.logger.Debug("Native module", "path", path, "package", module.Package, "package_version", module.PackageVersion,
+		"abi", module.ABI, "arch", strings.Join(module.Architectures, ","))
+	for _, dep := range module.Dependencies {
+		if dep.IsHijackable() {
+			s.logger.Debug("Hijackable dependency", "name", dep.Name, "resolved", dep.Resolved, "missing", dep.Missing, "writable", dep.Writable)
+		}
+	}
+
+	return module
+}
+
+// findPackageJson returns the nearest package.json above a file, without
+// leaving the node_modules package the file lives in or the search root
+func (s *Scanner) findPackageJson(path string, searchRoot string) string {
+	dir := filepath.Dir(path)
+	for {
+		candidate := filepath.Join(dir, "package.json")
+		if _, err := s.stat(candidate); err == nil {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || dir == searchRoot || filepath.Base(parent) == "node_modules" {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// binaryArchitectures returns the executable format and CPU architectures of a binary
+func (s *Scanner) binaryArchitectures(path string) (string, []string) {
+	ra, _, err := s.openReaderAt(path)
+	if err != nil {
+		return "", nil
+	}
+	defer ra.Close()
+
+	if fat, err := macho.NewFatFile(ra); err == nil {
+		var archs []string
+		for _, arch := range fat.Arches {
+			archs = append(archs, machoCpuName(arch.Cpu))
+		}
+		return "Mach-O", archs
+	}
+
+	if f, err := macho.NewFile(ra); err == nil {
+		return "Mach-O", []string{machoCpuName(f.Cpu)}
+	}
+
+	if f, err := pe.NewFile(ra); err == nil {
+		switch f.Machine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return "PE", []string{"x86_64"}
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return "PE", []string{"x86"}
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return "PE", []string{"arm64"}
+		default:
+			return "PE", []string{fmt.Sprintf("0x%x", f.Machine)}
+		}
+	}
+
+	if f, err := elf.NewFile(ra); err == nil {
+		switch f.Machine {
+		case elf.EM_X86_64:
+			return "ELF", []string{"x86_64"}
+		case elf.EM_386:
+			return "ELF", []string{"x86"}
+		case elf.EM_AARCH64:
+			return "ELF", []string{"arm64"}
+		default:
+			return "ELF", []string{strings.TrimPrefix(f.Machine.String(), "EM_")}
+		}
+	}
+
+	return "", nil
+}
+
+// machoCpuName converts a Mach-O CPU type to a conventional architecture name
+func machoCpuName(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "x86_64"
+	case macho.Cpu386:
+		return "x86"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuArm:
+		return "arm"
+	default:
+		return strings.TrimPrefix(cpu.String(), "Cpu")
+	}
+}
@@ -0,0 +1,188 @@
+package electronscan_test
+
+import (
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestNativeModuleProvenance(t *testing.T) {
+	fsys := fstest.MapFS{}
+	exe, err := fixture.App{GOOS: "windows", NativeModules: []string{"keytar"}}.Build(fsys, "Program Files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpacked := path.Join(path.Dir(exe), "resources", "app.asar.unpacked")
+	add := func(name string, content string) {
+		fsys[path.Join(unpacked, name)] = &fstest.MapFile{Data: []byte(content), Mode: 0o644}
+	}
+	// A NAN addon built against Electron 28's NODE_MODULE_VERSION
+	add("node_modules/nan-addon/package.json", `{"name": "nan-addon", "version": "4.1.0"}`)
+	add("node_modules/nan-addon/build/Release/addon.node", "node_register_module_v119")
+	// N-API versions come from the package's prebuild settings
+	add("node_modules/napi-addon/package.json", `{"name": "napi-addon", "version": "2.0.0", "binary": {"napi_versions": [3, 8, 6]}}`)
+	add("node_modules/napi-addon/prebuilds/win32-x64/addon.NODE", "napi_register_module_v1")
+	// A nested package without package.json must not be attributed to its parent
+	add("node_modules/outer/package.json", `{"name": "outer", "version": "1.0.0"}`)
+	add("node_modules/outer/node_modules/inner/inner.node", "napi_module_register")
+	// Dropped next to the asar without being in its header
+	add("node_modules/keytar/build/Release/planted.node", "node_module_register")
+
+	s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+	appPath := string(filepath.Separator) + filepath.FromSlash(exe)
+	modules := make(map[string]electronscan.NativeModule)
+	for _, module := range s.FindNodeFiles(appPath, 0) {
+		modules[filepath.Base(module.Path)] = module
+	}
+
+	tests := []struct {
+		file       string
+		pkg        string
+		version    string
+		abi        string
+		abiVersion string
+		unpacked   bool
+	}{
+		{"keytar.node", "keytar", "1.0.0", "N-API", "3", true},
+		{"addon.node", "nan-addon", "4.1.0", "NAN", "119", false},
+		{"addon.NODE", "napi-addon", "2.0.0", "N-API", "8", false},
+		{"inner.node", "", "", "N-API", "", false},
+		{"planted.node", "keytar", "1.0.0", "NAN", "", false},
+	}
+	if len(modules) != len(tests) {
+		t.Errorf("found %d modules, want %d: %v", len(modules), len(tests), modules)
+	}
+	for _, tt := range tests {
+		module, ok := modules[tt.file]
+		if !ok {
+			t.Errorf("%s not found", tt.file)
+			continue
+		}
+		if module.Package != tt.pkg || module.PackageVersion != tt.version {
+			t.Errorf("%s: package %s@%s, want %s@%s", tt.file, module.Package, module.PackageVersion, tt.pkg, tt.version)
+		}
+		if module.ABI != tt.abi || module.ABIVersion != tt.abiVersion {
+			t.Errorf("%s: ABI %s %s, want %s %s", tt.file, module.ABI, module.ABIVersion, tt.abi, tt.abiVersion)
+		}
+		if module.Unpacked != tt.unpacked {
+			t.Errorf("%s: unpacked = %t, want %t", tt.file, module.Unpacked, tt.unpacked)
+		}
+		if module.SHA256 == "" || module.Size == 0 {
+			t.Errorf("%s: sha256 %q, size %d", tt.file, module.SHA256, module.Size)
+		}
+	}
+	if module := modules["keytar.node"]; module.Format != "PE" || len(module.Architectures) != 1 || module.Architectures[0] != "x86_64" {
+		t.Errorf("keytar.node: format %q, architectures %v", module.Format, module.Architectures)
+	}
+
+	if truncated := s.FindNodeFiles(appPath, 2); len(truncated) != 2 {
+		t.Errorf("with a limit of 2 found %d modules", len(truncated))
+	}
+}
+
+func TestNativeModuleDependencies(t *testing.T) {
+	dir := fs.ModeDir | 0o755
+	tests := []struct {
+		name    string
+		goos    string
+		imports []string
+		// layout adds files relative to the install directory, or the bundle on macOS
+		layout map[string]*fstest.MapFile
+		want   map[string]string
+	}{
+		{
+			name:    "Windows, read-only install",
+			goos:    "windows",
+			imports: []string{"node.exe", "api-ms-win-core-file-l1-1-0.dll", "kernel32.dll", "present.dll", "sqlite3.dll"},
+			layout: map[string]*fstest.MapFile{
+				"/Windows/System32/kernel32.dll": {Data: []byte("MZ")},
+				"/Windows/System32/present.dll":  {Data: []byte("MZ")},
+			},
+			want: map[string]string{
+				"node.exe":                        "",
+				"api-ms-win-core-file-l1-1-0.dll": "",
+				"kernel32.dll":                    "System32",
+				"present.dll":                     "System32",
+				"sqlite3.dll":                     "missing",
+			},
+		},
+		{
+			name:    "Windows, writable module directory",
+			goos:    "windows",
+			imports: []string{"kernel32.dll", "present.dll"},
+			layout: map[string]*fstest.MapFile{
+				"resources/app.asar.unpacked/node_modules/keytar/build/Release": {Mode: dir | 0o777},
+				"/Windows/System32/kernel32.dll":                                {Data: []byte("MZ")},
+				"/Windows/System32/present.dll":                                 {Data: []byte("MZ")},
+			},
+			want: map[string]string{
+				// KnownDLLs are always mapped from System32
+				"kernel32.dll": "System32",
+				// Searched first, so a planted copy wins
+				"present.dll": "writable",
+			},
+		},
+		{
+			name:    "macOS rpath and system libraries",
+			goos:    "darwin",
+			imports: []string{"/usr/lib/libc++.1.dylib", "@rpath/libsqlcipher.0.dylib", "@loader_path/../lib/libmissing.dylib"},
+			layout: map[string]*fstest.MapFile{
+				"Contents/Frameworks/libsqlcipher.0.dylib": {Data: []byte{0xcf, 0xfa, 0xed, 0xfe}},
+			},
+			want: map[string]string{
+				"/usr/lib/libc++.1.dylib":              "",
+				"@rpath/libsqlcipher.0.dylib":          "Frameworks",
+				"@loader_path/../lib/libmissing.dylib": "missing",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: tt.goos, NativeModules: []string{"keytar"}, ModuleImports: tt.imports}.Build(fsys, installDirs[tt.goos])
+			if err != nil {
+				t.Fatal(err)
+			}
+			base := appPath
+			if tt.goos == "windows" {
+				base = path.Dir(appPath)
+			}
+			for name, file := range tt.layout {
+				if strings.HasPrefix(name, "/") {
+					fsys[name[1:]] = file
+				} else {
+					fsys[path.Join(base, name)] = file
+				}
+			}
+
+			s := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: fsys})
+			modules := s.FindNodeFiles(string(filepath.Separator)+filepath.FromSlash(appPath), 0)
+			if len(modules) != 1 {
+				t.Fatalf("found %d modules", len(modules))
+			}
+			got := make(map[string]string)
+			for _, dep := range modules[0].Dependencies {
+				switch {
+				case dep.Missing:
+					got[dep.Name] = "missing"
+				case dep.Writable:
+					got[dep.Name] = "writable"
+				case dep.Resolved == "" || dep.Resolved == dep.Name:
+					got[dep.Name] = ""
+				default:
+					got[dep.Name] = filepath.Base(filepath.Dir(dep.Resolved))
+				}
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("dependencies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
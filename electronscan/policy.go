@@ -0,0 +1,132 @@
+package electronscan
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// Policy declares the requirements an application must meet, for use as a release gate
+type Policy struct {
+	Name                    string            `json:"name" yaml:"name"`
+	Fuses                   map[string]string `json:"fuses" yaml:"fuses"`
+	MinElectronVersion      string            `json:"min_electron_version" yaml:"min_electron_version"`
+	RequireAsarIntegrity    bool              `json:"require_asar_integrity" yaml:"require_asar_integrity"`
+	RequireSignedExecutable bool              `json:"require_signed_executable" yaml:"require_signed_executable"`
+	RequireSignedNodeFiles  bool              `json:"require_signed_node_files" yaml:"require_signed_node_files"`
+	ForbiddenRules          []string          `json:"forbidden_rules" yaml:"forbidden_rules"`
+	MaxRiskScore            *int              `json:"max_risk_score" yaml:"max_risk_score"`
+}
+
+// PolicyViolation is a requirement an application failed
+type PolicyViolation struct {
+	Requirement string `json:"requirement"`
+	Message     string `json:"message"`
+	Path        string `json:"path,omitempty"`
+}
+
+// LoadPolicy reads a policy from a .json, .yaml or .yml file and checks that
+// it only refers to fuses, states and rules the scanner knows
+func LoadPolicy(path string) (*Policy, error) {
+	var policy Policy
+	if err := decodeSettingsFile(path, &policy); err != nil {
+		return nil, err
+	}
+
+	for name, state := range policy.Fuses {
+		if !slices.Contains(FuseNames, name) {
+			return nil, fmt.Errorf("policy %s: unknown fuse %q", path, name)
+		}
+		if state != FuseEnabled && state != FuseDisabled {
+			return nil, fmt.Errorf("policy %s: fuse %s must be %q or %q, not %q", path, name, FuseEnabled, FuseDisabled, state)
+		}
+	}
+	if policy.MinElectronVersion != "" {
+		if _, ok := electronMajor(policy.MinElectronVersion); !ok {
+			return nil, fmt.Errorf("policy %s: invalid min_electron_version %q", path, policy.MinElectronVersion)
+		}
+	}
+	for _, id := range policy.ForbiddenRules {
+		if _, ok := RuleByID(id); !ok {
+			return nil, fmt.Errorf("policy %s: unknown rule %q", path, id)
+		}
+	}
+
+	return &policy, nil
+}
+
+// Evaluate checks an application against the policy and returns every failed requirement.
+// Fuse, version and signature requirements only apply to Electron applications.
+func (p *Policy) Evaluate(result AppResult) []PolicyViolation {
+	var violations []PolicyViolation
+	violate := func(requirement string, path string, format string, args ...any) {
+		violations = append(violations, PolicyViolation{
+			Requirement: requirement,
+			Message:     fmt.Sprintf(format, args...),
+			Path:        path,
+		})
+	}
+
+	if result.IsElectron {
+		executable := result.Executable
+
+		for _, name := range FuseNames {
+			want, ok := p.Fuses[name]
+			if !ok {
+				continue
+			}
+			got := result.Fuses[name]
+			if got == "" {
+				got = "unknown"
+			}
+			if got != want {
+				violate("fuses."+name, executable, "%s must be %s but is %s", name, want, got)
+			}
+		}
+
+		if p.MinElectronVersion != "" {
+			if _, ok := electronMajor(result.Version); !ok {
+				violate("min_electron_version", executable, "Electron version could not be determined (required %s)", p.MinElectronVersion)
+			} else if compareVersions(result.Version, p.MinElectronVersion) < 0 {
+				violate("min_electron_version", executable, "Electron %s is older than the required %s", result.Version, p.MinElectronVersion)
+			}
+		}
+
+		if p.RequireAsarIntegrity {
+			switch {
+			case !result.HasAsarFile:
+				violate("require_asar_integrity", result.Path, "ASAR integrity is required but the app has no app.asar")
+			case result.IntegrityHash == IntegrityHashMismatch:
+				violate("require_asar_integrity", filepath.Join(result.ResourcesDir, "app.asar"), "app.asar does not match its embedded integrity hash")
+			case !result.AsarIntegrity:
+				violate("require_asar_integrity", filepath.Join(result.ResourcesDir, "app.asar"), "ASAR integrity is required but not enabled")
+			}
+		}
+
+		if p.RequireSignedExecutable && result.Signature != SignatureSigned {
+			violate("require_signed_executable", executable, "%s is not code signed", filepath.Base(executable))
+		}
+
+		if p.RequireSignedNodeFiles {
+			for _, module := range result.NodeFiles {
+				if module.Signature != SignatureSigned {
+					violate("require_signed_node_files", module.Path, "Native module %s is not code signed", filepath.Base(module.Path))
+				}
+			}
+		}
+	}
+
+	if len(p.ForbiddenRules) > 0 {
+		for _, finding := range Findings(result) {
+			if slices.Contains(p.ForbiddenRules, finding.RuleID) {
+				violate("forbidden_rules."+finding.RuleID, finding.Path, "%s", finding.Message)
+			}
+		}
+	}
+
+	if p.MaxRiskScore != nil && result.Risk != nil && result.Risk.Score > *p.MaxRiskScore {
+		violate("max_risk_score", result.Path, "Risk score %d exceeds the allowed %d", result.Risk.Score, *p.MaxRiskScore)
+	}
+
+	return violations
+}
@@ -0,0 +1,216 @@
+package electronscan_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestPolicySeesEveryNodeFile(t *testing.T) {
+	policy := &electronscan.Policy{RequireSignedNodeFiles: true}
+	tests := []struct {
+		name string
+		opts electronscan.Options
+	}{
+		{"full inventory", electronscan.Options{}},
+		{"inventory skipped", electronscan.Options{SkipNodeFiles: true}},
+		{"inventory truncated", electronscan.Options{MaxNodeFiles: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: "windows", NativeModules: []string{"keytar", "sqlite3"}}.Build(fsys, "Program Files")
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := tt.opts
+			opts.GOOS, opts.FS, opts.Policy = "windows", fsys, policy
+			result := electronscan.New(opts).CheckApp(string(filepath.Separator) + filepath.FromSlash(appPath))
+
+			var unsigned []string
+			for _, violation := range result.PolicyViolations {
+				if violation.Requirement == "require_signed_node_files" {
+					unsigned = append(unsigned, filepath.Base(violation.Path))
+				}
+			}
+			if strings.Join(unsigned, ",") != "keytar.node,sqlite3.node" {
+				t.Errorf("unsigned modules = %v, want keytar.node and sqlite3.node", unsigned)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	maxScore := 40
+	policy := &electronscan.Policy{
+		Fuses:              map[string]string{"RunAsNode": electronscan.FuseDisabled},
+		MinElectronVersion: "30",
+		MaxRiskScore:       &maxScore,
+	}
+	tests := []struct {
+		name   string
+		result electronscan.AppResult
+		want   []string
+	}{
+		{
+			name:   "compliant",
+			result: electronscan.AppResult{IsElectron: true, Version: "30.0.0", Fuses: map[string]string{"RunAsNode": electronscan.FuseDisabled}},
+		},
+		{
+			name:   "older patch release of an older major",
+			result: electronscan.AppResult{IsElectron: true, Version: "29.4.6", Fuses: map[string]string{"RunAsNode": electronscan.FuseDisabled}},
+			want:   []string{"min_electron_version"},
+		},
+		{
+			name:   "unknown version and fuse wire",
+			result: electronscan.AppResult{IsElectron: true, Version: "unknown"},
+			want:   []string{"fuses.RunAsNode", "min_electron_version"},
+		},
+		{
+			name:   "risk over the limit on a non-Electron app",
+			result: electronscan.AppResult{Framework: electronscan.FrameworkNWJS, Risk: &electronscan.RiskScore{Score: 41}},
+			want:   []string{"max_risk_score"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range policy.Evaluate(tt.result) {
+				got = append(got, violation.Requirement)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluateRequirements(t *testing.T) {
+	maxScore := 40
+	policy := &electronscan.Policy{
+		RequireAsarIntegrity:    true,
+		RequireSignedExecutable: true,
+		ForbiddenRules:          []string{electronscan.RuleRunAsNodeEnabled},
+		MaxRiskScore:            &maxScore,
+	}
+	hardened := electronscan.AppResult{
+		Path:             "/Applications/Demo.app",
+		IsElectron:       true,
+		Version:          "unknown",
+		Executable:       "/Applications/Demo.app/Contents/MacOS/Demo",
+		ResourcesDir:     "/Applications/Demo.app/Contents/Resources",
+		HasAsarFile:      true,
+		AsarIntegrity:    true,
+		OnlyLoadFromAsar: true,
+		Signature:        electronscan.SignatureSigned,
+		// A score at the limit passes
+		Risk: &electronscan.RiskScore{Score: 40},
+	}
+	tests := []struct {
+		name   string
+		change func(r *electronscan.AppResult)
+		want   []string
+	}{
+		{
+			name:   "compliant",
+			change: func(*electronscan.AppResult) {},
+		},
+		{
+			name:   "no app.asar",
+			change: func(r *electronscan.AppResult) { r.HasAsarFile = false },
+			want:   []string{"require_asar_integrity /Applications/Demo.app"},
+		},
+		{
+			name:   "tampered app.asar",
+			change: func(r *electronscan.AppResult) { r.IntegrityHash = electronscan.IntegrityHashMismatch },
+			want:   []string{"require_asar_integrity " + filepath.Join("/Applications/Demo.app/Contents/Resources", "app.asar")},
+		},
+		{
+			name: "unsigned, with a forbidden and an allowed finding",
+			change: func(r *electronscan.AppResult) {
+				r.Signature = electronscan.SignatureUnsigned
+				r.Fuses = map[string]string{"RunAsNode": electronscan.FuseEnabled, "EnableNodeOptionsEnvironmentVariable": electronscan.FuseEnabled}
+			},
+			want: []string{
+				"require_signed_executable /Applications/Demo.app/Contents/MacOS/Demo",
+				"forbidden_rules.run-as-node-enabled /Applications/Demo.app/Contents/MacOS/Demo",
+			},
+		},
+		{
+			name: "other frameworks are only held to findings and score",
+			change: func(r *electronscan.AppResult) {
+				*r = electronscan.AppResult{Path: "/Applications/Demo.app", Framework: electronscan.FrameworkCEF, Risk: &electronscan.RiskScore{Score: 41}}
+			},
+			want: []string{"max_risk_score /Applications/Demo.app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := hardened
+			tt.change(&result)
+			var got []string
+			for _, violation := range policy.Evaluate(result) {
+				if violation.Message == "" {
+					t.Errorf("%s has no message", violation.Requirement)
+				}
+				got = append(got, violation.Requirement+" "+violation.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyFormats(t *testing.T) {
+	files := map[string]string{
+		"policy.json": `{"fuses": {"RunAsNode": "disabled"}, "min_electron_version": "30.1", "max_risk_score": 0}`,
+		"policy.yml":  "fuses:\n  RunAsNode: disabled\nmin_electron_version: \"30.1\"\nmax_risk_score: 0\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			policy, err := electronscan.LoadPolicy(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A zero max_risk_score is a limit, not a missing one
+			if policy.Fuses["RunAsNode"] != electronscan.FuseDisabled || policy.MinElectronVersion != "30.1" || policy.MaxRiskScore == nil || *policy.MaxRiskScore != 0 {
+				t.Errorf("policy = %+v", policy)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyRejectsUnknownNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{"unknown fuse", "fuses:\n  RunAsNodes: disabled\n", `unknown fuse "RunAsNodes"`},
+		{"removed is not a requirement", "fuses:\n  RunAsNode: removed\n", "must be"},
+		{"unknown rule", "forbidden_rules: [no-such-rule]\n", `unknown rule "no-such-rule"`},
+		{"invalid version", "min_electron_version: latest\n", "invalid min_electron_version"},
+		{"unknown key", "require_signed_nodes: true\n", "require_signed_nodes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(tt.policy), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := electronscan.LoadPolicy(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
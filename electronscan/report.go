@@ -0,0 +1,149 @@
+package electronscan
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan/internal/jsonschema"
+)
+
+// SchemaVersion is the version of the Report format. The minor version goes up
+// when fields are added; the major version when a field is removed or changes meaning.
+const SchemaVersion = "1.3"
+
+// reportSchema is the published JSON Schema of Report, also at electronscan/schema/report.schema.json
+//
+//go:embed schema/report.schema.json
+var reportSchema []byte
+
+// Report is the JSON document produced by a scan
+type Report struct {
+	SchemaVersion string       `json:"schema_version"`
+	Metadata      ScanMetadata `json:"metadata"`
+	Results       []AppResult  `json:"results"`
+}
+
+// ScanMetadata describes where, when and by what a scan ran, so results
+// collected from many machines can be told apart and compared
+type ScanMetadata struct {
+	Tool           string    `json:"tool"`
+	ToolVersion    string    `json:"tool_version"`
+	Host           string    `json:"host"`
+	OS             string    `json:"os"`
+	OSVersion      string    `json:"os_version,omitempty"`
+	Arch           string    `json:"arch"`
+	User           string    `json:"user"`
+	Privilege      string    `json:"privilege,omitempty"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	DurationMs     int64     `json:"duration_ms"`
+	ScannedDirs    []string  `json:"scanned_dirs"`
+	UnreadableDirs []string  `json:"unreadable_dirs"`
+}
+
+// NewReport wraps scan results with the metadata of the scan. Host, OS,
+// OSVersion and Arch describe the system scanned, which differs from the host
+// when reading an image, and are empty where the image does not record them;
+// User and Privilege describe who ran the scan. The directories are those of
+// the last Discover.
+func (s *Scanner) NewReport(results []AppResult, toolVersion string, start time.Time, end time.Time) Report {
+	if results == nil {
+		results = []AppResult{}
+	}
+
+	s.mu.Lock()
+	metadata := ScanMetadata{
+		Tool:           "asarscan",
+		ToolVersion:    toolVersion,
+		OS:             s.goos,
+		Privilege:      privilegeLevel(),
+		StartTime:      start.UTC(),
+		EndTime:        end.UTC(),
+		DurationMs:     end.Sub(start).Milliseconds(),
+		ScannedDirs:    slices.Clone(s.scannedDirs),
+		UnreadableDirs: slices.Clone(s.unreadableDirs),
+	}
+	s.mu.Unlock()
+	if metadata.ScannedDirs == nil {
+		metadata.ScannedDirs = []string{}
+	}
+	if metadata.UnreadableDirs == nil {
+		metadata.UnreadableDirs = []string{}
+	}
+	metadata.Host, metadata.OSVersion, metadata.Arch = s.hostName(), s.osVersion(), s.arch()
+	if current, err := user.Current(); err == nil {
+		metadata.User = current.Username
+	}
+
+	return Report{
+		SchemaVersion: SchemaVersion,
+		Metadata:      metadata,
+		Results:       results,
+	}
+}
+
+// ReadReport reads a JSON report. Reports from before the schema, a bare list
+// of results, are accepted with the file name standing in for the host.
+func ReadReport(path string) (Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading report: %v", err)
+	}
+
+	var report Report
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		if err := json.Unmarshal(content, &report.Results); err != nil {
+			return Report{}, fmt.Errorf("error parsing report %s: %v", path, err)
+		}
+		report.Metadata.Host = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		return report, nil
+	}
+
+	if err := json.Unmarshal(content, &report); err != nil {
+		return Report{}, fmt.Errorf("error parsing report %s: %v", path, err)
+	}
+	if report.Metadata.Host == "" {
+		report.Metadata.Host = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return report, nil
+}
+
+// ReportSchema returns the JSON Schema that every Report conforms to
+func ReportSchema() []byte {
+	return reportSchema
+}
+
+// ValidateReport checks a JSON report against ReportSchema. A report that
+// parses but does not conform returns a *SchemaError listing every problem.
+func ValidateReport(data []byte) error {
+	schema, err := jsonschema.Parse(reportSchema)
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(data); err != nil {
+		var invalid *jsonschema.ValidationError
+		if errors.As(err, &invalid) {
+			return &SchemaError{Problems: invalid.Problems}
+		}
+		return err
+	}
+	return nil
+}
+
+// SchemaError lists the places a report does not conform to ReportSchema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return (&jsonschema.ValidationError{Problems: e.Problems}).Error()
+}
@@ -0,0 +1,127 @@
+package electronscan_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+// TestReportMatchesSchema fills every optional part of a result the fixtures
+// can produce, so a field added to AppResult without the schema fails here
+func TestReportMatchesSchema(t *testing.T) {
+	maxScore := 10
+	imports := map[string][]string{"darwin": {"@rpath/libsqlcipher.dylib"}, "windows": {"sqlcipher.dll"}, "linux": {"libsqlcipher.so.0"}}
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		t.Run(goos, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			if _, err := (fixture.App{
+				GOOS:            goos,
+				Integrity:       true,
+				TamperedAsar:    true,
+				NativeModules:   []string{"keytar"},
+				ModuleImports:   imports[goos],
+				WritableModules: true,
+				AutoStart:       true,
+			}).Build(fsys, installDirs[goos]); err != nil {
+				t.Fatal(err)
+			}
+
+			s := electronscan.New(electronscan.Options{
+				GOOS: goos,
+				FS:   fsys,
+				Suppressions: []electronscan.Suppression{
+					{Rule: electronscan.RuleRunAsNodeEnabled, Expires: "2099-01-01", Justification: "test"},
+				},
+				Policy: &electronscan.Policy{RequireSignedExecutable: true, MaxRiskScore: &maxScore},
+			})
+			results, err := s.Scan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || len(results[0].SuppressedFindings) == 0 || len(results[0].PolicyViolations) == 0 || len(results[0].AutoStartEntries) == 0 {
+				t.Fatalf("results = %+v", results)
+			}
+
+			data, err := json.Marshal(s.NewReport(results, "test", time.Now(), time.Now()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := electronscan.ValidateReport(data); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestValidateReportProblems(t *testing.T) {
+	report := `{
+		"schema_version": "2.0",
+		"metadata": {"tool": "asarscan", "tool_version": "test", "host": "h", "os": "beos", "arch": "arm64", "user": "u", "start_time": "2026-01-01T00:00:00Z", "end_time": "2026-01-01T00:00:00Z"},
+		"results": [{"path": "/Applications/Demo.app", "is_electron": "yes", "colour": "red"}]
+	}`
+	err := electronscan.ValidateReport([]byte(report))
+	var invalid *electronscan.SchemaError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want a *SchemaError", err)
+	}
+
+	// Every problem is listed, not just the first
+	for _, want := range []string{"schema_version", "metadata.os", "is_electron", "colour"} {
+		if !slices.ContainsFunc(invalid.Problems, func(problem string) bool { return strings.Contains(problem, want) }) {
+			t.Errorf("problems %q do not mention %s", invalid.Problems, want)
+		}
+	}
+
+	if err := electronscan.ValidateReport([]byte(`{"schema_version": `)); err == nil || errors.As(err, &invalid) {
+		t.Errorf("truncated report error = %v, want a parse error", err)
+	}
+}
+
+func TestReadReport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// Bare lists of results from before the schema
+		"legacy-mac.json": `[{"path": "/Applications/Demo.app", "is_electron": true}]`,
+		"no-host.json":    `{"schema_version": "1.0", "metadata": {}, "results": []}`,
+		"with-host.json":  `{"schema_version": "1.3", "metadata": {"host": "build-07"}, "results": []}`,
+		"broken.json":     `{"results": [`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file    string
+		host    string
+		results int
+	}{
+		{"legacy-mac.json", "legacy-mac", 1},
+		{"no-host.json", "no-host", 0},
+		{"with-host.json", "build-07", 0},
+	}
+	for _, tt := range tests {
+		report, err := electronscan.ReadReport(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		if report.Metadata.Host != tt.host || len(report.Results) != tt.results {
+			t.Errorf("%s: host %q with %d results, want %q with %d", tt.file, report.Metadata.Host, len(report.Results), tt.host, tt.results)
+		}
+	}
+
+	if _, err := electronscan.ReadReport(filepath.Join(dir, "broken.json")); err == nil {
+		t.Error("truncated report was accepted")
+	}
+}
@@ -0,0 +1,156 @@
+package electronscan_test
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestResolveEntryPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]string
+		// folder is unpacked as resources/app, which Electron loads before app.asar
+		folder map[string]string
+		source string
+		// main is the main script relative to the app root, suffixed with " missing" if absent
+		main string
+		// preloads are the preload scripts as location:name
+		preloads []string
+		// preferences are the insecure settings as script:setting=value
+		preferences []string
+	}{
+		{
+			name:    "main defaults to index.js",
+			sources: map[string]string{"package.json": `{"name": "demo"}`, "index.js": ""},
+			source:  "asar",
+			main:    "index.js",
+		},
+		{
+			name:    "main names a folder",
+			sources: map[string]string{"package.json": `{"main": "dist"}`, "dist/index.js": ""},
+			source:  "asar",
+			main:    "dist/index.js",
+		},
+		{
+			name:    "main without an extension",
+			sources: map[string]string{"package.json": `{"main": "./src/main"}`, "src/main.cjs": ""},
+			source:  "asar",
+			main:    "src/main.cjs",
+		},
+		{
+			name:    "main missing",
+			sources: map[string]string{"package.json": `{"main": "build/main.js"}`},
+			source:  "asar",
+			main:    "build/main.js missing",
+		},
+		{
+			name:    "main cannot climb out of the archive",
+			sources: map[string]string{"package.json": `{"main": "../../main.js"}`, "main.js": ""},
+			source:  "asar",
+			main:    "main.js",
+		},
+		{
+			name: "requires, imports and cycles are followed once",
+			sources: map[string]string{
+				"package.json": `{"main": "main.mjs"}`,
+				"main.mjs":     "import { open } from './lib/windows.mjs'\nconst later = import('./lib/later.js')\n",
+				"lib/windows.mjs": `import './cycle.js'
+new BrowserWindow({ webPreferences: { preload: path.join(__dirname, '..', 'preload.js'), sandbox: true, nodeIntegration: false } })
+new BrowserWindow({ webPreferences: { preload: path.join(__dirname, '../preload.js'), contextIsolation: !1 } })
+`,
+				"lib/cycle.js":     "require('./windows.mjs')\n",
+				"lib/later.js":     "new BrowserWindow({ webPreferences: { preload: path.join(app.getAppPath(), 'later-preload.js'), webSecurity: false, contextIsolation: false } })\n",
+				"preload.js":       "",
+				"later-preload.js": "",
+			},
+			source:      "asar",
+			main:        "main.mjs",
+			preloads:    []string{"asar:later-preload.js", "asar:preload.js"},
+			preferences: []string{"lib/later.js:contextIsolation=false", "lib/later.js:webSecurity=false", "lib/windows.mjs:contextIsolation=false"},
+		},
+		{
+			name: "preload on disk outside the app",
+			sources: map[string]string{
+				"package.json": `{}`,
+				"index.js":     `new BrowserWindow({ webPreferences: { preload: "/opt/demo/preload.js" } })`,
+			},
+			source:   "asar",
+			main:     "index.js",
+			preloads: []string{"filesystem:/opt/demo/preload.js missing"},
+		},
+		{
+			name:    "resources/app folder wins over app.asar",
+			sources: map[string]string{"package.json": `{"main": "asar.js"}`, "asar.js": ""},
+			folder: map[string]string{
+				"package.json": `{"main": "folder.js"}`,
+				"folder.js":    "new BrowserWindow({ webPreferences: { preload: `${__dirname}/bridge.js` } })\n",
+				"bridge.js":    "",
+			},
+			source:   "folder",
+			main:     "folder.js",
+			preloads: []string{"filesystem:bridge.js"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: "windows", Sources: tt.sources}.Build(fsys, "Program Files")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources := path.Join(path.Dir(appPath), "resources")
+			for name, content := range tt.folder {
+				fsys[path.Join(resources, "app", name)] = &fstest.MapFile{Data: []byte(content), Mode: 0o644}
+			}
+			s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+
+			entry := s.ResolveEntryPoint(string(filepath.Separator) + filepath.FromSlash(appPath))
+			if entry == nil {
+				t.Fatal("no entry point")
+			}
+			if entry.Source != tt.source {
+				t.Errorf("source = %q, want %q", entry.Source, tt.source)
+			}
+
+			// relative names a script by its path inside the source
+			relative := func(info electronscan.ScriptInfo) string {
+				name := filepath.ToSlash(info.Path)
+				for _, root := range []string{"/" + resources + "/app.asar/", "/" + resources + "/app/"} {
+					name = strings.TrimPrefix(name, root)
+				}
+				if info.Missing {
+					name += " missing"
+				}
+				return name
+			}
+			if got := relative(entry.Main); got != tt.main {
+				t.Errorf("main = %q, want %q", got, tt.main)
+			}
+
+			var preloads []string
+			for _, preload := range entry.Preloads {
+				preloads = append(preloads, preload.Location+":"+relative(preload))
+			}
+			slices.Sort(preloads)
+			if !slices.Equal(preloads, tt.preloads) {
+				t.Errorf("preloads = %q, want %q", preloads, tt.preloads)
+			}
+
+			var preferences []string
+			for _, pref := range entry.WebPreferences {
+				preferences = append(preferences, pref.Script+":"+pref.Setting+"="+pref.Value)
+			}
+			slices.Sort(preferences)
+			if !slices.Equal(preferences, tt.preferences) {
+				t.Errorf("webPreferences = %q, want %q", preferences, tt.preferences)
+			}
+		})
+	}
+}
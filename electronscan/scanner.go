@@ -0,0 +1,420 @@
+// Package electronscan finds Electron and other Chromium-based applications and
+// reports the ways their code can be modified or injected into: ASAR integrity,
+// fuses, writable native modules, entry points, updaters and more.
+package electronscan
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures a Scanner. The zero value scans the default locations
+// of the current system with one worker per CPU.
+type Options struct {
+	// Roots are the directories searched for applications; empty means DefaultSearchRoots
+	Roots []string
+	// GOOS is the application layout to expect, "darwin", "windows" or "linux"; empty means runtime.GOOS
+	GOOS string
+	// FS is read instead of the host filesystem when set, such as an fstest.MapFS,
+	// a zip archive or a disk image reader. Roots and the paths in results keep
+	// their OS form; the volume name and leading separator are dropped to look them up.
+	FS fs.FS
+	// Logger receives progress and debug messages; nil discards them
+	Logger *slog.Logger
+	// Workers is how many applications CheckApps checks at once; 0 means one per CPU
+	Workers int
+	// SkipNodeFiles turns off the .node file inventory, unless Policy requires
+	// signed native modules
+	SkipNodeFiles bool
+	// MaxNodeFiles limits the .node files inventoried per application; 0 means
+	// unlimited. A Policy requiring signed native modules lifts the limit.
+	MaxNodeFiles int
+	// Exclude removes applications from the ones Discover finds
+	Exclude []Exclusion
+	// Suppressions hide accepted findings until they expire
+	Suppressions []Suppression
+	// Policy is evaluated against every recognized application when set
+	Policy *Policy
+}
+
+// Scanner finds Electron and other Chromium-based applications and checks them
+// for ways to inject code. It is safe for concurrent use.
+type Scanner struct {
+	opts   Options
+	goos   string
+	fsys   fs.FS
+	logger *slog.Logger
+
+	// Directories read and failed by the last Discover, for the report metadata
+	mu             sync.Mutex
+	scannedDirs    []string
+	unreadableDirs []string
+	// Auto-start entries, read on first use after each Discover
+	autoStart *autoStartCache
+}
+
+// New creates a Scanner
+func New(opts Options) *Scanner {
+	s := &Scanner{
+		opts:   opts,
+		goos:   opts.GOOS,
+		fsys:   opts.FS,
+		logger: opts.Logger,
+	}
+	if s.fsys == nil {
+		s.fsys = hostFS{}
+	}
+	if s.goos == "" {
+		s.goos = runtime.GOOS
+	}
+	if s.logger == nil {
+		s.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return s
+}
+
+// Scan discovers applications under the search roots and checks each one
+func (s *Scanner) Scan() ([]AppResult, error) {
+	apps, err := s.Discover()
+	if err != nil {
+		return nil, err
+	}
+	return s.CheckApps(apps, nil), nil
+}
+
+// CheckApps checks applications with up to Options.Workers at a time and returns
+// the results in the same order. onResult, if not nil, is called one at a time as
+// each application completes, with the number checked so far; it may amend the result.
+func (s *Scanner) CheckApps(appPaths []string, onResult func(result *AppResult, checked int)) []AppResult {
+	workers := s.opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]AppResult, len(appPaths))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	checked := 0
+
+	for range min(workers, len(appPaths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.CheckApp(appPaths[i])
+				if onResult != nil {
+					mu.Lock()
+					checked++
+					onResult(&results[i], checked)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range appPaths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// CheckApp runs every check on one application: detection, the native module
+// inventory, suppressions, the risk score and the policy
+func (s *Scanner) CheckApp(appPath string) AppResult {
+	s.logger.Debug("Checking ASAR integrity", "app", appPath)
+
+	result := s.CheckAsarIntegrityForApp(appPath)
+	if result.Framework == "" {
+		return result
+	}
+
+	// A policy requiring signed native modules must see every one of them,
+	// or it would pass on the modules left out
+	skipNodeFiles, maxNodeFiles := s.opts.SkipNodeFiles, s.opts.MaxNodeFiles
+	if s.opts.Policy != nil && s.opts.Policy.RequireSignedNodeFiles {
+		skipNodeFiles, maxNodeFiles = false, 0
+	}
+	if !skipNodeFiles {
+		s.logger.Debug("Searching for .node files", "app", appPath)
+		result.NodeFiles = s.FindNodeFiles(appPath, maxNodeFiles)
+	}
+
+	result.AutoStartEntries = s.AutoStartFor(result)
+	result.AutoStart = len(result.AutoStartEntries) > 0
+
+	// Suppressed findings count towards neither the score nor the policy
+	ApplySuppressions(&result, s.opts.Suppressions, time.Now())
+	for _, suppressed := range result.SuppressedFindings {
+		s.logger.Debug("Suppressed finding", "app", appPath, "rule", suppressed.RuleID, "path", suppressed.Path, "justification", suppressed.Justification)
+	}
+	result.Risk = ScoreApp(result)
+
+	if s.opts.Policy != nil {
+		result.PolicyViolations = s.opts.Policy.Evaluate(result)
+	}
+	return result
+}
+
+// TargetOS returns the operating system whose application layout is expected
+func (s *Scanner) TargetOS() string {
+	return s.goos
+}
+
+// DefaultSearchRoots returns the common application locations of the target system.
+// When Options.FS is set they are taken from the user profiles found in it.
+func (s *Scanner) DefaultSearchRoots() []string {
+	if !s.isHost() {
+		return s.imageSearchRoots()
+	}
+	switch s.goos {
+	case "darwin":
+		return []string{
+			"/Applications",
+			filepath.Join(os.Getenv("HOME"), "Applications"),
+		}
+	case "windows":
+		return []string{
+			filepath.Join(os.Getenv("ProgramFiles")),
+			filepath.Join(os.Getenv("ProgramFiles(x86)")),
+			filepath.Join(os.Getenv("LOCALAPPDATA"), "Programs"),
+		}
+	case "linux":
+		return append(slices.Clone(linuxSearchRoots), filepath.Join(os.Getenv("HOME"), ".local", "share", "flatpak", "app"))
+	default:
+		return nil
+	}
+}
+
+// linuxSearchRoots are where Linux packages install applications: deb and rpm
+// packages under /opt and /usr, snaps and system-wide flatpaks
+var linuxSearchRoots = []string{
+	"/opt",
+	"/usr/lib",
+	"/usr/share",
+	"/usr/local",
+	"/snap",
+	"/var/lib/flatpak/app",
+}
+
+// imageSearchRoots returns the system-wide application folders of a disk image
+// and the per-user ones of every profile in it
+func (s *Scanner) imageSearchRoots() []string {
+	switch s.goos {
+	case "darwin":
+		roots := []string{"/Applications"}
+		homes := s.userHomes("/Users", "Shared")
+		for _, user := range sortedUsers(homes) {
+			roots = append(roots, filepath.Join(homes[user], "Applications"))
+		}
+		return roots
+	case "windows":
+		root, _ := s.windowsSystemDrive()
+		roots := []string{filepath.Join(root, "Program Files"), filepath.Join(root, "Program Files (x86)")}
+		homes := s.userHomes(filepath.Join(root, "Users"), "Public", "Default", "Default User", "All Users")
+		for _, user := range sortedUsers(homes) {
+			roots = append(roots, filepath.Join(homes[user], "AppData", "Local", "Programs"))
+		}
+		return roots
+	case "linux":
+		roots := slices.Clone(linuxSearchRoots)
+		homes := s.userHomes("/home")
+		for _, user := range sortedUsers(homes) {
+			roots = append(roots, filepath.Join(homes[user], ".local", "share", "flatpak", "app"))
+		}
+		return roots
+	default:
+		return nil
+	}
+}
+
+// Discover searches the search roots for applications that may embed Chromium,
+// leaving out excluded ones
+func (s *Scanner) Discover() ([]string, error) {
+	searchDirs := s.opts.Roots
+	if len(searchDirs) == 0 {
+		searchDirs = s.DefaultSearchRoots()
+	}
+
+	s.mu.Lock()
+	s.scannedDirs, s.unreadableDirs = []string{}, []string{}
+	s.autoStart = nil
+	s.mu.Unlock()
+
+	var apps []string
+	var err error
+	switch s.goos {
+	case "darwin":
+		apps, err = s.scanForElectronAppsMacos(searchDirs)
+	case "windows":
+		apps, err = s.scanForElectronAppsWindows(searchDirs)
+	case "linux":
+		apps, err = s.scanForElectronAppsLinux(searchDirs)
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", s.goos)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(apps, func(app string) bool {
+		if s.isExcluded(app) {
+			s.logger.Debug("Skipping excluded application", "app", app)
+			return true
+		}
+		return false
+	}), nil
+}
+
+// recordDir notes a search root that was scanned or a directory that could not be read
+func (s *Scanner) recordDir(dir string, readable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if readable {
+		s.scannedDirs = append(s.scannedDirs, dir)
+	} else {
+		s.unreadableDirs = append(s.unreadableDirs, dir)
+	}
+}
+
+// scanForElectronAppsMacos searches macOS for Electron applications
+func (s *Scanner) scanForElectronAppsMacos(searchDirs []string) ([]string, error) {
+	var appPaths []string
+
+	for _, dir := range searchDirs {
+		s.logger.Debug("Scanning directory", "dir", dir)
+
+		// Check if directory exists
+		if _, err := s.stat(dir); os.IsNotExist(err) {
+			s.logger.Debug("Directory does not exist", "dir", dir)
+			continue
+		}
+		s.recordDir(dir, true)
+
+		// Walk the directory looking for .app bundles
+		err := s.walk(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				s.logger.Debug("Error accessing path", "path", path, "error", err)
+				if d == nil || d.IsDir() {
+					s.recordDir(path, false)
+				}
+				return nil // Continue despite error
+			}
+
+			// Check for .app directories (bundles)
+			if d.IsDir() && strings.HasSuffix(path, ".app") {
+				s.logger.Debug("Found app bundle", "path", path)
+				appPaths = append(appPaths, path)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("error scanning directory %s: %v", dir, err)
+		}
+	}
+
+	return appPaths, nil
+}
+
+// scanForElectronAppsWindows searches Windows for Electron applications
+func (s *Scanner) scanForElectronAppsWindows(searchDirs []string) ([]string, error) {
+	var appPaths []string
+
+	for _, dir := range searchDirs {
+		s.logger.Debug("Scanning directory", "dir", dir)
+
+		// Check if directory exists
+		if _, err := s.stat(dir); os.IsNotExist(err) {
+			s.logger.Debug("Directory does not exist", "dir", dir)
+			continue
+		}
+		s.recordDir(dir, true)
+
+		// Walk the directory looking for potential Electron apps
+		err := s.walk(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				s.logger.Debug("Error accessing path", "path", path, "error", err)
+				if d == nil || d.IsDir() {
+					s.recordDir(path, false)
+				}
+				return nil // Continue despite error
+			}
+
+			// Look for .exe files or directories containing them
+			if !d.IsDir() && strings.HasSuffix(path, ".exe") {
+				resourcesDir := filepath.Join(filepath.Dir(path), "resources")
+				if _, err := s.stat(resourcesDir); err == nil {
+					s.logger.Debug("Found potential Electron app", "path", path)
+					appPaths = append(appPaths, path)
+				} else if s.HasWindowsFrameworkMarker(filepath.Dir(path)) {
+					s.logger.Debug("Found potential Chromium-based app", "path", path)
+					appPaths = append(appPaths, path)
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("error scanning directory %s: %v", dir, err)
+		}
+	}
+
+	return appPaths, nil
+}
+
+// scanForElectronAppsLinux searches Linux for Electron applications. Install
+// directories have no naming convention, so they are found by their
+// resources/app.asar and the executable is picked from the files next to it.
+func (s *Scanner) scanForElectronAppsLinux(searchDirs []string) ([]string, error) {
+	var appPaths []string
+
+	for _, dir := range searchDirs {
+		s.logger.Debug("Scanning directory", "dir", dir)
+
+		// Check if directory exists
+		if _, err := s.stat(dir); os.IsNotExist(err) {
+			s.logger.Debug("Directory does not exist", "dir", dir)
+			continue
+		}
+		s.recordDir(dir, true)
+
+		err := s.walk(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				s.logger.Debug("Error accessing path", "path", path, "error", err)
+				if d == nil || d.IsDir() {
+					s.recordDir(path, false)
+				}
+				return nil // Continue despite error
+			}
+
+			if !d.IsDir() && d.Name() == "app.asar" && filepath.Base(filepath.Dir(path)) == "resources" {
+				if exe := s.linuxMainExecutable(filepath.Dir(filepath.Dir(path))); exe != "" {
+					s.logger.Debug("Found potential Electron app", "path", exe)
+					appPaths = append(appPaths, exe)
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("error scanning directory %s: %v", dir, err)
+		}
+	}
+
+	return appPaths, nil
+}
@@ -0,0 +1,85 @@
+package electronscan_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestDiscoverImage(t *testing.T) {
+	tests := []struct {
+		goos string
+		dirs []string
+	}{
+		{"darwin", []string{"Applications", "Users/alice/Applications"}},
+		{"windows", []string{"Program Files", "Program Files (x86)", "Users/bob/AppData/Local/Programs"}},
+		{"linux", []string{"opt", "usr/share", "snap/demo/12", "home/carol/.local/share/flatpak/app/com.example.Demo/x86_64/stable/active/files"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			var want []string
+			for _, dir := range tt.dirs {
+				appPath, err := fixture.App{GOOS: tt.goos}.Build(fsys, dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, string(filepath.Separator)+filepath.FromSlash(appPath))
+			}
+
+			apps, err := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: fsys}).Discover()
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(apps)
+			slices.Sort(want)
+			if !slices.Equal(apps, want) {
+				t.Errorf("apps = %q, want %q", apps, want)
+			}
+		})
+	}
+}
+
+func TestCheckAppsKeepsOrder(t *testing.T) {
+	fsys := fstest.MapFS{}
+	var apps []string
+	for _, name := range []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"} {
+		appPath, err := fixture.App{Name: name}.Build(fsys, "Applications")
+		if err != nil {
+			t.Fatal(err)
+		}
+		apps = append(apps, string(filepath.Separator)+filepath.FromSlash(appPath))
+	}
+	apps = append(apps, string(filepath.Separator)+filepath.FromSlash("Applications/Missing.app"))
+
+	s := electronscan.New(electronscan.Options{GOOS: "darwin", FS: fsys, Workers: 3})
+	var counts []int
+	results := s.CheckApps(apps, func(result *electronscan.AppResult, checked int) {
+		counts = append(counts, checked)
+		result.PolicyViolations = []electronscan.PolicyViolation{{Requirement: "amended"}}
+	})
+
+	// Results come back in input order, whatever order they completed in,
+	// and keep what the callback changed
+	if len(results) != len(apps) {
+		t.Fatalf("got %d results, want %d", len(results), len(apps))
+	}
+	for i, result := range results {
+		if result.Path != apps[i] || len(result.PolicyViolations) != 1 {
+			t.Errorf("result %d = %s with %d violations, want %s amended", i, result.Path, len(result.PolicyViolations), apps[i])
+		}
+	}
+	if results[len(results)-1].Framework != "" {
+		t.Errorf("missing app recognized as %q", results[len(results)-1].Framework)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6}; !slices.Equal(counts, want) {
+		t.Errorf("progress = %v, want %v", counts, want)
+	}
+	if results := s.CheckApps(nil, nil); len(results) != 0 {
+		t.Errorf("results for no apps = %+v", results)
+	}
+}
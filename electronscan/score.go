@@ -0,0 +1,108 @@
+package electronscan
+
+// Score-only severity labels, alongside the finding severities
+const (
+	SeverityCritical = "critical"
+	SeverityNone     = "none"
+)
+
+// severityRanks orders severity labels for filtering
+var severityRanks = map[string]int{
+	SeverityNone:     0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// ruleWeights is how many points each rule adds to the risk score.
+// A rule counts once however many findings it produced.
+var ruleWeights = map[string]int{
+	RuleAsarIntegrityMismatch:   40,
+	RuleAsarIntegrityDisabled:   20,
+	RuleOnlyLoadFromAsarOff:     10,
+	RuleRunAsNodeEnabled:        20,
+	RuleNodeOptionsEnabled:      10,
+	RuleNodeCliInspectEnabled:   10,
+	RuleWritableNativeModule:    20,
+	RuleHijackableDependency:    15,
+	RuleWritableEntryScript:     20,
+	RuleInsecureWebPreferences:  10,
+	RuleInsecureUpdateFeed:      10,
+	RuleUpdateSignatureDisabled: 10,
+	RuleWritableUpdateCache:     5,
+	RuleFrameworkWeakness:       15,
+	RuleUnsignedExecutable:      10,
+	RuleUnsupportedElectron:     10,
+}
+
+// RiskFactor is one rule's contribution to a risk score
+type RiskFactor struct {
+	RuleID   string `json:"rule_id"`
+	Points   int    `json:"points"`
+	Findings int    `json:"findings"`
+	Example  string `json:"example"`
+}
+
+// RiskScore rates how exposed an application is to code injection, from 0 to 100
+type RiskScore struct {
+	Score    int          `json:"score"`
+	Severity string       `json:"severity"`
+	Factors  []RiskFactor `json:"factors,omitempty"`
+}
+
+// ScoreApp combines the findings of an application into a risk score.
+// Call it once native modules have been inventoried.
+func ScoreApp(result AppResult) *RiskScore {
+	risk := &RiskScore{}
+
+	index := make(map[string]int)
+	for _, finding := range Findings(result) {
+		if i, ok := index[finding.RuleID]; ok {
+			risk.Factors[i].Findings++
+			continue
+		}
+		index[finding.RuleID] = len(risk.Factors)
+		risk.Factors = append(risk.Factors, RiskFactor{
+			RuleID:   finding.RuleID,
+			Points:   ruleWeights[finding.RuleID],
+			Findings: 1,
+			Example:  finding.Message,
+		})
+		risk.Score += ruleWeights[finding.RuleID]
+	}
+
+	risk.Score = min(risk.Score, 100)
+	risk.Severity = ScoreSeverity(risk.Score)
+	return risk
+}
+
+// ScoreSeverity maps a score to a severity label
+func ScoreSeverity(score int) string {
+	switch {
+	case score >= 80:
+		return SeverityCritical
+	case score >= 50:
+		return SeverityHigh
+	case score >= 25:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityNone
+	}
+}
+
+// SeverityAtLeast reports whether severity is the same as or worse than minimum.
+// Unknown labels never pass.
+func SeverityAtLeast(severity string, minimum string) bool {
+	rank, ok := severityRanks[severity]
+	minRank, minOk := severityRanks[minimum]
+	return ok && minOk && rank >= minRank
+}
+
+// ValidSeverity reports whether a label can be used as a severity filter
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+	return ok
+}
@@ -0,0 +1,110 @@
+package electronscan_test
+
+import (
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+)
+
+func TestScoreApp(t *testing.T) {
+	exe := "/Applications/Demo.app/Contents/MacOS/Demo"
+	result := electronscan.AppResult{
+		Path:             "/Applications/Demo.app",
+		IsElectron:       true,
+		Version:          "unknown",
+		Executable:       exe,
+		HasAsarFile:      true,
+		AsarIntegrity:    true,
+		OnlyLoadFromAsar: true,
+		Fuses:            map[string]string{"RunAsNode": electronscan.FuseEnabled},
+		NodeFiles: []electronscan.NativeModule{{
+			Path: "/Applications/Demo.app/Contents/Resources/app.asar.unpacked/a.node",
+			Dependencies: []electronscan.LibraryDependency{
+				{Name: "@rpath/liba.dylib", Missing: true},
+				{Name: "@rpath/libb.dylib", Missing: true},
+				{Name: "@rpath/libc.dylib", Writable: true},
+			},
+		}},
+	}
+
+	// A rule adds its points once, however many findings it produced
+	risk := electronscan.ScoreApp(result)
+	if risk.Score != 35 || risk.Severity != electronscan.SeverityMedium || len(risk.Factors) != 2 {
+		t.Fatalf("risk = %+v, want 35 (medium) from two rules", risk)
+	}
+	if factor := risk.Factors[1]; factor.RuleID != electronscan.RuleHijackableDependency || factor.Points != 15 || factor.Findings != 3 || factor.Example == "" {
+		t.Errorf("dependency factor = %+v", factor)
+	}
+
+	// Suppressed findings do not count
+	result.SuppressedFindings = []electronscan.SuppressedFinding{{
+		Finding: electronscan.Finding{RuleID: electronscan.RuleRunAsNodeEnabled, Severity: electronscan.SeverityHigh, Path: exe, Message: electronscan.Findings(result)[0].Message},
+	}}
+	if risk := electronscan.ScoreApp(result); risk.Score != 15 || risk.Severity != electronscan.SeverityLow {
+		t.Errorf("risk with RunAsNode suppressed = %+v, want 15 (low)", risk)
+	}
+
+	// Every rule at once is capped at 100
+	everything := electronscan.AppResult{
+		Path:          "/Applications/Demo.app",
+		IsElectron:    true,
+		Version:       "1.8.8",
+		Executable:    exe,
+		HasAsarFile:   true,
+		IntegrityHash: electronscan.IntegrityHashMismatch,
+		Signature:     electronscan.SignatureUnsigned,
+		Fuses: map[string]string{
+			"RunAsNode":                            electronscan.FuseEnabled,
+			"EnableNodeOptionsEnvironmentVariable": electronscan.FuseEnabled,
+		},
+		NodeFiles: []electronscan.NativeModule{{Path: "/a.node", Writable: true}},
+	}
+	if risk := electronscan.ScoreApp(everything); risk.Score != 100 || risk.Severity != electronscan.SeverityCritical {
+		t.Errorf("risk = %+v, want capped at 100", risk)
+	}
+
+	if risk := electronscan.ScoreApp(electronscan.AppResult{Path: "/Applications/Notes.app"}); risk.Score != 0 || risk.Severity != electronscan.SeverityNone || risk.Factors != nil {
+		t.Errorf("risk without findings = %+v", risk)
+	}
+}
+
+func TestScoreSeverity(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{0, electronscan.SeverityNone},
+		{1, electronscan.SeverityLow},
+		{24, electronscan.SeverityLow},
+		{25, electronscan.SeverityMedium},
+		{49, electronscan.SeverityMedium},
+		{50, electronscan.SeverityHigh},
+		{79, electronscan.SeverityHigh},
+		{80, electronscan.SeverityCritical},
+		{100, electronscan.SeverityCritical},
+	}
+	for _, tt := range tests {
+		if got := electronscan.ScoreSeverity(tt.score); got != tt.want {
+			t.Errorf("ScoreSeverity(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity, minimum string
+		want              bool
+	}{
+		{electronscan.SeverityCritical, electronscan.SeverityHigh, true},
+		{electronscan.SeverityHigh, electronscan.SeverityHigh, true},
+		{electronscan.SeverityMedium, electronscan.SeverityHigh, false},
+		{electronscan.SeverityNone, electronscan.SeverityNone, true},
+		{"severe", electronscan.SeverityLow, false},
+		{electronscan.SeverityHigh, "HIGH", false},
+	}
+	for _, tt := range tests {
+		if got := electronscan.SeverityAtLeast(tt.severity, tt.minimum); got != tt.want {
+			t.Errorf("SeverityAtLeast(%q, %q) = %t, want %t", tt.severity, tt.minimum, got, tt.want)
+		}
+	}
+}
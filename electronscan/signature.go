@@ -0,0 +1,86 @@
+package electronscan
+
+import (
+	"debug/macho"
+	"debug/pe"
+)
+
+// Code signature states as reported in AppResult.Signature
+const (
+	SignatureSigned   = "signed"
+	SignatureUnsigned = "unsigned"
+)
+
+// loadCmdCodeSignature is LC_CODE_SIGNATURE, which debug/macho does not name
+const loadCmdCodeSignature = 0x1d
+
+// imageDirectoryEntrySecurity indexes the Authenticode certificate table in the PE data directories
+const imageDirectoryEntrySecurity = 4
+
+// CodeSignatureStatus reports whether an executable carries a code signature.
+// The signature is only located, not validated. It returns "" if the format is not recognized.
+func (s *Scanner) CodeSignatureStatus(executablePath string) string {
+	ra, _, err := s.openReaderAt(executablePath)
+	if err != nil {
+		return ""
+	}
+	defer ra.Close()
+
+	if fat, err := macho.NewFatFile(ra); err == nil {
+		for _, arch := range fat.Arches {
+			if !machoHasCodeSignature(arch.File) {
+				return SignatureUnsigned
+			}
+		}
+		return SignatureSigned
+	}
+
+	if f, err := macho.NewFile(ra); err == nil {
+		if machoHasCodeSignature(f) {
+			return SignatureSigned
+		}
+		return SignatureUnsigned
+	}
+
+	if f, err := pe.NewFile(ra); err == nil {
+		var dirs []pe.DataDirectory
+		switch header := f.OptionalHeader.(type) {
+		case *pe.OptionalHeader64:
+			dirs = header.DataDirectory[:min(int(header.NumberOfRvaAndSizes), len(header.DataDirectory))]
+		case *pe.OptionalHeader32:
+			dirs = header.DataDirectory[:min(int(header.NumberOfRvaAndSizes), len(header.DataDirectory))]
+		}
+		if len(dirs) > imageDirectoryEntrySecurity && dirs[imageDirectoryEntrySecurity].Size > 0 {
+			return SignatureSigned
+		}
+		return SignatureUnsigned
+	}
+
+	return ""
+}
+
+// machoHasCodeSignature looks for an LC_CODE_SIGNATURE load command
+func machoHasCodeSignature(f *macho.File) bool {
+	for _, load := range f.Loads {
+		raw := load.Raw()
+		if len(raw) >= 4 && f.ByteOrder.Uint32(raw) == loadCmdCodeSignature {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyAppSignature reports whether the code signature of an application is
+// valid: the whole bundle, sealed resources included, on macOS and the
+// Authenticode signature of the executable on Windows. It is false when the
+// scan does not read the host filesystem or the host cannot validate it.
+func (s *Scanner) verifyAppSignature(appPath string) bool {
+	if !s.isHost() {
+		return false
+	}
+	if s.goos == "darwin" {
+		return verifyCodeSignature(appPath)
+	}
+	executable := s.GetExecutablePath(appPath)
+	return executable != "" && verifyCodeSignature(executable)
+}
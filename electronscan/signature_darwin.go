@@ -0,0 +1,8 @@
+package electronscan
+
+import "os/exec"
+
+// verifyCodeSignature validates the code signature of a bundle or binary with codesign
+func verifyCodeSignature(path string) bool {
+	return exec.Command("/usr/bin/codesign", "--verify", "--deep", "--strict", path).Run() == nil
+}
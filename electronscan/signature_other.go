@@ -0,0 +1,8 @@
+//go:build !darwin && !windows
+
+package electronscan
+
+// verifyCodeSignature is only available on macOS and Windows
+func verifyCodeSignature(path string) bool {
+	return false
+}
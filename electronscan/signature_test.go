@@ -0,0 +1,34 @@
+package electronscan_test
+
+import (
+	"testing"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestCodeSignatureStatus(t *testing.T) {
+	tests := []struct {
+		goos   string
+		signed bool
+		want   string
+	}{
+		{"darwin", false, electronscan.SignatureUnsigned},
+		{"darwin", true, electronscan.SignatureSigned},
+		{"windows", false, electronscan.SignatureUnsigned},
+		{"windows", true, electronscan.SignatureSigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.want, func(t *testing.T) {
+			s, appPath := buildFixture(t, fixture.App{GOOS: tt.goos, Signed: tt.signed, NativeModules: []string{"keytar"}})
+			if got := s.CodeSignatureStatus(s.GetExecutablePath(appPath)); got != tt.want {
+				t.Errorf("executable signature = %q, want %q", got, tt.want)
+			}
+			for _, module := range s.FindNodeFiles(appPath, 0) {
+				if module.Signature != tt.want {
+					t.Errorf("%s signature = %q, want %q", module.Path, module.Signature, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package electronscan
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Arguments of WinVerifyTrust
+const (
+	wtdUINone               = 2
+	wtdRevokeNone           = 0
+	wtdChoiceFile           = 1
+	wtdStateActionVerify    = 1
+	wtdStateActionClose     = 2
+	wtdRevocationCheckNone  = 0x10
+	wtdCacheOnlyURLRetrieve = 0x1000
+)
+
+// wintrustActionGenericVerifyV2 is WINTRUST_ACTION_GENERIC_VERIFY_V2, the Authenticode policy
+var wintrustActionGenericVerifyV2 = syscall.GUID{
+	Data1: 0xaac56b,
+	Data2: 0xcd44,
+	Data3: 0x11d0,
+	Data4: [8]byte{0x8c, 0xc2, 0x00, 0xc0, 0x4f, 0xc2, 0x95, 0xee},
+}
+
+// wintrustFileInfo is WINTRUST_FILE_INFO
+type wintrustFileInfo struct {
+	size         uint32
+	filePath     *uint16
+	file         syscall.Handle
+	knownSubject *syscall.GUID
+}
+
+// wintrustData is WINTRUST_DATA
+type wintrustData struct {
+	size               uint32
+	policyCallbackData uintptr
+	sipClientData      uintptr
+	uiChoice           uint32
+	revocationChecks   uint32
+	unionChoice        uint32
+	file               *wintrustFileInfo
+	stateAction        uint32
+	stateData          syscall.Handle
+	urlReference       *uint16
+	provFlags          uint32
+	uiContext          uint32
+	signatureSettings  uintptr
+}
+
+var (
+	wintrust           = syscall.NewLazyDLL("wintrust.dll")
+	procWinVerifyTrust = wintrust.NewProc("WinVerifyTrust")
+)
+
+// verifyCodeSignature validates the Authenticode signature of a file and its
+// chain of trust, without going to the network for revocation
+func verifyCodeSignature(path string) bool {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	file := wintrustFileInfo{filePath: pathPtr}
+	file.size = uint32(unsafe.Sizeof(file))
+	data := wintrustData{
+		uiChoice:         wtdUINone,
+		revocationChecks: wtdRevokeNone,
+		unionChoice:      wtdChoiceFile,
+		file:             &file,
+		stateAction:      wtdStateActionVerify,
+		provFlags:        wtdRevocationCheckNone | wtdCacheOnlyURLRetrieve,
+	}
+	data.size = uint32(unsafe.Sizeof(data))
+
+	r, _, _ := procWinVerifyTrust.Call(uintptr(syscall.InvalidHandle), uintptr(unsafe.Pointer(&wintrustActionGenericVerifyV2)), uintptr(unsafe.Pointer(&data)))
+
+	// The state data has to be released whatever the outcome
+	data.stateAction = wtdStateActionClose
+	procWinVerifyTrust.Call(uintptr(syscall.InvalidHandle), uintptr(unsafe.Pointer(&wintrustActionGenericVerifyV2)), uintptr(unsafe.Pointer(&data)))
+
+	return r == 0
+}
@@ -0,0 +1,273 @@
+package electronscan
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdaterInfo describes how an application updates itself
+type UpdaterInfo struct {
+	Mechanisms            []string `json:"mechanisms"`
+	ConfigPath            string   `json:"config_path,omitempty"`
+	Provider              string   `json:"provider,omitempty"`
+	FeedURL               string   `json:"feed_url,omitempty"`
+	PublisherNames        []string `json:"publisher_names,omitempty"`
+	SignatureVerification string   `json:"signature_verification,omitempty"`
+	CachePath             string   `json:"cache_path,omitempty"`
+	CacheWritable         bool     `json:"cache_writable"`
+	Issues                []string `json:"issues,omitempty"`
+}
+
+var (
+	feedURLRegex     = regexp.MustCompile("setFeedURL\\(\\s*(?:\\{\\s*url\\s*:\\s*)?['\"`](https?://[^'\"`]+)['\"`]")
+	updateServiceURL = "https://update.electronjs.org"
+)
+
+// AnalyzeUpdater detects the update mechanism of an Electron application and
+// flags insecure feeds, disabled signature verification and writable caches
+func (s *Scanner) AnalyzeUpdater(appPath string) *UpdaterInfo {
+	resourcesDir := s.GetResourcesPath(appPath)
+	if resourcesDir == "" {
+		return nil
+	}
+
+	updater := &UpdaterInfo{}
+
+	// electron-updater ships its configuration next to app.asar
+	configPath := filepath.Join(resourcesDir, "app-update.yml")
+	if content, err := s.readFile(configPath); err == nil {
+		s.logger.Debug("Found electron-updater config", "path", configPath)
+		updater.Mechanisms = append(updater.Mechanisms, "electron-updater")
+		updater.ConfigPath = configPath
+		s.parseAppUpdateConfig(updater, appPath, content)
+	}
+
+	switch s.goos {
+	case "darwin":
+		squirrelPath := filepath.Join(appPath, "Contents", "Frameworks", "Squirrel.framework")
+		if _, err := s.stat(squirrelPath); err == nil {
+			s.logger.Debug("Found Squirrel.Mac", "path", squirrelPath)
+			updater.Mechanisms = append(updater.Mechanisms, "squirrel-mac")
+
+			// ShipIt validates the code signature of the downloaded bundle
+			// against the running app before swapping it in
+			if updater.SignatureVerification == "" {
+				updater.SignatureVerification = "code-signature"
+			}
+
+			if bundleID := s.GetBundleIdentifier(appPath); bundleID != "" && updater.CachePath == "" {
+				updater.CachePath = s.userCachePath(appPath, bundleID+".ShipIt")
+			}
+		}
+	case "windows":
+		exePath := appPath
+		if !strings.HasSuffix(exePath, ".exe") {
+			exePath = filepath.Join(appPath, filepath.Base(appPath)+".exe")
+		}
+
+		// Squirrel.Windows installs into <root>\app-<version>\ with Update.exe in <root>
+		installRoot := filepath.Dir(filepath.Dir(exePath))
+		updateExe := filepath.Join(installRoot, "Update.exe")
+		if _, err := s.stat(updateExe); err == nil {
+			s.logger.Debug("Found Squirrel.Windows", "path", updateExe)
+			updater.Mechanisms = append(updater.Mechanisms, "squirrel-windows")
+			updater.CachePath = filepath.Join(installRoot, "packages")
+
+			// Packages are checked against SHA1 hashes from the same feed,
+			// not against a signature
+			updater.SignatureVerification = "none"
+		}
+	}
+
+	// Custom feeds are configured in main process code
+	if source, pkg := s.openAppSource(appPath); source != nil {
+		if mainName := source.resolveModule(pkg.Main); mainName != "" {
+			source.walkMainProcess(mainName, func(name string, content []byte, info ScriptInfo) {
+				if updater.FeedURL == "" {
+					if matches := feedURLRegex.FindSubmatch(content); len(matches) > 1 {
+						updater.FeedURL = string(matches[1])
+					} else if bytes.Contains(content, []byte(updateServiceURL)) {
+						updater.FeedURL = updateServiceURL
+					}
+				}
+			})
+		}
+
+		// electron-builder defaults the cache directory to "<name>-updater"
+		if updater.ConfigPath != "" && updater.CachePath == "" && pkg.Name != "" {
+			updater.CachePath = s.userCachePath(appPath, strings.ToLower(pkg.Name)+"-updater")
+		}
+	}
+
+	if len(updater.Mechanisms) == 0 {
+		if updater.FeedURL == "" {
+			return nil
+		}
+		updater.Mechanisms = append(updater.Mechanisms, "custom")
+	}
+
+	// Flag weaknesses in the update chain
+	if strings.HasPrefix(strings.ToLower(updater.FeedURL), "http://") {
+		updater.Issues = append(updater.Issues, "update feed uses plain HTTP")
+	}
+	if updater.SignatureVerification == "disabled" || updater.SignatureVerification == "none" {
+		updater.Issues = append(updater.Issues, "update signature verification is disabled")
+	}
+	if updater.CachePath != "" {
+		if _, err := s.stat(updater.CachePath); err == nil && s.canWrite(updater.CachePath) {
+			updater.CacheWritable = true
+			updater.Issues = append(updater.Issues, "update cache is user-writable")
+		}
+	}
+
+	for _, issue := range updater.Issues {
+		s.logger.Debug("Updater issue", "issue", issue)
+	}
+
+	return updater
+}
+
+// appUpdateConfig holds the app-update.yml keys electron-builder writes that
+// locate the feed, the expected publisher and the download cache
+type appUpdateConfig struct {
+	Provider            string     `yaml:"provider"`
+	URL                 string     `yaml:"url"`
+	Host                string     `yaml:"host"`
+	Owner               string     `yaml:"owner"`
+	Repo                string     `yaml:"repo"`
+	Bucket              string     `yaml:"bucket"`
+	Endpoint            string     `yaml:"endpoint"`
+	Path                string     `yaml:"path"`
+	Name                string     `yaml:"name"`
+	Region              string     `yaml:"region"`
+	PublisherName       stringList `yaml:"publisherName"`
+	UpdaterCacheDirName string     `yaml:"updaterCacheDirName"`
+}
+
+// stringList is a YAML value written either as a single string or as a list of strings
+type stringList []string
+
+// UnmarshalYAML accepts a scalar or a sequence of scalars
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			*l = nil
+			return nil
+		}
+		*l = stringList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// parseAppUpdateConfig reads the YAML written by electron-builder into app-update.yml
+func (s *Scanner) parseAppUpdateConfig(updater *UpdaterInfo, appPath string, content []byte) {
+	var config appUpdateConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		s.logger.Debug("Could not parse electron-updater config", "path", updater.ConfigPath, "error", err)
+		return
+	}
+
+	updater.Provider = config.Provider
+	switch updater.Provider {
+	case "github":
+		host := config.Host
+		if host == "" {
+			host = "github.com"
+		}
+		updater.FeedURL = fmt.Sprintf("https://%s/%s/%s/releases", host, config.Owner, config.Repo)
+	case "s3":
+		updater.FeedURL = config.Endpoint
+		if updater.FeedURL == "" {
+			updater.FeedURL = fmt.Sprintf("https://%s.s3.amazonaws.com", config.Bucket)
+		}
+		if config.Path != "" {
+			updater.FeedURL += "/" + strings.Trim(config.Path, "/")
+		}
+	case "spaces":
+		updater.FeedURL = fmt.Sprintf("https://%s.%s.digitaloceanspaces.com", config.Name, config.Region)
+	default:
+		updater.FeedURL = config.URL
+	}
+
+	for _, name := range config.PublisherName {
+		if name != "" {
+			updater.PublisherNames = append(updater.PublisherNames, name)
+		}
+	}
+
+	// On Windows electron-updater only verifies the Authenticode signature
+	// when electron-builder recorded the expected publisher
+	if s.goos == "windows" {
+		if len(updater.PublisherNames) > 0 {
+			updater.SignatureVerification = "authenticode"
+		} else {
+			updater.SignatureVerification = "disabled"
+		}
+	}
+
+	if config.UpdaterCacheDirName != "" {
+		updater.CachePath = s.userCachePath(appPath, config.UpdaterCacheDirName)
+	}
+}
+
+// userCachePath returns where a per-user update cache directory lives: the
+// current user's on the host; in an image, the profile the application is
+// installed under, or else the first profile that has the directory
+func (s *Scanner) userCachePath(appPath string, dirName string) string {
+	cachePath := func(home string) string {
+		switch s.goos {
+		case "darwin":
+			return filepath.Join(home, "Library", "Caches", dirName)
+		case "windows":
+			return filepath.Join(home, "AppData", "Local", dirName)
+		}
+		return filepath.Join(home, ".cache", dirName)
+	}
+
+	if s.isHost() {
+		if s.goos == "windows" {
+			if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+				return filepath.Join(localAppData, dirName)
+			}
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return cachePath(home)
+		}
+		return ""
+	}
+
+	var homes map[string]string
+	switch s.goos {
+	case "darwin":
+		homes = s.userHomes("/Users", "Shared")
+	case "windows":
+		root, _ := s.windowsSystemDrive()
+		homes = s.userHomes(filepath.Join(root, "Users"), "Public", "Default", "Default User", "All Users")
+	default:
+		homes = s.userHomes("/home")
+	}
+	users := sortedUsers(homes)
+	for _, user := range users {
+		if strings.HasPrefix(strings.ToLower(appPath), strings.ToLower(homes[user])+string(filepath.Separator)) {
+			return cachePath(homes[user])
+		}
+	}
+	for _, user := range users {
+		if s.exists(cachePath(homes[user])) {
+			return cachePath(homes[user])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,236 @@
+package electronscan_test
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestUpdaterCacheInImage(t *testing.T) {
+	sep := string(filepath.Separator)
+	tests := []struct {
+		name      string
+		goos      string
+		installIn string
+		cacheDir  string
+		want      string
+	}{
+		{"per-user install", "windows", "Users/bob/AppData/Local/Programs", "Users/bob/AppData/Local/demo-updater", filepath.Join(sep, "Users", "bob", "AppData", "Local", "demo-updater")},
+		{"machine-wide install", "windows", "Program Files", "Users/carol/AppData/Local/demo-updater", filepath.Join(sep, "Users", "carol", "AppData", "Local", "demo-updater")},
+		{"macOS", "darwin", "Applications", "Users/dave/Library/Caches/demo-updater", filepath.Join(sep, "Users", "dave", "Library", "Caches", "demo-updater")},
+		{"no cache anywhere", "darwin", "Applications", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: tt.goos}.Build(fsys, tt.installIn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources := path.Join(path.Dir(appPath), "resources")
+			if tt.goos == "darwin" {
+				resources = path.Join(appPath, "Contents", "Resources")
+			}
+			fsys[path.Join(resources, "app-update.yml")] = &fstest.MapFile{Data: []byte("provider: generic\nurl: https://example.com\nupdaterCacheDirName: demo-updater\n")}
+			fsys["Users/erin/Desktop"] = &fstest.MapFile{Mode: fs.ModeDir | 0o755}
+			if tt.cacheDir != "" {
+				fsys[tt.cacheDir] = &fstest.MapFile{Mode: fs.ModeDir | 0o777}
+			}
+
+			s := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: fsys})
+			updater := s.AnalyzeUpdater(sep + filepath.FromSlash(appPath))
+			if updater == nil {
+				t.Fatal("no updater detected")
+			}
+			if updater.CachePath != tt.want {
+				t.Errorf("cache path = %q, want %q", updater.CachePath, tt.want)
+			}
+			if updater.CacheWritable != (tt.want != "") {
+				t.Errorf("cache writable = %t", updater.CacheWritable)
+			}
+		})
+	}
+}
+
+func TestAppUpdateConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		feed       string
+		publishers []string
+		signature  string
+	}{
+		{
+			name:       "publisher list",
+			config:     "provider: generic\nurl: https://updates.example.com/demo\npublisherName:\n  - Example Corp\n  - \"Example, Inc.\"\n",
+			feed:       "https://updates.example.com/demo",
+			publishers: []string{"Example Corp", "Example, Inc."},
+			signature:  "authenticode",
+		},
+		{
+			name:       "flow sequence",
+			config:     "provider: generic\nurl: https://updates.example.com\npublisherName: [Example Corp, 'Example: EU']\n",
+			feed:       "https://updates.example.com",
+			publishers: []string{"Example Corp", "Example: EU"},
+			signature:  "authenticode",
+		},
+		{
+			name:       "single publisher with a comment",
+			config:     "provider: generic\nurl: 'http://updates.example.com/#/feed' # staging\npublisherName: \"Example #1: Corp\"\n",
+			feed:       "http://updates.example.com/#/feed",
+			publishers: []string{"Example #1: Corp"},
+			signature:  "authenticode",
+		},
+		{
+			name:      "nested maps are skipped",
+			config:    "provider: github\nowner: example\nrepo: demo\nrequestHeaders:\n  url: http://attacker.example\n  publisherName: Attacker\nupdaterCacheDirName: demo-updater\n",
+			feed:      "https://github.com/example/demo/releases",
+			signature: "disabled",
+		},
+		{
+			name:      "empty publisher",
+			config:    "provider: s3\nbucket: demo-releases\npath: /stable/\npublisherName:\n",
+			feed:      "https://demo-releases.s3.amazonaws.com/stable",
+			signature: "disabled",
+		},
+		{
+			name:   "malformed",
+			config: "provider: generic\nurl: [https://updates.example.com\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: "windows"}.Build(fsys, "Program Files")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fsys[path.Join(path.Dir(appPath), "resources", "app-update.yml")] = &fstest.MapFile{Data: []byte(tt.config)}
+
+			s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+			updater := s.AnalyzeUpdater(string(filepath.Separator) + filepath.FromSlash(appPath))
+			if updater == nil {
+				t.Fatal("no updater detected")
+			}
+			if updater.FeedURL != tt.feed {
+				t.Errorf("feed = %q, want %q", updater.FeedURL, tt.feed)
+			}
+			if !slices.Equal(updater.PublisherNames, tt.publishers) {
+				t.Errorf("publishers = %q, want %q", updater.PublisherNames, tt.publishers)
+			}
+			if updater.SignatureVerification != tt.signature {
+				t.Errorf("signature verification = %q, want %q", updater.SignatureVerification, tt.signature)
+			}
+		})
+	}
+}
+
+func TestUpdaterMechanisms(t *testing.T) {
+	sep := string(filepath.Separator)
+	feedMain := func(feed string) map[string]string {
+		return map[string]string{
+			"package.json": `{"name": "Demo", "main": "main.js"}`,
+			"main.js":      "require('./updates')\n",
+			"updates.js":   feed,
+		}
+	}
+	tests := []struct {
+		name       string
+		goos       string
+		dir        string
+		sources    map[string]string
+		layout     func(fsys fstest.MapFS, appPath string)
+		mechanisms []string
+		feed       string
+		signature  string
+		issues     []string
+	}{
+		{
+			name:       "setFeedURL over HTTP",
+			goos:       "windows",
+			dir:        "Program Files",
+			sources:    feedMain("autoUpdater.setFeedURL({ url: 'http://updates.example.com/win' })\n"),
+			mechanisms: []string{"custom"},
+			feed:       "http://updates.example.com/win",
+			issues:     []string{"update feed uses plain HTTP"},
+		},
+		{
+			name:       "update.electronjs.org",
+			goos:       "darwin",
+			dir:        "Applications",
+			sources:    feedMain("const feed = `https://update.electronjs.org/example/demo/${process.platform}`\n"),
+			mechanisms: []string{"custom"},
+			feed:       "https://update.electronjs.org",
+		},
+		{
+			name:    "Squirrel.Windows",
+			goos:    "windows",
+			dir:     "Users/bob/AppData/Local/demo",
+			sources: feedMain(""),
+			layout: func(fsys fstest.MapFS, appPath string) {
+				fsys["Users/bob/AppData/Local/demo/Update.exe"] = &fstest.MapFile{Data: []byte("MZ")}
+				fsys["Users/bob/AppData/Local/demo/packages"] = &fstest.MapFile{Mode: fs.ModeDir | 0o777}
+			},
+			mechanisms: []string{"squirrel-windows"},
+			signature:  "none",
+			issues:     []string{"update signature verification is disabled", "update cache is user-writable"},
+		},
+		{
+			name:    "Squirrel.Mac with electron-updater",
+			goos:    "darwin",
+			dir:     "Applications",
+			sources: feedMain(""),
+			layout: func(fsys fstest.MapFS, appPath string) {
+				fsys[path.Join(appPath, "Contents/Frameworks/Squirrel.framework/Squirrel")] = &fstest.MapFile{Data: []byte{0xcf, 0xfa, 0xed, 0xfe}}
+				fsys[path.Join(appPath, "Contents/Resources/app-update.yml")] = &fstest.MapFile{Data: []byte("provider: github\nowner: example\nrepo: demo\n")}
+			},
+			mechanisms: []string{"electron-updater", "squirrel-mac"},
+			feed:       "https://github.com/example/demo/releases",
+			signature:  "code-signature",
+		},
+		{
+			name:    "no updater",
+			goos:    "windows",
+			dir:     "Program Files",
+			sources: feedMain("console.log('no updates')\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: tt.goos, Sources: tt.sources}.Build(fsys, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.layout != nil {
+				tt.layout(fsys, appPath)
+			}
+
+			updater := electronscan.New(electronscan.Options{GOOS: tt.goos, FS: fsys}).AnalyzeUpdater(sep + filepath.FromSlash(appPath))
+			if tt.mechanisms == nil {
+				if updater != nil {
+					t.Errorf("updater = %+v, want none", updater)
+				}
+				return
+			}
+			if updater == nil {
+				t.Fatal("no updater detected")
+			}
+			if !slices.Equal(updater.Mechanisms, tt.mechanisms) {
+				t.Errorf("mechanisms = %q, want %q", updater.Mechanisms, tt.mechanisms)
+			}
+			if updater.FeedURL != tt.feed || updater.SignatureVerification != tt.signature {
+				t.Errorf("feed = %q, signature verification = %q, want %q, %q", updater.FeedURL, updater.SignatureVerification, tt.feed, tt.signature)
+			}
+			if !slices.Equal(updater.Issues, tt.issues) {
+				t.Errorf("issues = %q, want %q", updater.Issues, tt.issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package electronscan
+
+import (
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of file tracked by the Watcher
+const (
+	WatchKindExecutable = "executable"
+	WatchKindAsar       = "asar"
+	WatchKindNode       = "node"
+	WatchKindMain       = "main"
+	WatchKindPreload    = "preload"
+)
+
+// Watch event types
+const (
+	WatchAlert  = "alert"
+	WatchUpdate = "update"
+)
+
+// Changes reported for a watched file
+const (
+	FileAdded    = "added"
+	FileRemoved  = "removed"
+	FileModified = "modified"
+)
+
+// WatchEvent is a change to an application's code seen between two polls.
+// Updates are version changes that came with a replaced, validly signed
+// executable, after which the application is re-baselined. Every other change
+// is an alert, even when the version moved.
+type WatchEvent struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	App        string    `json:"app"`
+	Kind       string    `json:"kind,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Change     string    `json:"change,omitempty"`
+	OldSHA256  string    `json:"old_sha256,omitempty"`
+	NewSHA256  string    `json:"new_sha256,omitempty"`
+	OldVersion string    `json:"old_version,omitempty"`
+	NewVersion string    `json:"new_version,omitempty"`
+}
+
+// watchedFile is the last seen state of a file
+type watchedFile struct {
+	kind    string
+	size    int64
+	modTime time.Time
+	sha256  string
+}
+
+// watchedApp is the last seen state of an application
+type watchedApp struct {
+	version string
+	files   map[string]watchedFile
+}
+
+// Watcher polls the code-bearing files of applications for changes
+type Watcher struct {
+	scanner *Scanner
+	logger  *slog.Logger
+	apps    map[string]*watchedApp
+}
+
+// NewWatcher creates a Watcher with no applications tracked yet
+func (s *Scanner) NewWatcher() *Watcher {
+	return &Watcher{
+		scanner: s,
+		logger:  s.logger,
+		apps:    make(map[string]*watchedApp),
+	}
+}
+
+// Poll checks the given applications and returns what changed since the last poll.
+// The first poll of an application records its state without reporting anything.
+func (w *Watcher) Poll(appPaths []string) []WatchEvent {
+	var events []WatchEvent
+	now := time.Now().UTC()
+
+	for _, appPath := range appPaths {
+		version := w.scanner.appVersion(appPath)
+		files := w.collectFiles(appPath)
+
+		previous, known := w.apps[appPath]
+		if !known {
+			w.logger.Debug("Watching application", "app", appPath, "version", version, "files", len(files))
+			w.apps[appPath] = &watchedApp{version: version, files: w.hashFiles(files, nil)}
+			continue
+		}
+
+		current := w.hashFiles(files, previous.files)
+		changes := diffWatchedFiles(previous.files, current)
+
+		// package.json is part of the code an attacker rewrites, so a new
+		// version alone does not make an update
+		if version != previous.version && w.hasSignedExecutable(appPath, previous, current) {
+			w.logger.Info("Application updated", "app", appPath, "old_version", previous.version, "new_version", version, "changed_files", len(changes))
+			events = append(events, WatchEvent{
+				Type:       WatchUpdate,
+				Time:       now,
+				App:        appPath,
+				OldVersion: previous.version,
+				NewVersion: version,
+			})
+		} else {
+			if version != previous.version {
+				w.logger.Warn("Application version changed without a signed new executable", "app", appPath, "old_version", previous.version, "new_version", version)
+			}
+			for _, change := range changes {
+				change.Type = WatchAlert
+				change.Time = now
+				change.App = appPath
+				if version != previous.version {
+					change.OldVersion = previous.version
+					change.NewVersion = version
+				}
+				w.logger.Warn("Application code changed", "app", appPath, "kind", change.Kind, "path", change.Path, "change", change.Change)
+				events = append(events, change)
+			}
+		}
+
+		w.apps[appPath] = &watchedApp{version: version, files: current}
+	}
+
+	return events
+}
+
+// hasSignedExecutable reports whether a version change was made by the
+// vendor's updater: the executable was replaced by one with a valid code
+// signature. The updater's staging directory is user-writable, so activity
+// there proves nothing.
+func (w *Watcher) hasSignedExecutable(appPath string, previous *watchedApp, current map[string]watchedFile) bool {
+	for path, file := range current {
+		if file.kind != WatchKindExecutable || previous.files[path].sha256 == file.sha256 {
+			continue
+		}
+		if w.scanner.verifyAppSignature(appPath) {
+			return true
+		}
+		w.logger.Warn("Replaced executable has no valid code signature", "app", appPath, "path", path)
+	}
+	return false
+}
+
+// collectFiles lists the files of an application that an attacker would modify
+// to run code inside it, keyed by path
+func (w *Watcher) collectFiles(appPath string) map[string]string {
+	files := make(map[string]string)
+
+	if executable := w.scanner.GetExecutablePath(appPath); executable != "" {
+		files[executable] = WatchKindExecutable
+	}
+	if asarPath := w.scanner.GetAsarPath(appPath); asarPath != "" {
+		files[asarPath] = WatchKindAsar
+	}
+
+	// Scripts inside app.asar are covered by the archive itself; a new
+	// resources/app folder shows up as a main script outside of it
+	if entry := w.scanner.ResolveEntryPoint(appPath); entry != nil {
+		if entry.Main.Location != "asar" && !entry.Main.Missing {
+			files[entry.Main.Path] = WatchKindMain
+		}
+		for _, script := range entry.Preloads {
+			if script.Location != "asar" && !script.Missing {
+				files[script.Path] = WatchKindPreload
+			}
+		}
+	}
+
+	searchRoots, _ := w.scanner.nodeSearchRoots(appPath)
+	for _, root := range searchRoots {
+		w.scanner.walk(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(strings.ToLower(d.Name()), ".node") {
+				files[path] = WatchKindNode
+			}
+			return nil
+		})
+	}
+
+	return files
+}
+
+// hashFiles stats and hashes each file. Only executables, which are large and
+// checked against their code signature on update, reuse the previous hash while
+// their size and modification time stay the same; both are easy to restore
+// after editing app.asar or a .node file.
+func (w *Watcher) hashFiles(files map[string]string, previous map[string]watchedFile) map[string]watchedFile {
+	state := make(map[string]watchedFile, len(files))
+	for path, kind := range files {
+		info, err := w.scanner.stat(path)
+		if err != nil {
+			continue
+		}
+
+		file := watchedFile{kind: kind, size: info.Size(), modTime: info.ModTime()}
+		if old, ok := previous[path]; ok && kind == WatchKindExecutable && old.size == file.size && old.modTime.Equal(file.modTime) {
+			file.sha256 = old.sha256
+		} else {
+			file.sha256 = w.scanner.hashFile(path)
+		}
+		state[path] = file
+	}
+	return state
+}
+
+// diffWatchedFiles reports files added, removed or modified between two states
+func diffWatchedFiles(previous map[string]watchedFile, current map[string]watchedFile) []WatchEvent {
+	var events []WatchEvent
+	for _, path := range sortedKeys(previous, current) {
+		old, hadOld := previous[path]
+		now, hasNow := current[path]
+		switch {
+		case !hadOld:
+			events = append(events, WatchEvent{Kind: now.kind, Path: path, Change: FileAdded, NewSHA256: now.sha256})
+		case !hasNow:
+			events = append(events, WatchEvent{Kind: old.kind, Path: path, Change: FileRemoved, OldSHA256: old.sha256})
+		case old.sha256 != now.sha256:
+			events = append(events, WatchEvent{Kind: now.kind, Path: path, Change: FileModified, OldSHA256: old.sha256, NewSHA256: now.sha256})
+		}
+	}
+	return events
+}
+
+// appVersion returns the version of the application code from its package.json
+func (s *Scanner) appVersion(appPath string) string {
+	if source, pkg := s.openAppSource(appPath); source != nil {
+		return pkg.Version
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package electronscan_test
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/adversis/electron-integrity/electronscan"
+	"github.com/adversis/electron-integrity/electronscan/fixture"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	installDir := "Users/bob/AppData/Local/Programs"
+	stagingDir := "Users/bob/AppData/Local/demo-updater"
+
+	// bumpVersion swaps in the archive of a newer build
+	bumpVersion := func(t *testing.T, fsys fstest.MapFS, resources string) {
+		newer := fstest.MapFS{}
+		newPath, err := fixture.App{GOOS: "windows", Version: "2.0.0"}.Build(newer, installDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		*fsys[path.Join(resources, "app.asar")] = *newer[path.Join(path.Dir(newPath), "resources", "app.asar")]
+	}
+	// stage touches the updater's user-writable staging directory
+	stage := func(fsys fstest.MapFS) {
+		fsys[path.Join(stagingDir, "pending")] = &fstest.MapFile{Mode: fs.ModeDir | 0o755, ModTime: time.Now()}
+		fsys[path.Join(stagingDir, "pending", "update.exe")] = &fstest.MapFile{ModTime: time.Now()}
+	}
+
+	tests := []struct {
+		name        string
+		change      func(t *testing.T, fsys fstest.MapFS, appPath, resources string)
+		wantVersion string
+		want        []string
+	}{
+		{
+			name:   "nothing changed",
+			change: func(*testing.T, fstest.MapFS, string, string) {},
+		},
+		{
+			name: "asar edited keeping size and modification time",
+			change: func(_ *testing.T, fsys fstest.MapFS, _, resources string) {
+				asar := fsys[path.Join(resources, "app.asar")]
+				asar.Data = append([]byte(nil), asar.Data...)
+				asar.Data[len(asar.Data)-1] ^= 0xff
+			},
+			want: []string{"asar modified"},
+		},
+		{
+			name: "tampered asar with a version bump and a staging touch",
+			change: func(t *testing.T, fsys fstest.MapFS, _, resources string) {
+				bumpVersion(t, fsys, resources)
+				stage(fsys)
+			},
+			wantVersion: "2.0.0",
+			want:        []string{"asar modified"},
+		},
+		{
+			name: "executable replaced without a valid signature",
+			change: func(t *testing.T, fsys fstest.MapFS, appPath, resources string) {
+				bumpVersion(t, fsys, resources)
+				exe := fsys[appPath]
+				exe.Data = append(append([]byte(nil), exe.Data...), "payload"...)
+			},
+			wantVersion: "2.0.0",
+			want:        []string{"asar modified", "executable modified"},
+		},
+		{
+			name: "native module planted",
+			change: func(_ *testing.T, fsys fstest.MapFS, _, resources string) {
+				fsys[path.Join(resources, "app.asar.unpacked", "node_modules", "evil", "evil.node")] = &fstest.MapFile{Data: []byte("MZ")}
+			},
+			want: []string{"node added"},
+		},
+		{
+			name: "native module removed",
+			change: func(_ *testing.T, fsys fstest.MapFS, _, resources string) {
+				delete(fsys, path.Join(resources, "app.asar.unpacked", "node_modules", "keytar", "build", "Release", "keytar.node"))
+			},
+			want: []string{"node removed"},
+		},
+		{
+			name: "resources/app folder planted",
+			change: func(_ *testing.T, fsys fstest.MapFS, _, resources string) {
+				fsys[path.Join(resources, "app", "package.json")] = &fstest.MapFile{Data: []byte(`{"main": "index.js"}`)}
+				fsys[path.Join(resources, "app", "index.js")] = &fstest.MapFile{Data: []byte("require('child_process')")}
+			},
+			// The planted package.json has no version
+			wantVersion: "",
+			want:        []string{"main added"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			appPath, err := fixture.App{GOOS: "windows", NativeModules: []string{"keytar"}}.Build(fsys, installDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources := path.Join(path.Dir(appPath), "resources")
+			fsys[path.Join(resources, "app-update.yml")] = &fstest.MapFile{Data: []byte("provider: generic\nurl: https://example.com\nupdaterCacheDirName: demo-updater\n")}
+			fsys[stagingDir] = &fstest.MapFile{Mode: fs.ModeDir | 0o755}
+
+			s := electronscan.New(electronscan.Options{GOOS: "windows", FS: fsys})
+			apps := []string{string(filepath.Separator) + filepath.FromSlash(appPath)}
+			watcher := s.NewWatcher()
+			if events := watcher.Poll(apps); len(events) != 0 {
+				t.Fatalf("events on first poll = %+v", events)
+			}
+
+			tt.change(t, fsys, appPath, resources)
+
+			var got []string
+			for _, event := range watcher.Poll(apps) {
+				if event.Type != electronscan.WatchAlert {
+					t.Errorf("event type = %q for %s, want %q", event.Type, event.Path, electronscan.WatchAlert)
+				}
+				if event.NewVersion != tt.wantVersion {
+					t.Errorf("new version = %q, want %q", event.NewVersion, tt.wantVersion)
+				}
+				got = append(got, event.Kind+" "+event.Change)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("alerts = %v, want %v", got, tt.want)
+			}
+			if events := watcher.Poll(apps); len(events) != 0 {
+				t.Errorf("events on an unchanged poll = %+v", events)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package electronscan
+
+import "syscall"
+
+// canWrite reports whether the current user has write permission on path
+func canWrite(path string) bool {
+	// W_OK
+	return syscall.Access(path, 0x2) == nil
+}
@@ -0,0 +1,86 @@
+//go:build windows
+
+package electronscan
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Arguments of the security functions used to evaluate file ACLs
+const (
+	seFileObject = 1
+
+	ownerSecurityInformation = 0x1
+	groupSecurityInformation = 0x2
+	daclSecurityInformation  = 0x4
+
+	securityImpersonation = 2
+
+	// FILE_WRITE_DATA on a file, FILE_ADD_FILE on a directory
+	fileWriteData = 0x2
+
+	fileGenericRead    = 0x120089
+	fileGenericWrite   = 0x120116
+	fileGenericExecute = 0x1200A0
+	fileAllAccess      = 0x1F01FF
+)
+
+// genericMapping is GENERIC_MAPPING
+type genericMapping struct {
+	genericRead    uint32
+	genericWrite   uint32
+	genericExecute uint32
+	genericAll     uint32
+}
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procGetNamedSecurityInfoW = advapi32.NewProc("GetNamedSecurityInfoW")
+	procDuplicateToken        = advapi32.NewProc("DuplicateToken")
+	procAccessCheck           = advapi32.NewProc("AccessCheck")
+)
+
+// canWrite reports whether the current user has write permission on path.
+// Windows ACLs are not reflected in file modes, so the file's security
+// descriptor is checked against the process token. Nothing is opened for
+// writing, which would also fail on running executables and loaded DLLs.
+func canWrite(path string) bool {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	var descriptor uintptr
+	r, _, _ := procGetNamedSecurityInfoW.Call(uintptr(unsafe.Pointer(pathPtr)), seFileObject,
+		ownerSecurityInformation|groupSecurityInformation|daclSecurityInformation,
+		0, 0, 0, 0, uintptr(unsafe.Pointer(&descriptor)))
+	if r != 0 {
+		return false
+	}
+	defer syscall.LocalFree(syscall.Handle(descriptor))
+
+	// AccessCheck needs an impersonation token
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return false
+	}
+	var token syscall.Token
+	if err := syscall.OpenProcessToken(process, syscall.TOKEN_QUERY|syscall.TOKEN_DUPLICATE, &token); err != nil {
+		return false
+	}
+	defer token.Close()
+	var impersonation syscall.Token
+	if r, _, _ := procDuplicateToken.Call(uintptr(token), securityImpersonation, uintptr(unsafe.Pointer(&impersonation))); r == 0 {
+		return false
+	}
+	defer impersonation.Close()
+
+	mapping := genericMapping{fileGenericRead, fileGenericWrite, fileGenericExecute, fileAllAccess}
+	privileges := make([]byte, 256)
+	privilegesLen := uint32(len(privileges))
+	var granted, status uint32
+	r, _, _ = procAccessCheck.Call(descriptor, uintptr(impersonation), fileWriteData,
+		uintptr(unsafe.Pointer(&mapping)), uintptr(unsafe.Pointer(&privileges[0])), uintptr(unsafe.Pointer(&privilegesLen)),
+		uintptr(unsafe.Pointer(&granted)), uintptr(unsafe.Pointer(&status)))
+	return r != 0 && status != 0
+}